@@ -14,6 +14,7 @@ import (
 type ControllerContext struct {
 	ClientBuilder *ClientBuilder
 
+	KubeInformerFactory           informers.SharedInformerFactory
 	KubeNamespacedInformerFactory informers.SharedInformerFactory
 	ConfigInformerFactory         configinformersv1.SharedInformerFactory
 	MachineInformerFactory        machineinformersv1beta1.SharedInformerFactory
@@ -33,12 +34,14 @@ func CreateControllerContext(cb *ClientBuilder, stop <-chan struct{}, targetName
 	configClient := cb.OpenshiftClientOrDie("config-shared-informer")
 	machineClient := cb.MachineClientOrDie("machine-shared-informer")
 
+	kubeSharedInformer := informers.NewSharedInformerFactoryWithOptions(kubeClient, resyncPeriod()())
 	kubeNamespacedSharedInformer := informers.NewSharedInformerFactoryWithOptions(kubeClient, resyncPeriod()(), informers.WithNamespace(targetNamespace))
 	configSharedInformer := configinformersv1.NewSharedInformerFactoryWithOptions(configClient, resyncPeriod()())
 	machineSharedInformer := machineinformersv1beta1.NewSharedInformerFactoryWithOptions(machineClient, resyncPeriod()(), machineinformersv1beta1.WithNamespace(targetNamespace))
 
 	return &ControllerContext{
 		ClientBuilder:                 cb,
+		KubeInformerFactory:           kubeSharedInformer,
 		KubeNamespacedInformerFactory: kubeNamespacedSharedInformer,
 		ConfigInformerFactory:         configSharedInformer,
 		MachineInformerFactory:        machineSharedInformer,
@@ -17,6 +17,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	coreclientsetv1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -42,6 +43,10 @@ var (
 	startOpts struct {
 		kubeconfig string
 		imagesFile string
+		// machineMetricsSelector limits the machines metrics are collected for
+		machineMetricsSelector string
+		// machineMetricsExcludedPhases lists the machine phases left out of the per machine metrics
+		machineMetricsExcludedPhases []string
 	}
 )
 
@@ -49,6 +54,8 @@ func init() {
 	rootCmd.AddCommand(startCmd)
 	startCmd.PersistentFlags().StringVar(&startOpts.kubeconfig, "kubeconfig", "", "Kubeconfig file to access a remote cluster (testing only)")
 	startCmd.PersistentFlags().StringVar(&startOpts.imagesFile, "images-json", "", "images.json file for MAO.")
+	startCmd.PersistentFlags().StringVar(&startOpts.machineMetricsSelector, "machine-metrics-selector", "", "Label selector limiting the machines metrics are collected for. If unspecified, metrics are collected for all machines.")
+	startCmd.PersistentFlags().StringSliceVar(&startOpts.machineMetricsExcludedPhases, "machine-metrics-excluded-phases", nil, "Comma separated list of machine phases, e.g. \"Deleting\", whose machines are only counted rather than reported individually by the machine metrics. If unspecified, all machines are reported.")
 
 	klog.InitFlags(nil)
 	flag.Parse()
@@ -130,6 +137,8 @@ func startControllers(ctx *ControllerContext) {
 		ctx.KubeNamespacedInformerFactory.Admissionregistration().V1().ValidatingWebhookConfigurations(),
 		ctx.KubeNamespacedInformerFactory.Admissionregistration().V1().MutatingWebhookConfigurations(),
 		ctx.ConfigInformerFactory.Config().V1().Proxies(),
+		ctx.KubeNamespacedInformerFactory.Core().V1().ConfigMaps(),
+		ctx.MachineInformerFactory.Machine().V1beta1().MachineHealthChecks(),
 		ctx.ClientBuilder.KubeClientOrDie(componentName),
 		ctx.ClientBuilder.OpenshiftClientOrDie(componentName),
 		ctx.ClientBuilder.DynamicClientOrDie(componentName),
@@ -140,11 +149,20 @@ func startControllers(ctx *ControllerContext) {
 func startMetricsCollectionAndServer(ctx *ControllerContext) {
 	machineInformer := ctx.MachineInformerFactory.Machine().V1beta1().Machines()
 	machinesetInformer := ctx.MachineInformerFactory.Machine().V1beta1().MachineSets()
+	nodeInformer := ctx.KubeInformerFactory.Core().V1().Nodes()
+	machineSelector, err := labels.Parse(startOpts.machineMetricsSelector)
+	if err != nil {
+		klog.Fatalf("Error parsing --machine-metrics-selector (%q): %v", startOpts.machineMetricsSelector, err)
+	}
 	machineMetricsCollector := metrics.NewMachineCollector(
 		machineInformer,
 		machinesetInformer,
-		componentNamespace)
+		nodeInformer,
+		[]string{componentNamespace},
+		machineSelector,
+		startOpts.machineMetricsExcludedPhases)
 	prometheus.MustRegister(machineMetricsCollector)
+	ctx.KubeInformerFactory.Start(ctx.Stop)
 	metricsPort := defaultMetricsPort
 	if port, ok := os.LookupEnv("METRICS_PORT"); ok {
 		v, err := strconv.Atoi(port)
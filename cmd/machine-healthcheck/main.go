@@ -3,11 +3,13 @@ package main
 import (
 	"flag"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/openshift/machine-api-operator/pkg/controller/machinehealthcheck"
 	"github.com/openshift/machine-api-operator/pkg/metrics"
 
+	configv1 "github.com/openshift/api/config/v1"
 	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 	"github.com/openshift/machine-api-operator/pkg/controller"
 	sdkVersion "github.com/operator-framework/operator-sdk/version"
@@ -69,6 +71,168 @@ func main() {
 		"The duration that non-leader candidates will wait after observing a leadership renewal until attempting to acquire leadership of a led but unrenewed leader slot. This is effectively the maximum duration that a leader can be stopped before it is replaced by another candidate. This is only applicable if leader election is enabled.",
 	)
 
+	auditWebhookURL := flag.String(
+		"audit-webhook-url",
+		"",
+		"URL a JSON record of every remediation is posted to. If unspecified, remediations are not audited.",
+	)
+
+	nodeGracePeriod := flag.Duration(
+		"node-grace-period",
+		0,
+		"Duration after the creation of a node during which its conditions are not evaluated. If unspecified, node conditions are evaluated immediately.",
+	)
+
+	nodeNotFoundGracePeriod := flag.Duration(
+		"node-not-found-grace-period",
+		0,
+		"Duration a node referenced by a machine may be missing before the machine is remediated. If unspecified, machines whose node is missing are remediated immediately.",
+	)
+
+	failedMachineTimeout := flag.Duration(
+		"failed-machine-timeout",
+		0,
+		"Duration a machine must have been in the Failed phase before it is remediated. If unspecified, failed machines are remediated immediately.",
+	)
+
+	nodeLeaseStaleTimeout := flag.Duration(
+		"node-lease-stale-timeout",
+		0,
+		"Duration after which a node whose lease has not been renewed is unhealthy, regardless of its conditions. If unspecified, node leases are not evaluated.",
+	)
+
+	cordonedNotReadyTimeout := flag.Duration(
+		"cordoned-not-ready-timeout",
+		0,
+		"Duration after which a node which is both cordoned and not ready is unhealthy, regardless of the MachineHealthCheck condition timeouts. If unspecified, such nodes are only evaluated against the condition timeouts.",
+	)
+
+	protectedRoles := flag.String(
+		"protected-roles",
+		"",
+		"Comma separated list of machine roles which are never remediated, e.g. \"infra\". If unspecified, machines of all roles are remediated.",
+	)
+
+	remediableOwnerKinds := flag.String(
+		"remediable-owner-kinds",
+		"",
+		"Comma separated list of controller kinds machines must be owned by to be remediated, e.g. \"MachineSet\". If unspecified, machines owned by any controller are remediated.",
+	)
+
+	masterLabels := flag.String(
+		"master-labels",
+		"",
+		"Semicolon separated list of label selectors identifying master nodes and machines, e.g. \"node-role.kubernetes.io/master\". A machine is a master if any of them matches its node or the machine itself. If unspecified, the legacy and current node role labels and the machine master role label are used.",
+	)
+
+	deletePropagationPolicy := flag.String(
+		"delete-propagation-policy",
+		"",
+		"Propagation policy used when deleting unhealthy machines, one of \"Foreground\", \"Background\" or \"Orphan\". If unspecified, the API server default is used.",
+	)
+
+	soleMachineSetMemberPolicy := flag.String(
+		"sole-machineset-member-policy",
+		"",
+		"Policy applied when remediation would delete the only machine of a single replica MachineSet, one of \"Delete\", \"ScaleFirst\" or \"Refuse\". \"ScaleFirst\" scales the MachineSet up by one and deletes the machine once its replacement is ready. If unspecified, the machine is deleted.",
+	)
+
+	flappingThreshold := flag.Int(
+		"flapping-threshold",
+		0,
+		"Number of remediations of a machine within the flapping window after which further remediations of the machine are suppressed. If unspecified, flapping detection is disabled.",
+	)
+
+	machineSetFlappingThreshold := flag.Int(
+		"machineset-flapping-threshold",
+		0,
+		"Number of machines of a MachineSet deleted by remediation within the flapping window after which further deletions of the machines of the MachineSet are suppressed. Deleted machines are replaced by machines of new names, so their repeated failures are only counted by this threshold. If unspecified, MachineSet flapping detection is disabled.",
+	)
+
+	flappingWindow := flag.Duration(
+		"flapping-window",
+		time.Hour,
+		"Sliding window remediations are counted over for flapping detection.",
+	)
+
+	escalationThreshold := flag.Int(
+		"escalation-threshold",
+		0,
+		"Number of remediations of a machine within the escalation window after which further remediations of the machine are escalated with a Warning event. If unspecified, remediations are never escalated.",
+	)
+
+	escalationWindow := flag.Duration(
+		"escalation-window",
+		time.Hour,
+		"Sliding window remediations are counted over for escalation.",
+	)
+
+	machineSetMaxUnhealthy := flag.String(
+		"machineset-max-unhealthy",
+		"",
+		"Number or percentage of the machines of a single MachineSet which may need remediation before remediation of its machines is restricted, e.g. \"1\" or \"40%\". If unspecified, remediation budgets are only enforced per MachineHealthCheck.",
+	)
+
+	clusterMaxNotReady := flag.String(
+		"cluster-max-not-ready",
+		"",
+		"Number or percentage of all nodes of the cluster which may be not ready before all remediation is suspended, e.g. \"50%\". If unspecified, remediation is never suspended cluster wide.",
+	)
+
+	maxRemediationsPerZone := flag.Int(
+		"max-remediations-per-zone",
+		0,
+		"Number of machines of a single availability zone remediated per reconcile. Remaining machines of the zone are remediated on requeue. If unspecified, remediations are not limited per zone.",
+	)
+
+	stuckFinalizers := flag.String(
+		"stuck-finalizers",
+		"",
+		"Comma separated list of finalizers which may be removed from machines stuck deleting. Removal must also be enabled per MachineHealthCheck with the machine.openshift.io/remove-stuck-finalizers-after annotation. If unspecified, no finalizer is removed.",
+	)
+
+	statusUpdateInterval := flag.Duration(
+		"status-update-interval",
+		0,
+		"Minimum duration between status writes of a MachineHealthCheck, status changes within the interval are written once at its end. If unspecified, every status change is written immediately.",
+	)
+
+	masterRemediationCooldown := flag.Duration(
+		"master-remediation-cooldown",
+		0,
+		"Duration after the remediation of a master during which no other master is remediated. At most one master is remediated per reconcile regardless.",
+	)
+
+	remediationIdempotencyWindow := flag.Duration(
+		"remediation-idempotency-window",
+		0,
+		"Duration after the remediation of a machine during which it is not remediated again, even by a restarted controller. If unspecified, remediations are not recorded on machines.",
+	)
+
+	maxUnhealthyDeferral := flag.Duration(
+		"max-unhealthy-deferral",
+		0,
+		"Cumulative duration a machine with an unhealthy node condition may be deferred within the condition timeouts before it is remediated regardless. If unspecified, deferral is not limited.",
+	)
+
+	maxConcurrentRemediations := flag.Int(
+		"max-concurrent-remediations",
+		1,
+		"Number of machines of a single MachineHealthCheck remediated concurrently per reconcile, within its remediation budgets. If unspecified, machines are remediated one at a time.",
+	)
+
+	minConditionTimeout := flag.Duration(
+		"min-condition-timeout",
+		40*time.Second,
+		"Shortest unhealthy condition timeout of a MachineHealthCheck not warned about. Shorter timeouts may expire between two regular node status updates, so that healthy nodes are remediated. If zero, condition timeouts are not checked.",
+	)
+
+	createDefaultWorkerMHC := flag.Bool(
+		"create-default-worker-mhc",
+		false,
+		"Ensure a default MachineHealthCheck of worker machines exists. It is created when absent and left alone once modified.",
+	)
+
 	klog.InitFlags(nil)
 	flag.Parse()
 	printVersion()
@@ -110,9 +274,47 @@ func main() {
 	if err := mapiv1.AddToScheme(mgr.GetScheme()); err != nil {
 		klog.Fatal(err)
 	}
+	if err := configv1.AddToScheme(mgr.GetScheme()); err != nil {
+		klog.Fatal(err)
+	}
 
 	// Setup all Controllers
-	if err := controller.AddToManager(mgr, opts, machinehealthcheck.Add); err != nil {
+	mhcOpts := machinehealthcheck.Options{
+		AuditWebhookURL: *auditWebhookURL,
+		NodeGracePeriod: *nodeGracePeriod,
+		ProtectedRoles:  splitList(*protectedRoles),
+
+		CordonedNotReadyTimeout: *cordonedNotReadyTimeout,
+		NodeNotFoundGracePeriod: *nodeNotFoundGracePeriod,
+		NodeLeaseStaleTimeout:   *nodeLeaseStaleTimeout,
+		FailedMachineTimeout:    *failedMachineTimeout,
+
+		DeletePropagationPolicy:     *deletePropagationPolicy,
+		FlappingThreshold:           *flappingThreshold,
+		MachineSetFlappingThreshold: *machineSetFlappingThreshold,
+		FlappingWindow:              *flappingWindow,
+		EscalationThreshold:         *escalationThreshold,
+		EscalationWindow:            *escalationWindow,
+		MachineSetMaxUnhealthy:      *machineSetMaxUnhealthy,
+		ClusterMaxNotReady:          *clusterMaxNotReady,
+		MaxRemediationsPerZone:      *maxRemediationsPerZone,
+		StuckFinalizers:             splitList(*stuckFinalizers),
+
+		MasterRemediationCooldown:    *masterRemediationCooldown,
+		StatusUpdateInterval:         *statusUpdateInterval,
+		RemediationIdempotencyWindow: *remediationIdempotencyWindow,
+		MaxUnhealthyDeferral:         *maxUnhealthyDeferral,
+		MinConditionTimeout:          *minConditionTimeout,
+		MaxConcurrentRemediations:    *maxConcurrentRemediations,
+		CreateDefaultWorkerMHC:       *createDefaultWorkerMHC,
+		SoleMachineSetMemberPolicy:   *soleMachineSetMemberPolicy,
+		RemediableOwnerKinds:         splitList(*remediableOwnerKinds),
+		MasterLabels:                 splitListBy(*masterLabels, ";"),
+	}
+	addMachineHealthCheck := func(mgr manager.Manager, opts manager.Options) error {
+		return machinehealthcheck.AddWithOptions(mgr, opts, mhcOpts)
+	}
+	if err := controller.AddToManager(mgr, opts, addMachineHealthCheck); err != nil {
 		klog.Fatal(err)
 	}
 
@@ -134,3 +336,19 @@ func main() {
 		klog.Fatal(err)
 	}
 }
+
+// splitList splits a comma separated list, dropping empty entries
+func splitList(list string) []string {
+	return splitListBy(list, ",")
+}
+
+// splitListBy splits list by the given separator, for lists whose entries may contain commas
+func splitListBy(list string, separator string) []string {
+	var out []string
+	for _, entry := range strings.Split(list, separator) {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
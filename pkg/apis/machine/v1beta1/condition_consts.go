@@ -26,4 +26,28 @@ const (
 	// TooManyUnhealthy is the reason used when too many Machines are unhealthy and the MachineHealthCheck is blocked
 	// from making any further remediations.
 	TooManyUnhealthyReason = "TooManyUnhealthy"
+
+	// InvalidSelectorReason is the reason used when the selector of the MachineHealthCheck cannot be parsed and
+	// the MachineHealthCheck cannot select any Machines until its spec is fixed.
+	InvalidSelectorReason = "InvalidSelector"
+
+	// RemediationInProgressCondition is set on MachineHealthChecks to show whether the MachineHealthCheck is
+	// currently remediating any Machines. Its message lists the Machines being remediated.
+	RemediationInProgressCondition ConditionType = "RemediationInProgress"
+
+	// NoUnhealthyMachinesReason is the reason used when the MachineHealthCheck has no Machines to remediate.
+	NoUnhealthyMachinesReason = "NoUnhealthyMachines"
+
+	// RemediationSucceedingCondition is set on MachineHealthChecks once remediation of their Machines failed
+	// repeatedly, to show whether remediation is still failing. It is not set on MachineHealthChecks whose
+	// remediation never failed repeatedly.
+	RemediationSucceedingCondition ConditionType = "RemediationSucceeding"
+
+	// RepeatedRemediationFailuresReason is the reason used when remediation of the Machines of the
+	// MachineHealthCheck failed in several consecutive reconciles.
+	RepeatedRemediationFailuresReason = "RepeatedRemediationFailures"
+
+	// ControlPlaneDeletionBlockedReason is the reason used when masters of the MachineHealthCheck need
+	// remediation by deletion, which the MachineHealthCheck does not allow.
+	ControlPlaneDeletionBlockedReason = "ControlPlaneDeletionBlocked"
 )
@@ -55,10 +55,38 @@ type MachineHealthCheckSpec struct {
 
 	// UnhealthyConditions contains a list of the conditions that determine
 	// whether a node is considered unhealthy.  The conditions are combined in a
-	// logical OR, i.e. if any of the conditions is met, the node is unhealthy.
-	//
-	// +kubebuilder:validation:MinItems=1
-	UnhealthyConditions []UnhealthyCondition `json:"unhealthyConditions"`
+	// logical OR, i.e. if any of the conditions is met, the node is unhealthy,
+	// unless UnhealthyConditionsMode is "All".
+	// May be omitted when NodeReadyTimeout is set.
+	// +optional
+	UnhealthyConditions []UnhealthyCondition `json:"unhealthyConditions,omitempty"`
+
+	// UnhealthyConditionsMode defines how the unhealthy conditions are combined.
+	// With "Any", the default, the node is unhealthy as soon as any of the conditions is met.
+	// With "All", the node is only unhealthy once the conditions of every type are met at the
+	// same time, each for at least its timeout. Conditions of the same type are alternatives,
+	// as a node condition has a single status at a time.
+	// +optional
+	// +kubebuilder:validation:Enum=Any;All
+	UnhealthyConditionsMode UnhealthyConditionsMode `json:"unhealthyConditionsMode,omitempty"`
+
+	// VetoCondition is the type of a node condition maintained by an external agent, e.g. an
+	// aggregated "ClusterHealthy" condition. A node reporting this condition as True is never
+	// remediated, regardless of the unhealthy conditions and of the machine phase.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	VetoCondition corev1.NodeConditionType `json:"vetoCondition,omitempty"`
+
+	// NodeReadyTimeout is a shortcut for the unhealthy conditions Ready=False and
+	// Ready=Unknown with this timeout. Explicit UnhealthyConditions of type Ready
+	// with the same status take precedence.
+	// Expects an unsigned duration string of decimal numbers each with optional
+	// fraction and a unit suffix, eg "300ms", "1.5h" or "2h45m".
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +optional
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +kubebuilder:validation:Type:=string
+	NodeReadyTimeout metav1.Duration `json:"nodeReadyTimeout,omitempty"`
 
 	// Any farther remediation is only allowed if at most "MaxUnhealthy" machines selected by
 	// "selector" are not healthy.
@@ -80,12 +108,75 @@ type MachineHealthCheckSpec struct {
 	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
 	// +kubebuilder:validation:Type:=string
 	NodeStartupTimeout metav1.Duration `json:"nodeStartupTimeout,omitempty"`
+
+	// UnhealthyMachinePhases contains a list of machine phases that cause a
+	// machine to be considered unhealthy, regardless of the state of its node.
+	// An empty list disables phase based health checking.
+	// +optional
+	// +kubebuilder:default:={"Failed"}
+	UnhealthyMachinePhases []string `json:"unhealthyMachinePhases,omitempty"`
+
+	// UnhealthyMachinePhasePolicy defines which of the machine phase and the node health wins
+	// when a machine is in one of the UnhealthyMachinePhases while its node may still be healthy,
+	// e.g. a Failed machine whose node reports Ready.
+	// With "PreferMachinePhase", the default, the machine is unhealthy regardless of its node.
+	// With "PreferNodeHealth", a machine with a node is judged on the health of its node alone,
+	// the phase only makes machines without a node unhealthy.
+	// With "RequireBoth", the machine is only unhealthy if its node is unhealthy too, i.e. it has
+	// no node, its node does not exist or one of the unhealthy conditions is in its unhealthy
+	// status, regardless of its timeout.
+	// +optional
+	// +kubebuilder:validation:Enum=PreferMachinePhase;PreferNodeHealth;RequireBoth
+	UnhealthyMachinePhasePolicy UnhealthyMachinePhasePolicy `json:"unhealthyMachinePhasePolicy,omitempty"`
+
+	// RemediationWindow restricts remediation to a daily time window.
+	// Unhealthy machines detected outside of the window are remediated once
+	// the window opens. Remediation is allowed at any time when unset.
+	// +optional
+	RemediationWindow *RemediationWindow `json:"remediationWindow,omitempty"`
+}
+
+// RemediationWindow represents a daily time window in UTC. A window whose end
+// is before its start spans midnight, a window whose start equals its end
+// spans the whole day.
+type RemediationWindow struct {
+	// Start of the window, expects a time of day in UTC formatted as "HH:MM".
+	// +kubebuilder:validation:Pattern="^([01][0-9]|2[0-3]):[0-5][0-9]$"
+	Start string `json:"start"`
+
+	// End of the window, expects a time of day in UTC formatted as "HH:MM".
+	// +kubebuilder:validation:Pattern="^([01][0-9]|2[0-3]):[0-5][0-9]$"
+	End string `json:"end"`
 }
 
+// UnhealthyConditionsMode defines how the unhealthy conditions of a MachineHealthCheck are combined
+type UnhealthyConditionsMode string
+
+const (
+	// UnhealthyConditionsModeAny considers a node unhealthy if any of the unhealthy conditions is met
+	UnhealthyConditionsModeAny UnhealthyConditionsMode = "Any"
+	// UnhealthyConditionsModeAll considers a node unhealthy only if the unhealthy conditions of every type are met
+	UnhealthyConditionsModeAll UnhealthyConditionsMode = "All"
+)
+
+// UnhealthyMachinePhasePolicy defines how a machine in an unhealthy phase with a healthy node is evaluated
+type UnhealthyMachinePhasePolicy string
+
+const (
+	// UnhealthyMachinePhasePolicyPreferMachinePhase considers a machine in an unhealthy phase unhealthy regardless of its node
+	UnhealthyMachinePhasePolicyPreferMachinePhase UnhealthyMachinePhasePolicy = "PreferMachinePhase"
+	// UnhealthyMachinePhasePolicyPreferNodeHealth evaluates a machine in an unhealthy phase on the health of its node, if it has one
+	UnhealthyMachinePhasePolicyPreferNodeHealth UnhealthyMachinePhasePolicy = "PreferNodeHealth"
+	// UnhealthyMachinePhasePolicyRequireBoth considers a machine in an unhealthy phase unhealthy only if its node is unhealthy too
+	UnhealthyMachinePhasePolicyRequireBoth UnhealthyMachinePhasePolicy = "RequireBoth"
+)
+
 // UnhealthyCondition represents a Node condition type and value with a timeout
 // specified as a duration.  When the named condition has been in the given
 // status for at least the timeout value, a node is considered unhealthy.
 type UnhealthyCondition struct {
+	// Type is the type of the node condition, either a built-in type such as "Ready"
+	// or a custom type reported by a monitoring agent, e.g. "KernelDeadlock".
 	// +kubebuilder:validation:Type=string
 	// +kubebuilder:validation:MinLength=1
 	Type corev1.NodeConditionType `json:"type"`
@@ -118,6 +209,46 @@ type MachineHealthCheckStatus struct {
 	// +optional
 	RemediationsAllowed int32 `json:"remediationsAllowed"`
 
+	// EffectiveSelector is the label selector resolved from the selector in the spec,
+	// in the string form accepted by kubectl, e.g. "machine.openshift.io/cluster-api-machine-role=worker"
+	// +optional
+	EffectiveSelector string `json:"effectiveSelector,omitempty"`
+
 	// Conditions defines the current state of the MachineHealthCheck
 	Conditions Conditions `json:"conditions,omitempty"`
+
+	// Targets reports the health of every machine of the MachineHealthCheck. It is only reported
+	// when the MachineHealthCheck has the machine.openshift.io/verbose-status annotation.
+	// +optional
+	Targets []MachineHealthCheckTargetStatus `json:"targets,omitempty"`
+}
+
+// TargetVerdict is the outcome of the health check of a machine
+type TargetVerdict string
+
+const (
+	// TargetHealthy is the verdict of machines which are healthy
+	TargetHealthy TargetVerdict = "Healthy"
+	// TargetNeedsRemediation is the verdict of machines which need remediation
+	TargetNeedsRemediation TargetVerdict = "NeedsRemediation"
+	// TargetPending is the verdict of machines which are neither healthy nor need remediation yet,
+	// e.g. with an unhealthy node condition within its timeout or being remediated
+	TargetPending TargetVerdict = "Pending"
+)
+
+// MachineHealthCheckTargetStatus reports the health of a single machine of a MachineHealthCheck
+type MachineHealthCheckTargetStatus struct {
+	// Machine is the name of the machine
+	Machine string `json:"machine"`
+
+	// Node is the name of the node of the machine, empty if the machine has no node
+	// +optional
+	Node string `json:"node,omitempty"`
+
+	// Verdict is the outcome of the last health check of the machine
+	Verdict TargetVerdict `json:"verdict"`
+
+	// UnhealthyCondition is the condition which made the machine need remediation
+	// +optional
+	UnhealthyCondition string `json:"unhealthyCondition,omitempty"`
 }
@@ -194,12 +194,23 @@ func (in *MachineHealthCheckSpec) DeepCopyInto(out *MachineHealthCheckSpec) {
 		*out = make([]UnhealthyCondition, len(*in))
 		copy(*out, *in)
 	}
+	out.NodeReadyTimeout = in.NodeReadyTimeout
 	if in.MaxUnhealthy != nil {
 		in, out := &in.MaxUnhealthy, &out.MaxUnhealthy
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
 	out.NodeStartupTimeout = in.NodeStartupTimeout
+	if in.UnhealthyMachinePhases != nil {
+		in, out := &in.UnhealthyMachinePhases, &out.UnhealthyMachinePhases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemediationWindow != nil {
+		in, out := &in.RemediationWindow, &out.RemediationWindow
+		*out = new(RemediationWindow)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineHealthCheckSpec.
@@ -232,6 +243,11 @@ func (in *MachineHealthCheckStatus) DeepCopyInto(out *MachineHealthCheckStatus)
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]MachineHealthCheckTargetStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineHealthCheckStatus.
@@ -244,6 +260,21 @@ func (in *MachineHealthCheckStatus) DeepCopy() *MachineHealthCheckStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineHealthCheckTargetStatus) DeepCopyInto(out *MachineHealthCheckTargetStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineHealthCheckTargetStatus.
+func (in *MachineHealthCheckTargetStatus) DeepCopy() *MachineHealthCheckTargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineHealthCheckTargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineList) DeepCopyInto(out *MachineList) {
 	*out = *in
@@ -538,6 +569,21 @@ func (in *ProviderSpec) DeepCopy() *ProviderSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationWindow) DeepCopyInto(out *RemediationWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationWindow.
+func (in *RemediationWindow) DeepCopy() *RemediationWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnhealthyCondition) DeepCopyInto(out *UnhealthyCondition) {
 	*out = *in
@@ -144,6 +144,9 @@ type ReconcileMachine struct {
 	eventRecorder record.EventRecorder
 
 	actuator Actuator
+
+	// kubeClient is used to drain nodes, a client is built from config when nil
+	kubeClient kubernetes.Interface
 }
 
 // Reconcile reads that state of the cluster for a Machine object and makes changes based on the state read
@@ -325,8 +328,16 @@ func (r *ReconcileMachine) Reconcile(ctx context.Context, request reconcile.Requ
 	return reconcile.Result{RequeueAfter: requeueAfter}, nil
 }
 
+// getKubeClient returns the client used to drain nodes
+func (r *ReconcileMachine) getKubeClient() (kubernetes.Interface, error) {
+	if r.kubeClient != nil {
+		return r.kubeClient, nil
+	}
+	return kubernetes.NewForConfig(r.config)
+}
+
 func (r *ReconcileMachine) drainNode(machine *machinev1.Machine) error {
-	kubeClient, err := kubernetes.NewForConfig(r.config)
+	kubeClient, err := r.getKubeClient()
 	if err != nil {
 		return fmt.Errorf("unable to build kube client: %v", err)
 	}
@@ -354,6 +365,7 @@ func (r *ReconcileMachine) drainNode(machine *machinev1.Machine) error {
 			if usingEviction {
 				verbStr = "Evicted"
 			}
+			metrics.DrainPodsEvictedTotal.Inc()
 			klog.Info(fmt.Sprintf("%s pod from Node", verbStr),
 				"pod", fmt.Sprintf("%s/%s", pod.Name, pod.Namespace))
 		},
@@ -376,11 +388,14 @@ func (r *ReconcileMachine) drainNode(machine *machinev1.Machine) error {
 		return &RequeueAfterError{RequeueAfter: 20 * time.Second}
 	}
 
+	drainStart := time.Now()
 	if err := drain.RunNodeDrain(drainer, node.Name); err != nil {
 		// Machine still tries to terminate after drain failure
 		klog.Warningf("drain failed for machine %q: %v", machine.Name, err)
+		metrics.DrainFailuresTotal.Inc()
 		return &RequeueAfterError{RequeueAfter: 20 * time.Second}
 	}
+	metrics.DrainDurationSeconds.Observe(time.Since(drainStart).Seconds())
 
 	klog.Infof("drain successful for machine %q", machine.Name)
 	r.eventRecorder.Eventf(machine, corev1.EventTypeNormal, "Deleted", "Node %q drained", node.Name)
@@ -26,11 +26,17 @@ import (
 
 	. "github.com/onsi/gomega"
 	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -761,3 +767,96 @@ func TestDelayIfRequeueAfterError(t *testing.T) {
 		})
 	}
 }
+
+func TestDrainNodeMetrics(t *testing.T) {
+	counterValue := func(counter prometheus.Counter) float64 {
+		m := &dto.Metric{}
+		if err := counter.Write(m); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return m.GetCounter().GetValue()
+	}
+	drainCount := func() uint64 {
+		m := &dto.Metric{}
+		if err := metrics.DrainDurationSeconds.(prometheus.Metric).Write(m); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return m.GetHistogram().GetSampleCount()
+	}
+
+	testCases := []struct {
+		name             string
+		deleteErr        error
+		expectedError    bool
+		expectedEvicted  float64
+		expectedFailures float64
+		expectedDrains   uint64
+	}{
+		{
+			name:            "drain succeeds",
+			expectedEvicted: 3,
+			expectedDrains:  1,
+		},
+		{
+			name:             "eviction fails",
+			deleteErr:        errors.New("eviction failed"),
+			expectedError:    true,
+			expectedFailures: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node"},
+				Status: corev1.NodeStatus{
+					Conditions: []corev1.NodeCondition{
+						{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+					},
+				},
+			}
+			objects := []runtime.Object{node}
+			for i := 0; i < 3; i++ {
+				objects = append(objects, &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: "test"},
+					Spec:       corev1.PodSpec{NodeName: node.Name},
+				})
+			}
+			kubeClient := kubefake.NewSimpleClientset(objects...)
+			if tc.deleteErr != nil {
+				kubeClient.PrependReactor("delete", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tc.deleteErr
+				})
+			}
+
+			machine := &machinev1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: "test"},
+				Status: machinev1.MachineStatus{
+					NodeRef: &corev1.ObjectReference{Name: node.Name},
+				},
+			}
+			r := &ReconcileMachine{
+				eventRecorder: record.NewFakeRecorder(10),
+				kubeClient:    kubeClient,
+			}
+
+			evictedBefore := counterValue(metrics.DrainPodsEvictedTotal)
+			failuresBefore := counterValue(metrics.DrainFailuresTotal)
+			drainsBefore := drainCount()
+
+			err := r.drainNode(machine)
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("Expected error: %v, got: %v", tc.expectedError, err)
+			}
+			if got := counterValue(metrics.DrainPodsEvictedTotal) - evictedBefore; got != tc.expectedEvicted {
+				t.Errorf("Expected %v pods evicted, got %v", tc.expectedEvicted, got)
+			}
+			if got := counterValue(metrics.DrainFailuresTotal) - failuresBefore; got != tc.expectedFailures {
+				t.Errorf("Expected %v drain failures, got %v", tc.expectedFailures, got)
+			}
+			if got := drainCount() - drainsBefore; got != tc.expectedDrains {
+				t.Errorf("Expected %v drain duration observations, got %v", tc.expectedDrains, got)
+			}
+		})
+	}
+}
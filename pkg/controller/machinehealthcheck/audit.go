@@ -0,0 +1,170 @@
+package machinehealthcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	defaultAuditWebhookTimeout       = 5 * time.Second
+	defaultAuditWebhookRetries       = 3
+	defaultAuditWebhookRetryInterval = time.Second
+	// defaultAuditWebhookQueueSize bounds the records waiting for delivery, records
+	// audited while the queue is full are dropped
+	defaultAuditWebhookQueueSize = 1024
+
+	auditOutcomeSucceeded = "Succeeded"
+	auditOutcomeFailed    = "Failed"
+)
+
+// auditRecord is the JSON document posted to the audit webhook for every remediation
+type auditRecord struct {
+	MachineHealthCheck string        `json:"machineHealthCheck"`
+	Machine            string        `json:"machine"`
+	Node               string        `json:"node"`
+	Strategy           string        `json:"strategy"`
+	Reason             string        `json:"reason"`
+	Outcome            string        `json:"outcome"`
+	Severity           string        `json:"severity"`
+	Instance           *instanceInfo `json:"instance,omitempty"`
+	Error              string        `json:"error,omitempty"`
+	Timestamp          time.Time     `json:"timestamp"`
+}
+
+// auditDelivery is a record queued for delivery along with the MHC it is counted against
+type auditDelivery struct {
+	mhc    types.NamespacedName
+	record auditRecord
+}
+
+// auditWebhook posts remediation records to an external HTTP sink. Records are queued by the
+// reconciler and posted by Start in the background, so that a slow or failing sink never
+// delays remediation.
+type auditWebhook struct {
+	url           string
+	client        *http.Client
+	retries       int
+	retryInterval time.Duration
+	queue         chan auditDelivery
+}
+
+var _ manager.Runnable = &auditWebhook{}
+
+func newAuditWebhook(url string) *auditWebhook {
+	return &auditWebhook{
+		url:           url,
+		client:        &http.Client{Timeout: defaultAuditWebhookTimeout},
+		retries:       defaultAuditWebhookRetries,
+		retryInterval: defaultAuditWebhookRetryInterval,
+		queue:         make(chan auditDelivery, defaultAuditWebhookQueueSize),
+	}
+}
+
+// enqueue queues the record for delivery, it returns false if the queue is full
+func (w *auditWebhook) enqueue(mhc types.NamespacedName, record auditRecord) bool {
+	select {
+	case w.queue <- auditDelivery{mhc: mhc, record: record}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Start posts the queued records until the context is done
+func (w *auditWebhook) Start(ctx context.Context) error {
+	for {
+		select {
+		case delivery := <-w.queue:
+			w.deliver(ctx, delivery)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// deliver posts the record of the delivery, failures are logged and counted
+func (w *auditWebhook) deliver(ctx context.Context, delivery auditDelivery) {
+	if err := w.post(ctx, delivery.record); err != nil {
+		klog.Errorf("%s: failed to post remediation audit record: %v", delivery.record.Machine, err)
+		metrics.ObserveMachineHealthCheckAuditWebhookFailure(delivery.mhc.Name, delivery.mhc.Namespace)
+	}
+}
+
+// post sends the record to the webhook, retrying on failure until the context is done
+func (w *auditWebhook) post(ctx context.Context, record auditRecord) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %v", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = w.postOnce(ctx, body)
+		if err == nil || attempt >= w.retries {
+			return err
+		}
+		klog.V(3).Infof("Posting audit record to %s failed (attempt %d/%d): %v", w.url, attempt, w.retries, err)
+		select {
+		case <-time.After(w.retryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (w *auditWebhook) postOnce(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %q", resp.Status)
+	}
+	return nil
+}
+
+// audit queues the remediation of the target for the audit webhook, if configured.
+// Failures are logged and counted but never block remediation.
+func (t *target) audit(r *ReconcileMachineHealthCheck, strategy string, remediationErr error) {
+	if r.auditWebhook == nil {
+		return
+	}
+
+	record := auditRecord{
+		MachineHealthCheck: namespacedName(&t.MHC).String(),
+		Machine:            namespacedName(&t.Machine).String(),
+		Node:               t.nodeName(),
+		Strategy:           strategy,
+		Reason:             t.unhealthyReason(),
+		Outcome:            auditOutcomeSucceeded,
+		Severity:           t.remediationSeverity(r),
+		Timestamp:          r.now().UTC(),
+	}
+	if info := machineInstanceInfo(&t.Machine); info != (instanceInfo{}) {
+		record.Instance = &info
+	}
+	if remediationErr != nil {
+		record.Outcome = auditOutcomeFailed
+		record.Error = remediationErr.Error()
+	}
+
+	if !r.auditWebhook.enqueue(namespacedName(&t.MHC), record) {
+		klog.Errorf("%s: audit webhook queue is full, dropping remediation audit record", t.string())
+		metrics.ObserveMachineHealthCheckAuditWebhookFailure(t.MHC.Name, t.MHC.Namespace)
+	}
+}
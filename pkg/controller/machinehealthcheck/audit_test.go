@@ -0,0 +1,197 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+)
+
+// auditServer is a fake audit webhook sink recording the posted records
+type auditServer struct {
+	lock       sync.Mutex
+	statusCode int
+	requests   int
+	records    []auditRecord
+}
+
+func (s *auditServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.requests++
+	record := auditRecord{}
+	if err := json.NewDecoder(req.Body).Decode(&record); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.records = append(s.records, record)
+	w.WriteHeader(s.statusCode)
+}
+
+func auditWebhookFailures(t *testing.T, mhc *mapiv1beta1.MachineHealthCheck) float64 {
+	counter, err := metrics.MachineHealthCheckAuditWebhookFailuresTotal.GetMetricWithLabelValues(mhc.Name, mhc.Namespace)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	metric := &dto.Metric{}
+	if err := counter.(prometheus.Metric).Write(metric); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func TestRemediateAuditWebhook(t *testing.T) {
+	testCases := []struct {
+		testCase         string
+		statusCode       int
+		expectedRequests int
+		expectedFailures float64
+	}{
+		{
+			testCase:         "webhook accepts record",
+			statusCode:       http.StatusOK,
+			expectedRequests: 1,
+			expectedFailures: 0,
+		},
+		{
+			testCase:         "webhook fails",
+			statusCode:       http.StatusInternalServerError,
+			expectedRequests: defaultAuditWebhookRetries,
+			expectedFailures: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			sink := &auditServer{statusCode: tc.statusCode}
+			server := httptest.NewServer(sink)
+			defer server.Close()
+
+			mhc := maotesting.NewMachineHealthCheck(tc.testCase)
+			node := maotesting.NewNode("node", false)
+			machine := maotesting.NewMachine("machine", node.Name)
+			target := target{
+				Machine: *machine,
+				Node:    node,
+				MHC:     *mhc,
+			}
+
+			r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(2), machine)
+			r.auditWebhook = newAuditWebhook(server.URL)
+			r.auditWebhook.retryInterval = time.Millisecond
+			failuresBefore := auditWebhookFailures(t, mhc)
+
+			// Audit failures never block the remediation
+			if err := target.remediate(r); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{}); !apierrors.IsNotFound(err) {
+				t.Errorf("Expected machine to be deleted, got: %v", err)
+			}
+
+			// the record is only posted once dequeued by the webhook runnable
+			if sink.requests != 0 {
+				t.Errorf("Expected no request during remediation, got %d", sink.requests)
+			}
+			select {
+			case delivery := <-r.auditWebhook.queue:
+				r.auditWebhook.deliver(ctx, delivery)
+			default:
+				t.Fatalf("Expected an audit record to be queued")
+			}
+
+			if sink.requests != tc.expectedRequests {
+				t.Errorf("Expected %d requests, got %d", tc.expectedRequests, sink.requests)
+			}
+			if failures := auditWebhookFailures(t, mhc) - failuresBefore; failures != tc.expectedFailures {
+				t.Errorf("Expected %v delivery failures, got %v", tc.expectedFailures, failures)
+			}
+
+			if len(sink.records) == 0 {
+				t.Fatalf("Expected an audit record")
+			}
+			record := sink.records[0]
+			if record.Machine != namespacedName(machine).String() {
+				t.Errorf("Expected machine %q, got %q", namespacedName(machine).String(), record.Machine)
+			}
+			if record.Node != node.Name {
+				t.Errorf("Expected node %q, got %q", node.Name, record.Node)
+			}
+			if record.Strategy != string(remediationStrategyDelete) {
+				t.Errorf("Expected strategy %q, got %q", remediationStrategyDelete, record.Strategy)
+			}
+			if record.Outcome != auditOutcomeSucceeded {
+				t.Errorf("Expected outcome %q, got %q", auditOutcomeSucceeded, record.Outcome)
+			}
+			if record.Severity != corev1.EventTypeNormal {
+				t.Errorf("Expected severity %q, got %q", corev1.EventTypeNormal, record.Severity)
+			}
+			if record.Reason != "condition Ready in state Unknown longer than 5m0s" {
+				t.Errorf("Unexpected reason %q", record.Reason)
+			}
+			if record.Timestamp.IsZero() {
+				t.Errorf("Expected timestamp to be set")
+			}
+		})
+	}
+}
+
+func TestAuditWebhookQueue(t *testing.T) {
+	sink := &auditServer{statusCode: http.StatusInternalServerError}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	mhc := maotesting.NewMachineHealthCheck("auditQueue")
+	target := target{
+		Machine: *maotesting.NewMachine("machine", "node"),
+		MHC:     *mhc,
+	}
+	r := newFakeReconciler()
+	r.auditWebhook = newAuditWebhook(server.URL)
+	r.auditWebhook.queue = make(chan auditDelivery, 1)
+	failuresBefore := auditWebhookFailures(t, mhc)
+
+	// records audited while the queue is full are dropped and counted as failures
+	target.audit(r, string(remediationStrategyDelete), nil)
+	target.audit(r, string(remediationStrategyDelete), nil)
+	if failures := auditWebhookFailures(t, mhc) - failuresBefore; failures != 1 {
+		t.Errorf("Expected 1 dropped record, got %v", failures)
+	}
+
+	// retries stop as soon as the runnable is stopped
+	r.auditWebhook.retryInterval = time.Hour
+	stopCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = r.auditWebhook.Start(stopCtx)
+	}()
+	wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		sink.lock.Lock()
+		defer sink.lock.Unlock()
+		return sink.requests > 0, nil
+	})
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("Expected the audit webhook to stop retrying once stopped")
+	}
+	if failures := auditWebhookFailures(t, mhc) - failuresBefore; failures != 2 {
+		t.Errorf("Expected the undelivered record to be counted, got %v failures", failures)
+	}
+}
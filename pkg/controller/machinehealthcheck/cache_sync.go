@@ -0,0 +1,30 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// cacheSyncTimeout bounds how long a reconcile waits for the caches to sync
+	cacheSyncTimeout = time.Second
+	// cacheSyncRequeue is the delay before reconciling again a MHC whose reconcile
+	// was deferred because the caches were not synced yet
+	cacheSyncRequeue = 5 * time.Second
+)
+
+// cacheSyncCheck returns whether the caches the reconciler reads from are synced,
+// waiting for them at most until the context is done
+type cacheSyncCheck func(ctx context.Context) bool
+
+// cachesSynced returns whether the caches of the reconciler are synced. Reading nodes and
+// machines from caches which are not synced yet returns partial results, e.g. a missing
+// node, which must not be mistaken for the actual health of the targets.
+func (r *ReconcileMachineHealthCheck) cachesSynced(ctx context.Context) bool {
+	if r.cacheSynced == nil {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(ctx, cacheSyncTimeout)
+	defer cancel()
+	return r.cacheSynced(ctx)
+}
@@ -0,0 +1,79 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"testing"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileCachesNotSynced(t *testing.T) {
+	testCases := []struct {
+		testCase        string
+		synced          bool
+		expectedResult  reconcile.Result
+		expectedDeleted bool
+		expectedEvents  []string
+	}{
+		{
+			testCase:        "caches synced",
+			synced:          true,
+			expectedDeleted: true,
+			expectedEvents:  []string{EventMachineDeleted},
+		},
+		{
+			testCase:       "caches not synced",
+			synced:         false,
+			expectedResult: reconcile.Result{RequeueAfter: cacheSyncRequeue},
+			expectedEvents: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			// the node of the machine is missing from the cache, which would be
+			// mistaken for a deleted node if the cache was not synced yet
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			machine := maotesting.NewMachine("machine", "node")
+
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder, mhc, machine)
+			var waited bool
+			r.cacheSynced = func(ctx context.Context) bool {
+				if _, ok := ctx.Deadline(); !ok {
+					t.Errorf("Expected waiting for the caches to be bounded")
+				}
+				waited = true
+				return tc.synced
+			}
+
+			result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !waited {
+				t.Errorf("Expected the caches sync to be checked")
+			}
+			if !tc.synced && result != tc.expectedResult {
+				t.Errorf("Expected result %v, got %v", tc.expectedResult, result)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+
+			err = r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{})
+			if deleted := err != nil; deleted != tc.expectedDeleted {
+				t.Errorf("Expected machine deleted: %t, got: %v", tc.expectedDeleted, err)
+			}
+
+			updatedMHC := &mapiv1beta1.MachineHealthCheck{}
+			if err := r.client.Get(ctx, namespacedName(mhc), updatedMHC); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !tc.synced && updatedMHC.Status.ExpectedMachines != nil {
+				t.Errorf("Expected status untouched while caches are not synced, got %d expected machines", *updatedMHC.Status.ExpectedMachines)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// clusterBreakerRequeue is the delay before retrying remediations suspended
+// because too many nodes of the cluster are not ready
+const clusterBreakerRequeue = time.Minute
+
+// parseClusterMaxNotReady validates the given number or percentage of not ready nodes
+// allowed cluster wide, an empty value disables the cluster wide circuit breaker
+func parseClusterMaxNotReady(maxNotReady string) (*intstr.IntOrString, error) {
+	if maxNotReady == "" {
+		return nil, nil
+	}
+	value := intstr.Parse(maxNotReady)
+	if _, _, err := getIntOrPercentValue(&value); err != nil {
+		return nil, fmt.Errorf("invalid cluster max not ready %q: %v", maxNotReady, err)
+	}
+	return &value, nil
+}
+
+// clusterRemediationSuspended returns true along with a description of the outage if more nodes
+// of the cluster are not ready than the cluster wide budget allows. So many nodes going not ready
+// at once points to a control plane or network wide problem, which remediating machines would only
+// make worse, so all remediation is suspended regardless of the maxUnhealthy of each MachineHealthCheck.
+func (r *ReconcileMachineHealthCheck) clusterRemediationSuspended() (bool, string, error) {
+	if r.clusterMaxNotReady == nil {
+		return false, "", nil
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(context.TODO(), nodes); err != nil {
+		return false, "", fmt.Errorf("failed to list nodes: %v", err)
+	}
+	var notReady int
+	for i := range nodes.Items {
+		ready := conditions.GetNodeCondition(&nodes.Items[i], corev1.NodeReady)
+		if ready == nil || ready.Status != corev1.ConditionTrue {
+			notReady++
+		}
+	}
+	maxNotReady, err := getValueFromIntOrPercent(r.clusterMaxNotReady, len(nodes.Items), false)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get value for cluster max not ready: %v", err)
+	}
+
+	suspended := notReady > maxNotReady
+	metrics.ObserveClusterRemediationSuspended(suspended)
+	if !suspended {
+		return false, "", nil
+	}
+	return true, fmt.Sprintf("total: %v, not ready: %v, maxNotReady: %v", len(nodes.Items), notReady, r.clusterMaxNotReady), nil
+}
@@ -0,0 +1,84 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"testing"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	dto "github.com/prometheus/client_model/go"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileClusterRemediationSuspended(t *testing.T) {
+	testCases := []struct {
+		testCase          string
+		notReadyNodes     int
+		expectedSuspended bool
+		expectedEvents    []string
+		expectedResult    reconcile.Result
+	}{
+		{
+			testCase:          "cluster wide outage",
+			notReadyNodes:     3,
+			expectedSuspended: true,
+			expectedEvents:    []string{EventClusterRemediationSuspended},
+			expectedResult:    reconcile.Result{RequeueAfter: clusterBreakerRequeue},
+		},
+		{
+			testCase:          "single unhealthy node",
+			notReadyNodes:     1,
+			expectedSuspended: false,
+			expectedEvents:    []string{EventMachineDeleted},
+			expectedResult:    reconcile.Result{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("breaker")
+			machine := maotesting.NewMachine("machine", "node-0")
+			objects := []runtime.Object{mhc, machine}
+			// four nodes, only the first one backs a machine covered by the MHC
+			for i := 0; i < 4; i++ {
+				node := maotesting.NewNode(fmt.Sprintf("node-%d", i), i >= tc.notReadyNodes)
+				if i == 0 {
+					node.Annotations[machineAnnotationKey] = namespacedName(machine).String()
+				}
+				objects = append(objects, node)
+			}
+
+			recorder := record.NewFakeRecorder(10)
+			r := newFakeReconcilerWithCustomRecorder(recorder, objects...)
+			maxNotReady := intstr.FromString("50%")
+			r.clusterMaxNotReady = &maxNotReady
+
+			result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tc.expectedResult {
+				t.Errorf("Expected result %+v, got %+v", tc.expectedResult, result)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+
+			err = r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{})
+			if deleted := apierrors.IsNotFound(err); deleted == tc.expectedSuspended {
+				t.Errorf("Expected machine deleted: %t, got: %v", !tc.expectedSuspended, err)
+			}
+
+			m := &dto.Metric{}
+			if err := metrics.ClusterRemediationSuspended.Write(m); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if suspended := m.GetGauge().GetValue() == 1; suspended != tc.expectedSuspended {
+				t.Errorf("Expected suspended metric: %t, got: %v", tc.expectedSuspended, m.GetGauge().GetValue())
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package machinehealthcheck
+
+import (
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// remediateTargets remediates the given targets and returns the error of each target, in the
+// order of the targets. Up to maxConcurrentRemediations targets are remediated concurrently, so
+// that slow remediations, e.g. reachability checks or audit webhooks timing out, do not hold up
+// the others. The targets are remediated one at a time when the cap is zero or one. The targets
+// were already filtered by the remediation budgets of the MHC, the cap only bounds how many of
+// them are in flight at once. Remediations tracked under the same flapping detection key, e.g.
+// deletions of machines of the same MachineSet, still run one at a time, see lockKey.
+func (r *ReconcileMachineHealthCheck) remediateTargets(targets []target) []error {
+	errs := make([]error, len(targets))
+	if r.maxConcurrentRemediations <= 1 {
+		for i := range targets {
+			klog.V(3).Infof("Reconciling %s: meet unhealthy criteria, triggers remediation", targets[i].string())
+			errs[i] = targets[i].remediate(r)
+		}
+		return errs
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, r.maxConcurrentRemediations)
+	for i := range targets {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			klog.V(3).Infof("Reconciling %s: meet unhealthy criteria, triggers remediation", targets[i].string())
+			errs[i] = targets[i].remediate(r)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
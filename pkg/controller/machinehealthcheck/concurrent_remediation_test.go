@@ -0,0 +1,167 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// concurrencyRecordingStrategy records the peak number of remediations in flight
+type concurrencyRecordingStrategy struct {
+	lock       sync.Mutex
+	inFlight   int
+	peak       int
+	remediated int
+}
+
+func (s *concurrencyRecordingStrategy) remediate(t *target, r *ReconcileMachineHealthCheck) error {
+	s.lock.Lock()
+	s.inFlight++
+	if s.inFlight > s.peak {
+		s.peak = s.inFlight
+	}
+	s.lock.Unlock()
+
+	// a slow remediation
+	time.Sleep(50 * time.Millisecond)
+
+	s.lock.Lock()
+	s.inFlight--
+	s.remediated++
+	s.lock.Unlock()
+	return nil
+}
+
+func TestReconcileMaxConcurrentRemediations(t *testing.T) {
+	testCases := []struct {
+		maxConcurrentRemediations int
+		expectedPeak              int
+	}{
+		{
+			maxConcurrentRemediations: 0,
+			expectedPeak:              1,
+		},
+		{
+			maxConcurrentRemediations: 2,
+			expectedPeak:              2,
+		},
+		{
+			maxConcurrentRemediations: 10,
+			expectedPeak:              5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("cap %d", tc.maxConcurrentRemediations), func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("concurrent")
+			mhc.Annotations = map[string]string{remediationStrategyAnnotation: "recording"}
+			objects := []runtime.Object{mhc}
+			for i := 0; i < 5; i++ {
+				node := maotesting.NewNode(fmt.Sprintf("node%d", i), false)
+				machine := maotesting.NewMachine(fmt.Sprintf("machine%d", i), node.Name)
+				node.Annotations[machineAnnotationKey] = namespacedName(machine).String()
+				objects = append(objects, node, machine)
+			}
+
+			strategy := &concurrencyRecordingStrategy{}
+			r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(20), objects...)
+			r.maxConcurrentRemediations = tc.maxConcurrentRemediations
+			r.remediationStrategies = defaultRemediationStrategies()
+			if err := r.remediationStrategies.register("recording", strategy); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if strategy.remediated != 5 {
+				t.Errorf("Expected 5 remediations, got %d", strategy.remediated)
+			}
+			if strategy.peak != tc.expectedPeak {
+				t.Errorf("Expected at most %d remediations in flight, got %d", tc.expectedPeak, strategy.peak)
+			}
+		})
+	}
+}
+
+func TestRemediateTargetsErrorOrder(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("order")
+	mhc.Annotations = map[string]string{remediationStrategyAnnotation: "failing"}
+	var targets []target
+	for i := 0; i < 4; i++ {
+		targets = append(targets, target{
+			Machine: *maotesting.NewMachine(fmt.Sprintf("machine%d", i), fmt.Sprintf("node%d", i)),
+			Node:    maotesting.NewNode(fmt.Sprintf("node%d", i), false),
+			MHC:     *mhc,
+		})
+	}
+
+	r := newFakeReconciler()
+	r.maxConcurrentRemediations = 3
+	r.remediationStrategies = newRemediationStrategyRegistry()
+	failing := remediationStrategyFunc(func(t *target, r *ReconcileMachineHealthCheck) error {
+		return fmt.Errorf("%s", t.Machine.Name)
+	})
+	if err := r.remediationStrategies.register("failing", failing); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	errs := r.remediateTargets(targets)
+	for i, err := range errs {
+		if err == nil || err.Error() != targets[i].Machine.Name {
+			t.Errorf("Expected error %q for target %d, got %v", targets[i].Machine.Name, i, err)
+		}
+	}
+}
+
+func TestRemediateTargetsMachineSetFlapping(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("flapping")
+	var targets []target
+	for i := 0; i < 2; i++ {
+		machine := maotesting.NewMachine(fmt.Sprintf("machine%d", i), fmt.Sprintf("node%d", i))
+		machine.OwnerReferences[0].Name = "machineset"
+		targets = append(targets, target{
+			Machine: *machine,
+			Node:    maotesting.NewNode(fmt.Sprintf("node%d", i), false),
+			MHC:     *mhc,
+		})
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	r := newFakeReconcilerWithCustomRecorder(recorder)
+	r.maxConcurrentRemediations = 2
+	r.machineSetFlappingTracker = newRemediationTracker(2, time.Hour)
+	key, _ := targets[0].machineSetDeletionKey(string(remediationStrategyDelete))
+	// one deletion below the threshold
+	r.machineSetFlappingTracker.record(key, r.now())
+
+	// a slow deletion, recorded for flapping detection once done
+	strategy := &concurrencyRecordingStrategy{}
+	r.remediationStrategies = newRemediationStrategyRegistry()
+	deleting := remediationStrategyFunc(func(t *target, r *ReconcileMachineHealthCheck) error {
+		if err := strategy.remediate(t, r); err != nil {
+			return err
+		}
+		t.recordRemediation(r, string(remediationStrategyDelete))
+		return nil
+	})
+	if err := r.remediationStrategies.register(remediationStrategyDelete, deleting); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i, err := range r.remediateTargets(targets) {
+		if err != nil {
+			t.Errorf("Unexpected error for target %d: %v", i, err)
+		}
+	}
+	if strategy.remediated != 1 {
+		t.Errorf("Expected a single deletion before reaching the threshold, got %d", strategy.remediated)
+	}
+	assertEvents(t, "concurrent deletions", []string{EventMachineSetFlappingDetected}, recorder.Events)
+}
@@ -0,0 +1,82 @@
+package machinehealthcheck
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// conditionTransitionTracker records the last transition time observed for each condition
+// of each node, so that transitions between reconciles are counted even if the node never
+// stays unhealthy long enough to be remediated
+type conditionTransitionTracker struct {
+	lock sync.Mutex
+	// transitions maps nodes to the last transition time observed for each of their conditions
+	transitions map[string]map[corev1.NodeConditionType]time.Time
+}
+
+// observe records the transition times of the conditions of the node and returns the conditions
+// whose transition time changed since the node was last observed. Conditions observed for the
+// first time are not reported, as their previous transition is unknown.
+func (c *conditionTransitionTracker) observe(node *corev1.Node) []corev1.NodeConditionType {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.transitions == nil {
+		c.transitions = map[string]map[corev1.NodeConditionType]time.Time{}
+	}
+	last, ok := c.transitions[node.Name]
+	if !ok {
+		last = map[corev1.NodeConditionType]time.Time{}
+		c.transitions[node.Name] = last
+	}
+
+	var transitioned []corev1.NodeConditionType
+	for _, condition := range node.Status.Conditions {
+		transitionTime := condition.LastTransitionTime.Time
+		if previous, ok := last[condition.Type]; ok && !previous.Equal(transitionTime) {
+			transitioned = append(transitioned, condition.Type)
+		}
+		last[condition.Type] = transitionTime
+	}
+	return transitioned
+}
+
+// forget removes the record of the given node and returns the conditions which were recorded
+// for it, e.g. when the node is gone
+func (c *conditionTransitionTracker) forget(node string) []corev1.NodeConditionType {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var conditionTypes []corev1.NodeConditionType
+	for conditionType := range c.transitions[node] {
+		conditionTypes = append(conditionTypes, conditionType)
+	}
+	delete(c.transitions, node)
+	return conditionTypes
+}
+
+// observeConditionTransitions counts the condition transitions of the node of the target since
+// it was last observed. The record of a node which was not found is dropped along with its series.
+func (r *ReconcileMachineHealthCheck) observeConditionTransitions(t *target) {
+	if t.Node == nil || t.Node.Name == "" {
+		return
+	}
+	if t.Node.UID == "" {
+		r.forgetConditionTransitions(t.Node.Name)
+		return
+	}
+	for _, conditionType := range r.conditionTransitions.observe(t.Node) {
+		metrics.ObserveNodeConditionTransition(t.Node.Name, string(conditionType))
+	}
+}
+
+// forgetConditionTransitions drops the record of the node along with its series, e.g. when
+// the node is deleted after its machine was remediated
+func (r *ReconcileMachineHealthCheck) forgetConditionTransitions(node string) {
+	for _, conditionType := range r.conditionTransitions.forget(node) {
+		metrics.DeleteNodeConditionTransitions(node, string(conditionType))
+	}
+}
@@ -0,0 +1,95 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestObserveConditionTransitions(t *testing.T) {
+	transitions := func(node string) float64 {
+		counter, err := metrics.NodeConditionTransitionsTotal.GetMetricWithLabelValues(node, string(corev1.NodeReady))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		metric := &dto.Metric{}
+		if err := counter.(prometheus.Metric).Write(metric); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return metric.GetCounter().GetValue()
+	}
+
+	node := maotesting.NewNode("flapping", true)
+	setReady := func(status corev1.ConditionStatus, transition time.Time) {
+		node.Status.Conditions = []corev1.NodeCondition{
+			{
+				Type:               corev1.NodeReady,
+				Status:             status,
+				LastTransitionTime: metav1.NewTime(transition),
+			},
+		}
+	}
+	target := target{
+		Machine: *maotesting.NewMachine("machine", node.Name),
+		Node:    node,
+		MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+	}
+	r := newFakeReconciler()
+	start := time.Now()
+
+	// the first observation only records the transition time
+	setReady(corev1.ConditionTrue, start)
+	r.observeConditionTransitions(&target)
+	if got := transitions(node.Name); got != 0 {
+		t.Errorf("Expected no transition, got %v", got)
+	}
+
+	setReady(corev1.ConditionFalse, start.Add(time.Minute))
+	r.observeConditionTransitions(&target)
+	// observing the node again without a transition does not count
+	r.observeConditionTransitions(&target)
+	setReady(corev1.ConditionTrue, start.Add(2*time.Minute))
+	r.observeConditionTransitions(&target)
+	if got := transitions(node.Name); got != 2 {
+		t.Errorf("Expected 2 transitions, got %v", got)
+	}
+
+	// a node which is not found is forgotten
+	target.Node = &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: node.Name}}
+	r.observeConditionTransitions(&target)
+	if got := transitions(node.Name); got != 0 {
+		t.Errorf("Expected transitions of a missing node to be reset, got %v", got)
+	}
+}
+
+func TestForgetConditionTransitionsOfDeletedNode(t *testing.T) {
+	node := maotesting.NewNode("deleted", true)
+	node.UID = "uid"
+	target := target{
+		Machine: *maotesting.NewMachine("machine", node.Name),
+		Node:    node,
+		MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+	}
+	r := newFakeReconciler()
+
+	r.observeConditionTransitions(&target)
+	node.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now())
+	r.observeConditionTransitions(&target)
+	labels := prometheus.Labels{"node": node.Name, "condition": string(corev1.NodeReady)}
+
+	// the machine of the node is deleted by remediation, so no target refers to the node
+	// anymore and only the node delete event is left to drop its record and series
+	r.mhcRequestsFromNode(node)
+	if _, ok := r.conditionTransitions.transitions[node.Name]; ok {
+		t.Errorf("Expected the record of a deleted node to be dropped")
+	}
+	if metrics.NodeConditionTransitionsTotal.Delete(labels) {
+		t.Errorf("Expected the series of a deleted node to be dropped")
+	}
+}
@@ -0,0 +1,72 @@
+package machinehealthcheck
+
+import (
+	"sync"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// unhealthyConditionsCache caches the unhealthy conditions of each MachineHealthCheck,
+// including the conditions synthesized from its NodeReadyTimeout, so they are resolved
+// once per spec change rather than for every evaluation of every target.
+// Entries are keyed by the MHC namespace and name and are only valid for the UID and
+// generation they were resolved from, so any change of the spec invalidates them.
+type unhealthyConditionsCache struct {
+	lock    sync.Mutex
+	entries map[string]*unhealthyConditionsCacheEntry
+}
+
+type unhealthyConditionsCacheEntry struct {
+	uid        types.UID
+	generation int64
+	conditions []mapiv1.UnhealthyCondition
+}
+
+func newUnhealthyConditionsCache() *unhealthyConditionsCache {
+	return &unhealthyConditionsCache{
+		entries: map[string]*unhealthyConditionsCacheEntry{},
+	}
+}
+
+// get returns the unhealthy conditions of the MHC, resolving them when the cached entry is
+// missing or stale. The returned slice is shared and must not be modified. MHCs without a
+// generation, i.e. not read from the API server, are never cached.
+func (c *unhealthyConditionsCache) get(mhc *mapiv1.MachineHealthCheck) []mapiv1.UnhealthyCondition {
+	if mhc.Generation == 0 {
+		return unhealthyConditions(mhc)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := namespacedName(mhc).String()
+	if entry, ok := c.entries[key]; ok && entry.uid == mhc.UID && entry.generation == mhc.Generation {
+		return entry.conditions
+	}
+	// copy the conditions, as they may share the spec of the MHC
+	conditions := append([]mapiv1.UnhealthyCondition{}, unhealthyConditions(mhc)...)
+	c.entries[key] = &unhealthyConditionsCacheEntry{
+		uid:        mhc.UID,
+		generation: mhc.Generation,
+		conditions: conditions,
+	}
+	return conditions
+}
+
+// forget drops the entry of the given MHC, e.g. when the MHC was deleted
+func (c *unhealthyConditionsCache) forget(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.entries, key)
+}
+
+// unhealthyConditions returns the unhealthy conditions of the MHC of the target,
+// as resolved when fetching the target or derived from the MHC when not set
+func (t *target) unhealthyConditions() []mapiv1.UnhealthyCondition {
+	if t.UnhealthyConditions != nil {
+		return t.UnhealthyConditions
+	}
+	return unhealthyConditions(&t.MHC)
+}
@@ -0,0 +1,75 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUnhealthyConditionsCacheInvalidation(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("mhc")
+	mhc.UID = "uid"
+	mhc.Generation = 1
+	cache := newUnhealthyConditionsCache()
+
+	conditions := cache.get(mhc)
+	if len(conditions) != 2 || conditions[0].Timeout.Duration != 300*time.Second {
+		t.Fatalf("Expected the conditions of the MHC, got: %v", conditions)
+	}
+
+	// the cached conditions are returned as long as the generation is unchanged
+	mhc.Spec.UnhealthyConditions[0].Timeout = metav1.Duration{Duration: time.Minute}
+	if conditions := cache.get(mhc); conditions[0].Timeout.Duration != 300*time.Second {
+		t.Errorf("Expected the cached timeout, got: %v", conditions[0].Timeout.Duration)
+	}
+
+	// a spec change bumps the generation
+	mhc.Generation = 2
+	if conditions := cache.get(mhc); conditions[0].Timeout.Duration != time.Minute {
+		t.Errorf("Expected the cache to be invalidated by the spec change, got timeout %v", conditions[0].Timeout.Duration)
+	}
+
+	// a recreated MHC starts over at the same generation
+	recreated := maotesting.NewMachineHealthCheck("mhc")
+	recreated.UID = "recreated"
+	recreated.Generation = 2
+	recreated.Spec.NodeReadyTimeout = metav1.Duration{Duration: time.Hour}
+	recreated.Spec.UnhealthyConditions = nil
+	conditions = cache.get(recreated)
+	if len(conditions) != 2 || conditions[0].Type != corev1.NodeReady || conditions[0].Timeout.Duration != time.Hour {
+		t.Errorf("Expected the conditions of the recreated MHC, got: %v", conditions)
+	}
+
+	// MHCs without a generation are not cached
+	uncached := maotesting.NewMachineHealthCheck("uncached")
+	cache.get(uncached)
+	if _, ok := cache.entries[namespacedName(uncached).String()]; ok {
+		t.Errorf("Expected MHC without a generation not to be cached")
+	}
+}
+
+func BenchmarkUnhealthyConditions(b *testing.B) {
+	mhc := maotesting.NewMachineHealthCheck("mhc")
+	mhc.UID = "uid"
+	mhc.Generation = 1
+	mhc.Spec.NodeReadyTimeout = metav1.Duration{Duration: 5 * time.Minute}
+	mhc.Spec.UnhealthyConditions = mhc.Spec.UnhealthyConditions[:1]
+
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			unhealthyConditions(mhc)
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		cache := newUnhealthyConditionsCache()
+		for i := 0; i < b.N; i++ {
+			cache.get(mhc)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// allowControlPlaneDeletionAnnotation opts a MachineHealthCheck into remediating control
+	// plane machines by deleting them. Deleting masters risks losing etcd quorum, so masters
+	// are never deleted by MachineHealthChecks which do not carry this annotation.
+	allowControlPlaneDeletionAnnotation = "machine.openshift.io/allow-control-plane-deletion"
+
+	// EventControlPlaneDeletionBlocked is emitted when the remediation of masters by deletion
+	// starts being refused because the MachineHealthCheck does not allow control plane deletion
+	EventControlPlaneDeletionBlocked string = "ControlPlaneDeletionBlocked"
+)
+
+// controlPlaneDeletionBlockedError is returned when the remediation of a master by deletion is
+// refused because its MachineHealthCheck does not allow deleting control plane machines
+type controlPlaneDeletionBlockedError struct {
+	target string
+}
+
+func (e *controlPlaneDeletionBlockedError) Error() string {
+	return fmt.Sprintf("%s: master remediation by deletion requires the %s annotation", e.target, allowControlPlaneDeletionAnnotation)
+}
+
+// checkControlPlaneDeletion returns a controlPlaneDeletionBlockedError if the target is a master
+// which would be remediated by deletion although its MachineHealthCheck does not allow deleting
+// control plane machines
+func (t *target) checkControlPlaneDeletion(r *ReconcileMachineHealthCheck, strategy mapiv1.RemediationStrategyType) error {
+	if strategy != remediationStrategyDelete || !t.isMaster(r.getMasterLabels()) {
+		return nil
+	}
+	if _, ok := t.MHC.Annotations[allowControlPlaneDeletionAnnotation]; ok {
+		return nil
+	}
+	return &controlPlaneDeletionBlockedError{target: t.string()}
+}
+
+// setControlPlaneDeletionBlockedCondition reports the masters of the MHC whose remediation by deletion was
+// refused by setting its RemediationSucceeding condition to False. The event is only emitted when the
+// condition is set, not on every reconcile refusing the deletions.
+func (r *ReconcileMachineHealthCheck) setControlPlaneDeletionBlockedCondition(mhc *mapiv1.MachineHealthCheck, blockedRemediations int) {
+	if previous := conditions.Get(mhc, mapiv1.RemediationSucceedingCondition); previous == nil || previous.Reason != mapiv1.ControlPlaneDeletionBlockedReason {
+		r.recorder.Eventf(
+			mhc,
+			corev1.EventTypeWarning,
+			EventControlPlaneDeletionBlocked,
+			"Refusing to remediate %d masters by deletion without the %s annotation on the MachineHealthCheck",
+			blockedRemediations,
+			allowControlPlaneDeletionAnnotation,
+		)
+	}
+	conditions.Set(mhc, conditions.FalseCondition(
+		mapiv1.RemediationSucceedingCondition,
+		mapiv1.ControlPlaneDeletionBlockedReason,
+		mapiv1.ConditionSeverityWarning,
+		"%d masters need remediation by deletion, which requires the %s annotation",
+		blockedRemediations,
+		allowControlPlaneDeletionAnnotation,
+	))
+}
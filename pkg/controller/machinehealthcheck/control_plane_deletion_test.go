@@ -0,0 +1,136 @@
+package machinehealthcheck
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestRemediateControlPlaneDeletion(t *testing.T) {
+	testCases := []struct {
+		testCase        string
+		master          bool
+		annotations     map[string]string
+		expectedBlocked bool
+		expectedDeleted bool
+		expectedEvents  []string
+	}{
+		{
+			testCase:        "master deletion blocked",
+			master:          true,
+			expectedBlocked: true,
+			expectedDeleted: false,
+			expectedEvents:  []string{},
+		},
+		{
+			testCase:        "master deletion allowed",
+			master:          true,
+			annotations:     map[string]string{allowControlPlaneDeletionAnnotation: ""},
+			expectedDeleted: true,
+			expectedEvents:  []string{EventMachineDeleted},
+		},
+		{
+			testCase:        "master deletion blocked for unknown strategy",
+			master:          true,
+			annotations:     map[string]string{remediationStrategyAnnotation: "unknown"},
+			expectedBlocked: true,
+			expectedDeleted: false,
+			expectedEvents:  []string{},
+		},
+		{
+			testCase:        "worker deletion",
+			master:          false,
+			expectedDeleted: true,
+			expectedEvents:  []string{EventMachineDeleted},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			mhc.Annotations = tc.annotations
+			machine := maotesting.NewMachine("machine", "node")
+			if tc.master {
+				machine.Labels[machineRoleLabel] = machineMasterRole
+			}
+			target := target{
+				Machine: *machine,
+				Node:    maotesting.NewNode("node", false),
+				MHC:     *mhc,
+			}
+
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder, machine)
+			err := target.remediate(r)
+			var blockedErr *controlPlaneDeletionBlockedError
+			if blocked := errors.As(err, &blockedErr); blocked != tc.expectedBlocked || (!blocked && err != nil) {
+				t.Fatalf("Expected blocked: %t, got: %v", tc.expectedBlocked, err)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+
+			err = r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{})
+			if deleted := apierrors.IsNotFound(err); deleted != tc.expectedDeleted {
+				t.Errorf("Expected machine deleted: %t, got: %v", tc.expectedDeleted, err)
+			}
+		})
+	}
+}
+
+func TestReconcileControlPlaneDeletionBlocked(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("blockedControlPlaneDeletion")
+	node := maotesting.NewNode("master", false)
+	machine := maotesting.NewMachine("master", node.Name)
+	machine.Labels[machineRoleLabel] = machineMasterRole
+
+	recorder := record.NewFakeRecorder(10)
+	r := newFakeReconcilerWithCustomRecorder(recorder, mhc, node, machine)
+	blockedBefore := remediationsBlocked(t, mhc, metrics.RemediationBlockedReasonControlPlaneDeletion)
+
+	for i, expectedEvents := range [][]string{{EventControlPlaneDeletionBlocked}, {}} {
+		step := fmt.Sprintf("reconcile %d", i+1)
+		if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+			t.Fatalf("%s: unexpected error: %v", step, err)
+		}
+		// the event is only emitted when the deletion starts being refused
+		assertEvents(t, step, expectedEvents, recorder.Events)
+	}
+
+	if err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{}); err != nil {
+		t.Errorf("Expected the master not to be deleted: %v", err)
+	}
+	if got := remediationsBlocked(t, mhc, metrics.RemediationBlockedReasonControlPlaneDeletion) - blockedBefore; got != 2 {
+		t.Errorf("Expected 2 blocked remediations, got %v", got)
+	}
+	got := &mapiv1beta1.MachineHealthCheck{}
+	if err := r.client.Get(ctx, namespacedName(mhc), got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	condition := conditions.Get(got, mapiv1beta1.RemediationSucceedingCondition)
+	if condition == nil || condition.Status != corev1.ConditionFalse || condition.Reason != mapiv1beta1.ControlPlaneDeletionBlockedReason {
+		t.Errorf("Expected RemediationSucceeding to be False with reason %s, got %+v", mapiv1beta1.ControlPlaneDeletionBlockedReason, condition)
+	}
+}
+
+// remediationsBlocked returns the number of remediations of targets of the MHC blocked for the given reason
+func remediationsBlocked(t *testing.T, mhc *mapiv1beta1.MachineHealthCheck, reason string) float64 {
+	counter, err := metrics.MachineHealthCheckRemediationBlockedTotal.GetMetricWithLabelValues(mhc.Name, mhc.Namespace, reason)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	metric := &dto.Metric{}
+	if err := counter.(prometheus.Metric).Write(metric); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return metric.GetCounter().GetValue()
+}
@@ -0,0 +1,171 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apimachineryerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	defaultMHCControllerName = "default-machinehealthcheck-controller"
+	// defaultMHCName is the name of the default MachineHealthCheck of worker machines
+	defaultMHCName = "machine-api-default-worker"
+	// defaultMHCNamespace is the namespace of the default MachineHealthCheck when
+	// the controller watches all namespaces
+	defaultMHCNamespace = "openshift-machine-api"
+	// defaultMHCSpecHashAnnotation records the hash of the spec last applied to the default
+	// MachineHealthCheck. A spec which no longer matches it was modified by a user and is left alone.
+	defaultMHCSpecHashAnnotation = "machine.openshift.io/default-spec-hash"
+	machineWorkerRole            = "worker"
+)
+
+// defaultMHCSpec returns the spec of the default MachineHealthCheck of worker machines.
+// Fields defaulted by the API server are set explicitly, so that the spec read back
+// matches the applied one.
+func defaultMHCSpec() mapiv1.MachineHealthCheckSpec {
+	maxUnhealthy := intstr.FromString("40%")
+	return mapiv1.MachineHealthCheckSpec{
+		Selector: metav1.LabelSelector{
+			MatchLabels: map[string]string{machineRoleLabel: machineWorkerRole},
+		},
+		UnhealthyConditions: []mapiv1.UnhealthyCondition{
+			{
+				Type:    corev1.NodeReady,
+				Status:  corev1.ConditionFalse,
+				Timeout: metav1.Duration{Duration: 5 * time.Minute},
+			},
+			{
+				Type:    corev1.NodeReady,
+				Status:  corev1.ConditionUnknown,
+				Timeout: metav1.Duration{Duration: 5 * time.Minute},
+			},
+		},
+		MaxUnhealthy:           &maxUnhealthy,
+		NodeStartupTimeout:     metav1.Duration{Duration: defaultNodeStartupTimeout},
+		UnhealthyMachinePhases: []string{machinePhaseFailed},
+	}
+}
+
+// hashMHCSpec returns a hash of the given spec
+func hashMHCSpec(spec mapiv1.MachineHealthCheckSpec) (string, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal MHC spec: %v", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// addDefaultMHCController adds a controller ensuring the default MachineHealthCheck of
+// worker machines exists in the given namespace to the Manager
+func addDefaultMHCController(mgr manager.Manager, namespace string) error {
+	if namespace == "" {
+		namespace = defaultMHCNamespace
+	}
+	r := &ReconcileDefaultMachineHealthCheck{
+		client:    mgr.GetClient(),
+		namespace: namespace,
+	}
+
+	c, err := controller.New(defaultMHCControllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// there is no event while the default MHC is absent, trigger the first reconcile explicitly
+	initial := make(chan event.GenericEvent, 1)
+	initial <- event.GenericEvent{Object: &mapiv1.MachineHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultMHCName, Namespace: namespace},
+	}}
+	if err := c.Watch(&source.Channel{Source: initial}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	isDefaultMHC := predicate.NewPredicateFuncs(func(o client.Object) bool {
+		return o.GetName() == defaultMHCName && o.GetNamespace() == namespace
+	})
+	return c.Watch(&source.Kind{Type: &mapiv1.MachineHealthCheck{}}, &handler.EnqueueRequestForObject{}, isDefaultMHC)
+}
+
+var _ reconcile.Reconciler = &ReconcileDefaultMachineHealthCheck{}
+
+// ReconcileDefaultMachineHealthCheck ensures the default MachineHealthCheck of worker machines exists
+type ReconcileDefaultMachineHealthCheck struct {
+	client    client.Client
+	namespace string
+}
+
+// Reconcile creates the default MachineHealthCheck if it is absent and updates it to the current
+// default spec unless a user modified it
+func (r *ReconcileDefaultMachineHealthCheck) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	klog.V(3).Infof("Reconciling default MHC %s", request.String())
+
+	spec := defaultMHCSpec()
+	hash, err := hashMHCSpec(spec)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	key := types.NamespacedName{Namespace: r.namespace, Name: defaultMHCName}
+	mhc := &mapiv1.MachineHealthCheck{}
+	if err := r.client.Get(ctx, key, mhc); err != nil {
+		if !apimachineryerrors.IsNotFound(err) {
+			return reconcile.Result{}, fmt.Errorf("failed to get default MHC %s: %v", key, err)
+		}
+		mhc = &mapiv1.MachineHealthCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        key.Name,
+				Namespace:   key.Namespace,
+				Annotations: map[string]string{defaultMHCSpecHashAnnotation: hash},
+			},
+			Spec: spec,
+		}
+		klog.Infof("Creating default MHC %s", key)
+		if err := r.client.Create(ctx, mhc); err != nil && !apimachineryerrors.IsAlreadyExists(err) {
+			return reconcile.Result{}, fmt.Errorf("failed to create default MHC %s: %v", key, err)
+		}
+		return reconcile.Result{}, nil
+	}
+
+	appliedHash, ok := mhc.Annotations[defaultMHCSpecHashAnnotation]
+	if !ok {
+		klog.V(3).Infof("Default MHC %s is not managed, leaving it alone", key)
+		return reconcile.Result{}, nil
+	}
+	currentHash, err := hashMHCSpec(mhc.Spec)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if currentHash != appliedHash {
+		klog.V(3).Infof("Default MHC %s was modified, leaving it alone", key)
+		return reconcile.Result{}, nil
+	}
+	if appliedHash == hash {
+		return reconcile.Result{}, nil
+	}
+
+	klog.Infof("Updating default MHC %s to the current default spec", key)
+	mhc.Spec = spec
+	mhc.Annotations[defaultMHCSpecHashAnnotation] = hash
+	if err := r.client.Update(ctx, mhc); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to update default MHC %s: %v", key, err)
+	}
+	return reconcile.Result{}, nil
+}
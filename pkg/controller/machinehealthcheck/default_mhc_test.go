@@ -0,0 +1,111 @@
+package machinehealthcheck
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileDefaultMachineHealthCheck(t *testing.T) {
+	defaultHash, err := hashMHCSpec(defaultMHCSpec())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// a spec previously applied by the controller, before the default spec changed
+	outdatedSpec := defaultMHCSpec()
+	outdatedSpec.NodeStartupTimeout = metav1.Duration{Duration: 20 * time.Minute}
+	outdatedHash, err := hashMHCSpec(outdatedSpec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// a spec modified by a user after the controller applied the default spec
+	userSpec := defaultMHCSpec()
+	userMaxUnhealthy := intstr.FromInt(1)
+	userSpec.MaxUnhealthy = &userMaxUnhealthy
+
+	newDefaultMHC := func(spec mapiv1beta1.MachineHealthCheckSpec, annotations map[string]string) *mapiv1beta1.MachineHealthCheck {
+		return &mapiv1beta1.MachineHealthCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        defaultMHCName,
+				Namespace:   namespace,
+				Annotations: annotations,
+			},
+			Spec: spec,
+		}
+	}
+
+	testCases := []struct {
+		testCase     string
+		existing     *mapiv1beta1.MachineHealthCheck
+		expectedSpec mapiv1beta1.MachineHealthCheckSpec
+		expectedHash string
+	}{
+		{
+			testCase:     "created when absent",
+			expectedSpec: defaultMHCSpec(),
+			expectedHash: defaultHash,
+		},
+		{
+			testCase:     "up to date",
+			existing:     newDefaultMHC(defaultMHCSpec(), map[string]string{defaultMHCSpecHashAnnotation: defaultHash}),
+			expectedSpec: defaultMHCSpec(),
+			expectedHash: defaultHash,
+		},
+		{
+			testCase:     "outdated default spec is updated",
+			existing:     newDefaultMHC(outdatedSpec, map[string]string{defaultMHCSpecHashAnnotation: outdatedHash}),
+			expectedSpec: defaultMHCSpec(),
+			expectedHash: defaultHash,
+		},
+		{
+			testCase:     "user edited spec is not clobbered",
+			existing:     newDefaultMHC(userSpec, map[string]string{defaultMHCSpecHashAnnotation: outdatedHash}),
+			expectedSpec: userSpec,
+			expectedHash: outdatedHash,
+		},
+		{
+			testCase:     "unmanaged MHC is not clobbered",
+			existing:     newDefaultMHC(userSpec, nil),
+			expectedSpec: userSpec,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			var objects []runtime.Object
+			if tc.existing != nil {
+				objects = append(objects, tc.existing)
+			}
+			r := &ReconcileDefaultMachineHealthCheck{
+				client:    fake.NewFakeClient(objects...),
+				namespace: namespace,
+			}
+
+			key := types.NamespacedName{Namespace: namespace, Name: defaultMHCName}
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			got := &mapiv1beta1.MachineHealthCheck{}
+			if err := r.client.Get(ctx, key, got); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got.Spec, tc.expectedSpec) {
+				t.Errorf("Expected spec %+v, got %+v", tc.expectedSpec, got.Spec)
+			}
+			if hash := got.Annotations[defaultMHCSpecHashAnnotation]; hash != tc.expectedHash {
+				t.Errorf("Expected spec hash %q, got %q", tc.expectedHash, hash)
+			}
+		})
+	}
+}
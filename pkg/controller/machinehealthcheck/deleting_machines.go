@@ -0,0 +1,20 @@
+package machinehealthcheck
+
+// isDeleting returns true if the machine of the target is being deleted. Such targets remain
+// targets of the MHC, so that their stuck finalizers are escalated and a deleting master holds
+// the remediation of other masters, but they do not count toward the machines expected by the
+// MHC nor toward remediation budgets, and they are never remediated again.
+func (t *target) isDeleting() bool {
+	return t.Machine.DeletionTimestamp != nil
+}
+
+// expectedTargetCount returns the number of targets whose machine is not being deleted
+func expectedTargetCount(targets []target) int {
+	count := 0
+	for _, t := range targets {
+		if !t.isDeleting() {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,75 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileExcludesDeletingMachines(t *testing.T) {
+	testCases := []struct {
+		testCase string
+		strategy mapiv1beta1.RemediationStrategyType
+	}{
+		{
+			testCase: "delete strategy",
+		},
+		{
+			testCase: "external strategy",
+			strategy: remediationStrategyExternal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			// a deleting machine counted as unhealthy would short circuit remediation
+			maxUnhealthy := intstr.FromInt(0)
+			mhc := maotesting.NewMachineHealthCheck("deleting")
+			mhc.Spec.MaxUnhealthy = &maxUnhealthy
+			if tc.strategy != "" {
+				mhc.Annotations = map[string]string{remediationStrategyAnnotation: string(tc.strategy)}
+			}
+			nodeHealthy := maotesting.NewNode("healthy", true)
+			nodeDeleting := maotesting.NewNode("deleting", false)
+			machineHealthy := maotesting.NewMachine("healthy", nodeHealthy.Name)
+			machineDeleting := maotesting.NewMachine("deleting", nodeDeleting.Name)
+			machineDeleting.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+
+			r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), mhc, nodeHealthy, nodeDeleting, machineHealthy, machineDeleting)
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			got := &mapiv1beta1.MachineHealthCheck{}
+			if err := r.client.Get(ctx, namespacedName(mhc), got); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if expected := derefInt(got.Status.ExpectedMachines); expected != 1 {
+				t.Errorf("Expected 1 expected machine, got %d", expected)
+			}
+			if healthy := derefInt(got.Status.CurrentHealthy); healthy != 1 {
+				t.Errorf("Expected 1 healthy machine, got %d", healthy)
+			}
+			if c := conditions.Get(got, mapiv1beta1.RemediationAllowedCondition); c == nil || c.Status != corev1.ConditionTrue {
+				t.Errorf("Expected remediation to be allowed, got conditions: %v", got.Status.Conditions)
+			}
+
+			// the deleting machine is not remediated again
+			machine := &mapiv1beta1.Machine{}
+			if err := r.client.Get(ctx, namespacedName(machineDeleting), machine); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, ok := machine.Annotations[machineExternalAnnotationKey]; ok {
+				t.Errorf("Expected deleting machine not to be annotated for external remediation")
+			}
+		})
+	}
+}
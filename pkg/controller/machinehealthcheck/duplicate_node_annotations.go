@@ -0,0 +1,48 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"fmt"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nodeMachineAnnotationIndex indexes nodes by the machine they are annotated with
+const nodeMachineAnnotationIndex = "nodeMachineAnnotationIndex"
+
+func indexNodeByMachineAnnotation(object client.Object) []string {
+	node, ok := object.(*corev1.Node)
+	if !ok {
+		klog.Warningf("Expected a node for indexing field, got: %T", object)
+		return nil
+	}
+
+	if annotation, ok := node.Annotations[machineAnnotationKey]; ok && annotation != "" {
+		return []string{annotation}
+	}
+
+	return nil
+}
+
+// duplicateNodes returns the names of the nodes other than the given node of the machine which
+// are annotated with the machine, e.g. due to a provisioning bug. Only the node referenced by the
+// machine is health checked, the duplicates are ignored.
+func (r *ReconcileMachineHealthCheck) duplicateNodes(machine mapiv1.Machine, node *corev1.Node) ([]string, error) {
+	key := namespacedName(&machine).String()
+	nodeList := &corev1.NodeList{}
+	if err := r.client.List(context.TODO(), nodeList, client.MatchingFields{nodeMachineAnnotationIndex: key}); err != nil {
+		return nil, fmt.Errorf("failed to list nodes annotated with machine %s: %v", key, err)
+	}
+
+	var duplicates []string
+	for i := range nodeList.Items {
+		// the annotation is checked again, the index is not supported by every client
+		if nodeList.Items[i].Name != node.Name && nodeList.Items[i].Annotations[machineAnnotationKey] == key {
+			duplicates = append(duplicates, nodeList.Items[i].Name)
+		}
+	}
+	return duplicates, nil
+}
@@ -0,0 +1,56 @@
+package machinehealthcheck
+
+import (
+	"testing"
+
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestDuplicateNodeAnnotations(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("duplicateNodes")
+	machine := maotesting.NewMachine("machine", "node")
+	node := maotesting.NewNode("node", true)
+	node.Annotations[machineAnnotationKey] = namespacedName(machine).String()
+	duplicate := maotesting.NewNode("duplicate", false)
+	duplicate.Annotations[machineAnnotationKey] = namespacedName(machine).String()
+	other := maotesting.NewNode("other", true)
+
+	duplicates := func() float64 {
+		counter, err := metrics.MachineHealthCheckDuplicateNodeAnnotationsTotal.GetMetricWithLabelValues(mhc.Name, mhc.Namespace)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		metric := &dto.Metric{}
+		if err := counter.(prometheus.Metric).Write(metric); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return metric.GetCounter().GetValue()
+	}
+	before := duplicates()
+
+	r := newFakeReconciler(mhc, machine, node, duplicate, other)
+	r.client = &machineNodeIndexClient{Client: r.client}
+
+	// the node referenced by the machine is the only one health checked
+	targets, err := r.getTargetsFromMHC(*mhc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(targets) != 1 || targets[0].nodeName() != node.Name {
+		t.Fatalf("Expected a single target with node %q, got %v", node.Name, targets)
+	}
+	if got := duplicates() - before; got != 1 {
+		t.Errorf("Expected 1 duplicate node, got %v", got)
+	}
+
+	// events of the duplicate node do not trigger the MHC
+	if requests := r.mhcRequestsFromNode(duplicate); len(requests) != 0 {
+		t.Errorf("Expected no requests for the duplicate node, got %v", requests)
+	}
+	if requests := r.mhcRequestsFromNode(node); len(requests) != 1 {
+		t.Errorf("Expected 1 request for the node of the machine, got %v", requests)
+	}
+}
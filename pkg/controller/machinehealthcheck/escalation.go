@@ -0,0 +1,63 @@
+package machinehealthcheck
+
+import (
+	"time"
+
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// EventRemediationEscalated is emitted when a machine is remediated although its node was
+// already remediated repeatedly without recovering
+const EventRemediationEscalated string = "RemediationEscalated"
+
+// count returns the number of remediations recorded under the given key within the window
+func (rt *remediationTracker) count(key string, now time.Time) int {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	return len(rt.prune(key, now))
+}
+
+// remediationSeverity classifies the recorded remediation of the target. Remediations are routine
+// and reported as corev1.EventTypeNormal, unless the machine of the target had already been remediated
+// the escalation threshold of times within the escalation window without recovering, in which case
+// the remediation is escalated and reported as corev1.EventTypeWarning.
+func (t *target) remediationSeverity(r *ReconcileMachineHealthCheck) string {
+	if r.escalationTracker == nil {
+		return corev1.EventTypeNormal
+	}
+	if r.escalationTracker.count(t.remediationKey(), r.now()) > r.escalationTracker.threshold {
+		return corev1.EventTypeWarning
+	}
+	return corev1.EventTypeNormal
+}
+
+// escalateRemediation records the remediation of the target for escalation and signals
+// the remediation with a Warning event and a dedicated metric if it is escalated
+func (t *target) escalateRemediation(r *ReconcileMachineHealthCheck) {
+	if r.escalationTracker == nil {
+		return
+	}
+	key := t.remediationKey()
+	r.escalationTracker.record(key, r.now())
+	if t.remediationSeverity(r) != corev1.EventTypeWarning {
+		return
+	}
+
+	remediations := r.escalationTracker.count(key, r.now())
+	klog.Warningf("%s: %s remediated %d times within %v without recovering, escalating",
+		t.string(), key, remediations, r.escalationTracker.window)
+	r.recorder.Eventf(
+		&t.Machine,
+		corev1.EventTypeWarning,
+		EventRemediationEscalated,
+		"Machine %v remediated: %v was remediated %d times within %v without recovering",
+		t.string(),
+		key,
+		remediations,
+		r.escalationTracker.window,
+	)
+	metrics.ObserveMachineHealthCheckRemediationEscalated(t.MHC.Name, t.MHC.Namespace)
+}
@@ -0,0 +1,101 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func remediationsEscalated(t *testing.T, name, namespace string) float64 {
+	counter, err := metrics.MachineHealthCheckRemediationEscalatedTotal.GetMetricWithLabelValues(name, namespace)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	metric := &dto.Metric{}
+	if err := counter.(prometheus.Metric).Write(metric); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func TestRemediationEscalation(t *testing.T) {
+	testCases := []struct {
+		testCase          string
+		threshold         int
+		previous          int
+		expectedSeverity  string
+		expectedEvents    []string
+		expectedEscalated float64
+	}{
+		{
+			testCase:          "escalation disabled",
+			threshold:         0,
+			previous:          5,
+			expectedSeverity:  corev1.EventTypeNormal,
+			expectedEvents:    []string{},
+			expectedEscalated: 0,
+		},
+		{
+			testCase:          "first remediation is routine",
+			threshold:         2,
+			previous:          0,
+			expectedSeverity:  corev1.EventTypeNormal,
+			expectedEvents:    []string{},
+			expectedEscalated: 0,
+		},
+		{
+			testCase:          "remediation within threshold is routine",
+			threshold:         2,
+			previous:          1,
+			expectedSeverity:  corev1.EventTypeNormal,
+			expectedEvents:    []string{},
+			expectedEscalated: 0,
+		},
+		{
+			testCase:          "remediation beyond threshold is escalated",
+			threshold:         2,
+			previous:          2,
+			expectedSeverity:  corev1.EventTypeWarning,
+			expectedEvents:    []string{EventRemediationEscalated},
+			expectedEscalated: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck(tc.testCase)
+			node := maotesting.NewNode("node", false)
+			target := target{
+				Machine: *maotesting.NewMachine("machine", node.Name),
+				Node:    node,
+				MHC:     *mhc,
+			}
+
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder)
+			if tc.threshold > 0 {
+				r.escalationTracker = newRemediationTracker(tc.threshold, time.Hour)
+				for i := 0; i < tc.previous; i++ {
+					r.escalationTracker.record(target.remediationKey(), time.Now())
+				}
+			}
+			escalatedBefore := remediationsEscalated(t, mhc.Name, mhc.Namespace)
+
+			target.recordRemediation(r, string(remediationStrategyDelete))
+
+			if severity := target.remediationSeverity(r); severity != tc.expectedSeverity {
+				t.Errorf("Expected severity %q, got %q", tc.expectedSeverity, severity)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+			if escalated := remediationsEscalated(t, mhc.Name, mhc.Namespace) - escalatedBefore; escalated != tc.expectedEscalated {
+				t.Errorf("Expected %v escalated remediations, got %v", tc.expectedEscalated, escalated)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNeedsRemediationFailedMachineTimeout(t *testing.T) {
+	testCases := []struct {
+		testCase                 string
+		failedFor                time.Duration
+		timeout                  time.Duration
+		expectedNeedsRemediation bool
+		expectedNextCheck        bool
+	}{
+		{
+			testCase:                 "recently failed",
+			failedFor:                time.Minute,
+			timeout:                  5 * time.Minute,
+			expectedNeedsRemediation: false,
+			expectedNextCheck:        true,
+		},
+		{
+			testCase:                 "failed for longer than timeout",
+			failedFor:                10 * time.Minute,
+			timeout:                  5 * time.Minute,
+			expectedNeedsRemediation: true,
+		},
+		{
+			testCase:                 "status never updated",
+			timeout:                  5 * time.Minute,
+			expectedNeedsRemediation: true,
+		},
+		{
+			testCase:                 "timeout disabled",
+			failedFor:                time.Minute,
+			expectedNeedsRemediation: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			phase := machinePhaseFailed
+			machine := maotesting.NewMachine("machine", "node")
+			machine.Status.Phase = &phase
+			if tc.failedFor > 0 {
+				lastUpdated := metav1.NewTime(time.Now().Add(-tc.failedFor))
+				machine.Status.LastUpdated = &lastUpdated
+			}
+			target := target{
+				Machine: *machine,
+				Node:    maotesting.NewNode("node", true),
+				MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+			}
+
+			needsRemediation, unhealthyCondition, nextCheck, err := target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: defaultNodeStartupTimeout, failedMachineTimeout: tc.timeout})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if needsRemediation != tc.expectedNeedsRemediation {
+				t.Errorf("Expected needsRemediation: %t, got: %t", tc.expectedNeedsRemediation, needsRemediation)
+			}
+			if needsRemediation && unhealthyCondition != unhealthyConditionMachinePhasePrefix+machinePhaseFailed {
+				t.Errorf("Expected unhealthy condition %q, got %q", unhealthyConditionMachinePhasePrefix+machinePhaseFailed, unhealthyCondition)
+			}
+			if tc.expectedNextCheck && (nextCheck <= 0 || nextCheck > tc.timeout) {
+				t.Errorf("Expected next check within the failed machine timeout, got %v", nextCheck)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package machinehealthcheck
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// remediationTracker records the remediations of each machine, or the deletions of the machines
+// of each MachineSet, over a sliding window to detect machines which keep failing after being remediated
+type remediationTracker struct {
+	lock sync.Mutex
+	// threshold is the number of remediations within window after which
+	// further remediations are suppressed
+	threshold int
+	window    time.Duration
+	// remediations contains the times of the remediations within window, keyed by remediationKey
+	remediations map[string][]time.Time
+	// keyLocks holds the locks of the keys remediations are in progress for, see lockKey
+	keyLocks map[string]*keyLock
+}
+
+// keyLock serializes the remediations tracked under a key, refs counts the
+// remediations holding or waiting for it
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newRemediationTracker(threshold int, window time.Duration) *remediationTracker {
+	return &remediationTracker{
+		threshold:    threshold,
+		window:       window,
+		remediations: map[string][]time.Time{},
+	}
+}
+
+// record records a remediation under the given key
+func (rt *remediationTracker) record(key string, now time.Time) {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	rt.remediations[key] = append(rt.prune(key, now), now)
+}
+
+// isFlapping returns true if at least threshold remediations were recorded
+// under the given key within the window
+func (rt *remediationTracker) isFlapping(key string, now time.Time) bool {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	return len(rt.prune(key, now)) >= rt.threshold
+}
+
+// lockKey waits until no other remediation tracked under the given key is in progress and returns
+// the function releasing the key. Targets are remediated concurrently, so the remediation must be
+// checked with isFlapping and recorded while holding the key, otherwise concurrent remediations of
+// targets sharing a key could all pass the check and exceed the threshold.
+func (rt *remediationTracker) lockKey(key string) func() {
+	rt.lock.Lock()
+	if rt.keyLocks == nil {
+		rt.keyLocks = map[string]*keyLock{}
+	}
+	kl, ok := rt.keyLocks[key]
+	if !ok {
+		kl = &keyLock{}
+		rt.keyLocks[key] = kl
+	}
+	kl.refs++
+	rt.lock.Unlock()
+
+	kl.Lock()
+	return func() {
+		kl.Unlock()
+
+		rt.lock.Lock()
+		defer rt.lock.Unlock()
+		kl.refs--
+		if kl.refs == 0 {
+			delete(rt.keyLocks, key)
+		}
+	}
+}
+
+// prune drops the remediations recorded under the given key which are outside of the window
+func (rt *remediationTracker) prune(key string, now time.Time) []time.Time {
+	var remediations []time.Time
+	for _, remediation := range rt.remediations[key] {
+		if now.Sub(remediation) < rt.window {
+			remediations = append(remediations, remediation)
+		}
+	}
+	if len(remediations) == 0 {
+		delete(rt.remediations, key)
+		return nil
+	}
+	rt.remediations[key] = remediations
+	return remediations
+}
+
+// remediationKey returns the key the remediations of the target are tracked by for flapping
+// detection and escalation. Remediations are tracked per machine, whatever the strategy.
+func (t *target) remediationKey() string {
+	return "Machine " + namespacedName(&t.Machine).String()
+}
+
+// machineSetDeletionKey returns the key deletions of the target are tracked by for MachineSet
+// flapping detection, and false if the strategy does not delete the machine or the machine is
+// not controlled by a MachineSet. A deleted machine is replaced by its MachineSet with a machine
+// and a node of a new name, so repeated deletions can only be told apart per MachineSet.
+func (t *target) machineSetDeletionKey(strategy string) (string, bool) {
+	if strategy != string(remediationStrategyDelete) {
+		return "", false
+	}
+	machineSet := getMachineSetFromMachine(t.Machine)
+	if machineSet == "" {
+		return "", false
+	}
+	return "MachineSet " + types.NamespacedName{Namespace: t.Machine.Namespace, Name: machineSet}.String(), true
+}
+
+// lockFlapping locks the keys the remediation of the target with the given strategy is tracked
+// by for flapping detection, and returns the function releasing them once the remediation is over
+func (t *target) lockFlapping(r *ReconcileMachineHealthCheck, strategy string) func() {
+	var unlocks []func()
+	if r.remediationTracker != nil {
+		unlocks = append(unlocks, r.remediationTracker.lockKey(t.remediationKey()))
+	}
+	if key, ok := t.machineSetDeletionKey(strategy); ok && r.machineSetFlappingTracker != nil {
+		unlocks = append(unlocks, r.machineSetFlappingTracker.lockKey(key))
+	}
+	return func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+}
+
+// flappingDetected returns true if the remediation of the target with the given strategy is
+// suppressed, either because the machine was remediated too often within the flapping window
+// or because its MachineSet had too many machines deleted within the window
+func (t *target) flappingDetected(r *ReconcileMachineHealthCheck, strategy string) bool {
+	if key := t.remediationKey(); r.remediationTracker != nil && r.remediationTracker.isFlapping(key, r.now()) {
+		r.recorder.Eventf(
+			&t.Machine,
+			corev1.EventTypeWarning,
+			EventFlappingDetected,
+			"Machine %v remediation suppressed: the machine was remediated %d times within %v",
+			t.string(),
+			r.remediationTracker.threshold,
+			r.remediationTracker.window,
+		)
+		klog.Warningf("%s: flapping detected, skipping remediation", t.string())
+		metrics.ObserveMachineHealthCheckFlappingDetected(t.MHC.Name, t.MHC.Namespace)
+		return true
+	}
+	if key, ok := t.machineSetDeletionKey(strategy); ok && r.machineSetFlappingTracker != nil && r.machineSetFlappingTracker.isFlapping(key, r.now()) {
+		r.recorder.Eventf(
+			&t.Machine,
+			corev1.EventTypeWarning,
+			EventMachineSetFlappingDetected,
+			"Machine %v remediation suppressed: %v had %d machines deleted by remediation within %v",
+			t.string(),
+			key,
+			r.machineSetFlappingTracker.threshold,
+			r.machineSetFlappingTracker.window,
+		)
+		klog.Warningf("%s: MachineSet flapping detected, skipping remediation", t.string())
+		metrics.ObserveMachineHealthCheckFlappingDetected(t.MHC.Name, t.MHC.Namespace)
+		return true
+	}
+	return false
+}
+
+// recordFlapping records the remediation of the target with the given strategy for flapping detection
+func (t *target) recordFlapping(r *ReconcileMachineHealthCheck, strategy string) {
+	if r.remediationTracker != nil {
+		r.remediationTracker.record(t.remediationKey(), r.now())
+	}
+	if key, ok := t.machineSetDeletionKey(strategy); ok && r.machineSetFlappingTracker != nil {
+		r.machineSetFlappingTracker.record(key, r.now())
+	}
+}
@@ -0,0 +1,198 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRemediationTracker(t *testing.T) {
+	now := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newRemediationTracker(2, time.Hour)
+
+	tracker.record("node", now.Add(-90*time.Minute))
+	if tracker.isFlapping("node", now) {
+		t.Errorf("Expected remediations outside of the window not to count")
+	}
+
+	tracker.record("node", now.Add(-30*time.Minute))
+	if tracker.isFlapping("node", now) {
+		t.Errorf("Expected a single remediation within the window not to be flapping")
+	}
+
+	tracker.record("node", now)
+	if !tracker.isFlapping("node", now) {
+		t.Errorf("Expected two remediations within the window to be flapping")
+	}
+	if tracker.isFlapping("other", now) {
+		t.Errorf("Expected remediations to be tracked per node")
+	}
+
+	if tracker.isFlapping("node", now.Add(45*time.Minute)) {
+		t.Errorf("Expected remediations to expire as the window slides")
+	}
+}
+
+func TestRemediateFlappingDetection(t *testing.T) {
+	now := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	recorder := record.NewFakeRecorder(10)
+	r := newFakeReconcilerWithCustomRecorder(recorder)
+	r.clock = clock.NewFakePassiveClock(now)
+	r.remediationTracker = newRemediationTracker(2, time.Hour)
+
+	node := maotesting.NewNode("node", false)
+	machine := maotesting.NewMachine("machine", node.Name)
+	if err := r.client.Create(ctx, machine); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	target := target{
+		Machine: *machine,
+		Node:    node,
+		MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+	}
+
+	// the machine was remediated twice, e.g. rebooted, within the window
+	r.remediationTracker.record(target.remediationKey(), now.Add(-20*time.Minute))
+	r.remediationTracker.record(target.remediationKey(), now.Add(-10*time.Minute))
+
+	if err := target.remediate(r); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	assertEvents(t, "flapping machine", []string{EventFlappingDetected}, recorder.Events)
+	if err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{}); err != nil {
+		t.Errorf("Expected the flapping machine not to be deleted, got: %v", err)
+	}
+}
+
+func TestRemediateMachineSetFlappingDetection(t *testing.T) {
+	// remediate the replacement machines of a MachineSet by deletion every 10 minutes, each
+	// replacement machine comes up with a new node
+	remediations := []time.Duration{0, 10 * time.Minute, 20 * time.Minute, 30 * time.Minute, 60 * time.Minute}
+
+	testCases := []struct {
+		testCase                    string
+		flappingThreshold           int
+		machineSetFlappingThreshold int
+		expectedDeleted             []bool
+		expectedEvents              [][]string
+	}{
+		{
+			testCase:          "replacement machines are not flapping machines",
+			flappingThreshold: 2,
+			expectedDeleted:   []bool{true, true, true, true, true},
+			expectedEvents: [][]string{
+				{EventMachineDeleted},
+				{EventMachineDeleted},
+				{EventMachineDeleted},
+				{EventMachineDeleted},
+				{EventMachineDeleted},
+			},
+		},
+		{
+			testCase:                    "deletions are counted per MachineSet",
+			machineSetFlappingThreshold: 2,
+			expectedDeleted:             []bool{true, true, false, false, true},
+			expectedEvents: [][]string{
+				{EventMachineDeleted},
+				{EventMachineDeleted},
+				{EventMachineSetFlappingDetected},
+				{EventMachineSetFlappingDetected},
+				// the first remediation has left the window
+				{EventMachineDeleted},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			now := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+			fakeClock := clock.NewFakePassiveClock(now)
+
+			recorder := record.NewFakeRecorder(10)
+			r := newFakeReconcilerWithCustomRecorder(recorder)
+			r.clock = fakeClock
+			if tc.flappingThreshold > 0 {
+				r.remediationTracker = newRemediationTracker(tc.flappingThreshold, time.Hour)
+			}
+			if tc.machineSetFlappingThreshold > 0 {
+				r.machineSetFlappingTracker = newRemediationTracker(tc.machineSetFlappingThreshold, time.Hour)
+			}
+
+			for i, elapsed := range remediations {
+				step := fmt.Sprintf("remediation after %v", elapsed)
+				fakeClock.SetTime(now.Add(elapsed))
+
+				node := maotesting.NewNode(fmt.Sprintf("node-%d", i), false)
+				machine := maotesting.NewMachine(fmt.Sprintf("machine-%d", i), node.Name)
+				machine.OwnerReferences[0].Name = "machineset"
+				if err := r.client.Create(ctx, machine); err != nil {
+					t.Fatalf("%s: unexpected error: %v", step, err)
+				}
+				target := target{
+					Machine: *machine,
+					Node:    node,
+					MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+				}
+
+				if err := target.remediate(r); err != nil {
+					t.Errorf("%s: unexpected error: %v", step, err)
+				}
+				assertEvents(t, step, tc.expectedEvents[i], recorder.Events)
+
+				err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{})
+				if deleted := apierrors.IsNotFound(err); deleted != tc.expectedDeleted[i] {
+					t.Errorf("%s: expected deleted: %t, got: %v", step, tc.expectedDeleted[i], err)
+				}
+			}
+		})
+	}
+}
+
+func TestMachineSetDeletionKey(t *testing.T) {
+	machine := maotesting.NewMachine("machine", "node")
+	machine.OwnerReferences[0].Name = "machineset"
+	orphan := maotesting.NewMachine("orphan", "node")
+	orphan.OwnerReferences = nil
+
+	testCases := []struct {
+		testCase    string
+		machine     *mapiv1beta1.Machine
+		strategy    mapiv1beta1.RemediationStrategyType
+		expectedKey string
+		expectedOK  bool
+	}{
+		{
+			testCase:    "deletion is tracked per MachineSet",
+			machine:     machine,
+			strategy:    remediationStrategyDelete,
+			expectedKey: "MachineSet " + maotesting.Namespace + "/machineset",
+			expectedOK:  true,
+		},
+		{
+			testCase: "reboot is not tracked per MachineSet",
+			machine:  machine,
+			strategy: remediationStrategyReboot,
+		},
+		{
+			testCase: "deletion of a machine without MachineSet is not tracked per MachineSet",
+			machine:  orphan,
+			strategy: remediationStrategyDelete,
+		},
+	}
+
+	for _, tc := range testCases {
+		target := target{Machine: *tc.machine}
+		if key, ok := target.machineSetDeletionKey(string(tc.strategy)); key != tc.expectedKey || ok != tc.expectedOK {
+			t.Errorf("%s: expected key %q, %t, got %q, %t", tc.testCase, tc.expectedKey, tc.expectedOK, key, ok)
+		}
+	}
+	if key := (&target{Machine: *machine}).remediationKey(); key != "Machine "+maotesting.Namespace+"/machine" {
+		t.Errorf("Expected remediations to be tracked per machine, got key %q", key)
+	}
+}
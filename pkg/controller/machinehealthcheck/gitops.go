@@ -0,0 +1,93 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// remediationStrategyGitOps remediates machines of fleets managed by a GitOps controller.
+	// Deleting such a machine would fight the source of truth, instead the remediation is
+	// requested with the GitOps remediation annotation and the GitOps controller recreates the
+	// machine on its own terms. The MHC never deletes the machine, even when it failed.
+	remediationStrategyGitOps = mapiv1.RemediationStrategyType("gitops")
+	// gitOpsRemediationAnnotation is set on machines needing remediation by the gitops strategy,
+	// its value is the time the remediation was requested. It is removed by the MHC once the
+	// machine is healthy again, so that the GitOps controller can tell pending requests apart.
+	gitOpsRemediationAnnotation = "machine.openshift.io/gitops-remediation-requested"
+
+	// EventGitOpsRemediationRequested is emitted when the GitOps remediation
+	// annotation was added to a machine
+	EventGitOpsRemediationRequested string = "GitOpsRemediationRequested"
+	// EventGitOpsRemediationFailed is emitted in case adding the GitOps
+	// remediation annotation to a machine failed
+	EventGitOpsRemediationFailed string = "GitOpsRemediationFailed"
+)
+
+// remediationStrategyGitOps requests the remediation of the target from the GitOps controller
+// managing its machine, unless it was already requested
+func (t *target) remediationStrategyGitOps(r *ReconcileMachineHealthCheck) error {
+	if _, ok := t.Machine.Annotations[gitOpsRemediationAnnotation]; ok {
+		klog.V(3).Infof("%s: GitOps remediation already requested", t.string())
+		return nil
+	}
+
+	klog.Infof("%s: requesting GitOps remediation", t.string())
+	if err := t.setGitOpsRemediation(r, r.now().UTC().Format(time.RFC3339)); err != nil {
+		t.audit(r, string(remediationStrategyGitOps), err)
+		r.recorder.Eventf(
+			&t.Machine,
+			corev1.EventTypeWarning,
+			EventGitOpsRemediationFailed,
+			"Requesting GitOps remediation of machine %v failed: %v",
+			t.string(),
+			err,
+		)
+		return err
+	}
+	r.recorder.Eventf(
+		&t.Machine,
+		corev1.EventTypeNormal,
+		EventGitOpsRemediationRequested,
+		"Requesting GitOps remediation of machine %v%s",
+		t.string(),
+		t.instanceDescription(),
+	)
+	t.observeTimeToRemediate()
+	t.recordRemediation(r, string(remediationStrategyGitOps))
+	t.audit(r, string(remediationStrategyGitOps), nil)
+	return nil
+}
+
+// clearGitOpsRemediation removes the GitOps remediation annotation from the machine of a healthy target
+func (t *target) clearGitOpsRemediation(r *ReconcileMachineHealthCheck) error {
+	if _, ok := t.Machine.Annotations[gitOpsRemediationAnnotation]; !ok {
+		return nil
+	}
+	klog.Infof("%s: healthy, clearing GitOps remediation request", t.string())
+	return t.setGitOpsRemediation(r, "")
+}
+
+// setGitOpsRemediation sets the GitOps remediation annotation of the machine of the target,
+// the annotation is removed when value is empty
+func (t *target) setGitOpsRemediation(r *ReconcileMachineHealthCheck, value string) error {
+	mergeBase := client.MergeFrom(t.Machine.DeepCopy())
+	if value == "" {
+		delete(t.Machine.Annotations, gitOpsRemediationAnnotation)
+	} else {
+		if t.Machine.Annotations == nil {
+			t.Machine.Annotations = map[string]string{}
+		}
+		t.Machine.Annotations[gitOpsRemediationAnnotation] = value
+	}
+	if err := r.client.Patch(context.TODO(), &t.Machine, mergeBase); err != nil {
+		return fmt.Errorf("%s: failed to set GitOps remediation annotation: %v", t.string(), err)
+	}
+	return nil
+}
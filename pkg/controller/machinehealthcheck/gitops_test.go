@@ -0,0 +1,135 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestRemediateGitOps(t *testing.T) {
+	testCases := []struct {
+		testCase           string
+		annotation         string
+		phase              string
+		expectedEvents     []string
+		expectedAnnotation string
+	}{
+		{
+			testCase:           "remediation requested",
+			expectedEvents:     []string{EventGitOpsRemediationRequested},
+			expectedAnnotation: "2020-01-01T00:00:00Z",
+		},
+		{
+			testCase:           "failed machine not deleted",
+			phase:              machinePhaseFailed,
+			expectedEvents:     []string{EventGitOpsRemediationRequested},
+			expectedAnnotation: "2020-01-01T00:00:00Z",
+		},
+		{
+			testCase:           "remediation already requested",
+			annotation:         "2019-12-31T00:00:00Z",
+			expectedEvents:     []string{},
+			expectedAnnotation: "2019-12-31T00:00:00Z",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			mhc.Annotations = map[string]string{remediationStrategyAnnotation: string(remediationStrategyGitOps)}
+			machine := maotesting.NewMachine("machine", "node")
+			if tc.annotation != "" {
+				machine.Annotations[gitOpsRemediationAnnotation] = tc.annotation
+			}
+			if tc.phase != "" {
+				machine.Status.Phase = pointer.StringPtr(tc.phase)
+			}
+			target := target{
+				Machine: *machine,
+				Node:    maotesting.NewNode("node", false),
+				MHC:     *mhc,
+			}
+
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder, machine)
+			r.clock = clock.NewFakePassiveClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+			if err := target.remediate(r); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+
+			// the machine is annotated rather than deleted
+			got := &mapiv1beta1.Machine{}
+			if err := r.client.Get(ctx, namespacedName(machine), got); apierrors.IsNotFound(err) {
+				t.Fatalf("Expected machine not to be deleted")
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.DeletionTimestamp != nil {
+				t.Errorf("Expected machine not to be deleted")
+			}
+			if value := got.Annotations[gitOpsRemediationAnnotation]; value != tc.expectedAnnotation {
+				t.Errorf("Expected %s annotation %q, got %q", gitOpsRemediationAnnotation, tc.expectedAnnotation, value)
+			}
+		})
+	}
+}
+
+func TestHealthCheckTargetsClearsGitOpsRemediation(t *testing.T) {
+	testCases := []struct {
+		testCase           string
+		nodeReady          bool
+		expectedHealthy    int
+		expectedAnnotation bool
+	}{
+		{
+			testCase:           "healed machine",
+			nodeReady:          true,
+			expectedHealthy:    1,
+			expectedAnnotation: false,
+		},
+		{
+			testCase:           "still unhealthy machine",
+			nodeReady:          false,
+			expectedHealthy:    0,
+			expectedAnnotation: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			mhc.Annotations = map[string]string{remediationStrategyAnnotation: string(remediationStrategyGitOps)}
+			machine := maotesting.NewMachine("machine", "node")
+			machine.Annotations[gitOpsRemediationAnnotation] = "2020-01-01T00:00:00Z"
+			tgt := target{
+				Machine: *machine,
+				Node:    maotesting.NewNode("node", tc.nodeReady),
+				MHC:     *mhc,
+			}
+
+			r := newFakeReconciler(machine)
+			healthy, _, _, errList := r.healthCheckTargets([]target{tgt}, defaultNodeStartupTimeout)
+			if len(errList) > 0 {
+				t.Fatalf("Unexpected errors: %v", errList)
+			}
+			if len(healthy) != tc.expectedHealthy {
+				t.Errorf("Expected %d healthy targets, got %d", tc.expectedHealthy, len(healthy))
+			}
+
+			got := &mapiv1beta1.Machine{}
+			if err := r.client.Get(ctx, namespacedName(machine), got); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, ok := got.Annotations[gitOpsRemediationAnnotation]; ok != tc.expectedAnnotation {
+				t.Errorf("Expected %s annotation: %t, got annotations: %v", gitOpsRemediationAnnotation, tc.expectedAnnotation, got.Annotations)
+			}
+		})
+	}
+}
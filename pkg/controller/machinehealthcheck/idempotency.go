@@ -0,0 +1,80 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// remediatedAtAnnotation records the time a machine was last remediated by deletion.
+// It is stamped before deleting the machine, so that a restarted or concurrent controller
+// does not remediate the same machine again within the remediation idempotency window,
+// and removed again if the deletion fails.
+const remediatedAtAnnotation = "machine.openshift.io/remediated-at"
+
+// recentlyRemediatedError is returned when a machine was remediated within the
+// remediation idempotency window and should not be remediated again yet
+type recentlyRemediatedError struct {
+	target string
+	after  time.Duration
+}
+
+func (e *recentlyRemediatedError) Error() string {
+	return fmt.Sprintf("%s: already remediated, remediation allowed again in %v", e.target, e.after)
+}
+
+// checkRecentlyRemediated returns a recentlyRemediatedError if the machine records a
+// remediation within the remediation idempotency window. An unparseable annotation is
+// ignored, so that a corrupted value cannot block remediation forever.
+func (t *target) checkRecentlyRemediated(r *ReconcileMachineHealthCheck, machine *mapiv1.Machine) error {
+	if r.remediationIdempotencyWindow <= 0 {
+		return nil
+	}
+	value, ok := machine.Annotations[remediatedAtAnnotation]
+	if !ok {
+		return nil
+	}
+	remediatedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		klog.Warningf("%s: ignoring invalid %s annotation %q: %v", t.string(), remediatedAtAnnotation, value, err)
+		return nil
+	}
+	if remaining := r.remediationIdempotencyWindow - r.now().Sub(remediatedAt); remaining > 0 {
+		return &recentlyRemediatedError{target: t.string(), after: remaining}
+	}
+	return nil
+}
+
+// markRemediated stamps the machine with the time of its remediation
+func (t *target) markRemediated(r *ReconcileMachineHealthCheck, machine *mapiv1.Machine) error {
+	if r.remediationIdempotencyWindow <= 0 {
+		return nil
+	}
+	mergeBase := client.MergeFrom(machine.DeepCopy())
+	if machine.Annotations == nil {
+		machine.Annotations = map[string]string{}
+	}
+	machine.Annotations[remediatedAtAnnotation] = r.now().UTC().Format(time.RFC3339)
+	if err := r.client.Patch(context.TODO(), machine, mergeBase); err != nil {
+		return fmt.Errorf("%s: failed to record remediation: %v", t.string(), err)
+	}
+	return nil
+}
+
+// clearRemediated removes the remediation time stamped on the machine after its deletion
+// failed, so that the deletion is retried without waiting for the idempotency window
+func (t *target) clearRemediated(r *ReconcileMachineHealthCheck, machine *mapiv1.Machine) error {
+	if _, ok := machine.Annotations[remediatedAtAnnotation]; !ok {
+		return nil
+	}
+	mergeBase := client.MergeFrom(machine.DeepCopy())
+	delete(machine.Annotations, remediatedAtAnnotation)
+	if err := r.client.Patch(context.TODO(), machine, mergeBase); err != nil {
+		return fmt.Errorf("%s: failed to clear remediation record: %v", t.string(), err)
+	}
+	return nil
+}
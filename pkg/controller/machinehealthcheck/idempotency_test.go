@@ -0,0 +1,170 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// lostDeleteClient counts machine deletions without performing them, as if
+// the deletion was lost by a controller restarting before acting on it, or
+// failed with deleteErr
+type lostDeleteClient struct {
+	client.Client
+	deletes   int
+	deleteErr error
+}
+
+func (c *lostDeleteClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.deletes++
+	return c.deleteErr
+}
+
+func TestReconcileRemediationIdempotency(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	mhc := maotesting.NewMachineHealthCheck("idempotency")
+	node := maotesting.NewNode("node", false)
+	machine := maotesting.NewMachine("machine", node.Name)
+	node.Annotations[machineAnnotationKey] = namespacedName(machine).String()
+
+	fakeClock := clock.NewFakeClock(now)
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), mhc, machine, node)
+	r.clock = fakeClock
+	r.remediationIdempotencyWindow = 10 * time.Minute
+	c := &lostDeleteClient{Client: r.client}
+	r.client = c
+
+	reconcileMHC := func(pass string, expectedResult reconcile.Result, expectedDeletes int) {
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", pass, err)
+		}
+		if result != expectedResult {
+			t.Errorf("%s: expected result %+v, got %+v", pass, expectedResult, result)
+		}
+		if c.deletes != expectedDeletes {
+			t.Errorf("%s: expected %d deletes, got %d", pass, expectedDeletes, c.deletes)
+		}
+	}
+
+	reconcileMHC("first pass", reconcile.Result{}, 1)
+
+	got := &mapiv1beta1.Machine{}
+	if err := r.client.Get(ctx, namespacedName(machine), got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := now.UTC().Format(time.RFC3339); got.Annotations[remediatedAtAnnotation] != expected {
+		t.Errorf("Expected %s annotation %q, got %q", remediatedAtAnnotation, expected, got.Annotations[remediatedAtAnnotation])
+	}
+
+	// the machine is not deleted again within the window
+	fakeClock.Step(4 * time.Minute)
+	reconcileMHC("within window", reconcile.Result{RequeueAfter: 6 * time.Minute}, 1)
+
+	fakeClock.Step(6 * time.Minute)
+	reconcileMHC("after window", reconcile.Result{}, 2)
+}
+
+func TestReconcileRemediationIdempotencyDeleteFailed(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("idempotency")
+	node := maotesting.NewNode("node", false)
+	machine := maotesting.NewMachine("machine", node.Name)
+	node.Annotations[machineAnnotationKey] = namespacedName(machine).String()
+
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), mhc, machine, node)
+	r.clock = clock.NewFakeClock(time.Now())
+	r.remediationIdempotencyWindow = 10 * time.Minute
+	c := &lostDeleteClient{Client: r.client, deleteErr: errors.New("denied by webhook")}
+	r.client = c
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err == nil {
+		t.Errorf("Expected the failed deletion to be reported")
+	}
+	if c.deletes != 1 {
+		t.Errorf("Expected 1 delete, got %d", c.deletes)
+	}
+
+	got := &mapiv1beta1.Machine{}
+	if err := r.client.Get(ctx, namespacedName(machine), got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value, ok := got.Annotations[remediatedAtAnnotation]; ok {
+		t.Errorf("Expected %s annotation to be removed after the failed deletion, got %q", remediatedAtAnnotation, value)
+	}
+
+	// the failed deletion is retried by the next reconcile, within the window
+	c.deleteErr = nil
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c.deletes != 2 {
+		t.Errorf("Expected the deletion to be retried, got %d deletes", c.deletes)
+	}
+}
+
+func TestCheckRecentlyRemediated(t *testing.T) {
+	now := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		testCase      string
+		window        time.Duration
+		annotations   map[string]string
+		expectedAfter time.Duration
+	}{
+		{
+			testCase:    "window disabled",
+			annotations: map[string]string{remediatedAtAnnotation: now.Format(time.RFC3339)},
+		},
+		{
+			testCase: "never remediated",
+			window:   10 * time.Minute,
+		},
+		{
+			testCase:      "remediated within window",
+			window:        10 * time.Minute,
+			annotations:   map[string]string{remediatedAtAnnotation: now.Add(-time.Minute).Format(time.RFC3339)},
+			expectedAfter: 9 * time.Minute,
+		},
+		{
+			testCase:    "remediated before window",
+			window:      10 * time.Minute,
+			annotations: map[string]string{remediatedAtAnnotation: now.Add(-time.Hour).Format(time.RFC3339)},
+		},
+		{
+			testCase:    "invalid annotation",
+			window:      10 * time.Minute,
+			annotations: map[string]string{remediatedAtAnnotation: "yesterday"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			machine := maotesting.NewMachine("machine", "node")
+			machine.Annotations = tc.annotations
+			target := target{Machine: *machine, MHC: *maotesting.NewMachineHealthCheck("mhc")}
+
+			r := newFakeReconciler()
+			r.clock = clock.NewFakePassiveClock(now)
+			r.remediationIdempotencyWindow = tc.window
+
+			err := target.checkRecentlyRemediated(r, machine)
+			if tc.expectedAfter == 0 {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			remediatedErr, ok := err.(*recentlyRemediatedError)
+			if !ok || remediatedErr.after != tc.expectedAfter {
+				t.Errorf("Expected remediation to be allowed again in %v, got: %v", tc.expectedAfter, err)
+			}
+		})
+	}
+}
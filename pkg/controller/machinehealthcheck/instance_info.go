@@ -0,0 +1,103 @@
+package machinehealthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+)
+
+// instanceInfo identifies the cloud instance backing a machine, fields are empty when unknown
+type instanceInfo struct {
+	ID     string `json:"id,omitempty"`
+	Region string `json:"region,omitempty"`
+	Zone   string `json:"zone,omitempty"`
+}
+
+var (
+	// instanceIDFields, instanceRegionFields and instanceZoneFields are the paths of the fields
+	// holding the instance information in the provider specs and statuses of the supported
+	// providers, e.g. placement.region for AWS, region for GCP or location for Azure
+	instanceIDFields     = [][]string{{"instanceId"}, {"vmId"}}
+	instanceRegionFields = [][]string{{"placement", "region"}, {"region"}, {"location"}}
+	instanceZoneFields   = [][]string{{"placement", "availabilityZone"}, {"zone"}}
+)
+
+// machineInstanceInfo extracts the instance information of the machine from its provider spec and
+// status on a best-effort basis, without depending on the provider types. The instance ID falls back
+// to the last segment of the provider ID. Unparseable provider specs and statuses are ignored.
+func machineInstanceInfo(machine *mapiv1.Machine) instanceInfo {
+	spec := parseProviderFields(machine, "providerSpec", machine.Spec.ProviderSpec.Value)
+	status := parseProviderFields(machine, "providerStatus", machine.Status.ProviderStatus)
+
+	info := instanceInfo{
+		ID:     lookupProviderField(status, instanceIDFields),
+		Region: lookupProviderField(spec, instanceRegionFields),
+		Zone:   lookupProviderField(spec, instanceZoneFields),
+	}
+	if info.ID == "" && machine.Spec.ProviderID != nil {
+		providerID := strings.TrimRight(*machine.Spec.ProviderID, "/")
+		info.ID = providerID[strings.LastIndex(providerID, "/")+1:]
+	}
+	return info
+}
+
+// parseProviderFields decodes the raw provider spec or status of the machine as a generic object
+func parseProviderFields(machine *mapiv1.Machine, field string, raw *runtime.RawExtension) map[string]interface{} {
+	if raw == nil || len(raw.Raw) == 0 {
+		return nil
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(raw.Raw, &fields); err != nil {
+		klog.V(4).Infof("%s/%s: ignoring unparseable %s: %v", machine.Namespace, machine.Name, field, err)
+		return nil
+	}
+	return fields
+}
+
+// lookupProviderField returns the first non empty string found at one of the given paths
+func lookupProviderField(fields map[string]interface{}, paths [][]string) string {
+	for _, path := range paths {
+		value := interface{}(fields)
+		for _, key := range path {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = object[key]
+		}
+		if s, ok := value.(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// String describes the instance for inclusion in event messages, e.g.
+// "instance i-0123, region us-east-1, zone us-east-1a". It is empty when nothing is known.
+func (i instanceInfo) String() string {
+	var parts []string
+	if i.ID != "" {
+		parts = append(parts, fmt.Sprintf("instance %s", i.ID))
+	}
+	if i.Region != "" {
+		parts = append(parts, fmt.Sprintf("region %s", i.Region))
+	}
+	if i.Zone != "" {
+		parts = append(parts, fmt.Sprintf("zone %s", i.Zone))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// instanceDescription returns the instance of the machine of the target as a
+// suffix for event messages, or an empty string when nothing is known
+func (t *target) instanceDescription() string {
+	if info := machineInstanceInfo(&t.Machine).String(); info != "" {
+		return fmt.Sprintf(" (%s)", info)
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package machinehealthcheck
+
+import (
+	"testing"
+
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+func TestMachineInstanceInfo(t *testing.T) {
+	testCases := []struct {
+		testCase            string
+		providerSpec        string
+		providerStatus      string
+		providerID          *string
+		expected            instanceInfo
+		expectedDescription string
+	}{
+		{
+			testCase:            "aws provider spec and status",
+			providerSpec:        `{"apiVersion":"awsproviderconfig.openshift.io/v1beta1","kind":"AWSMachineProviderConfig","instanceType":"m5.large","placement":{"region":"us-east-1","availabilityZone":"us-east-1a"}}`,
+			providerStatus:      `{"instanceId":"i-0123","instanceState":"running"}`,
+			expected:            instanceInfo{ID: "i-0123", Region: "us-east-1", Zone: "us-east-1a"},
+			expectedDescription: " (instance i-0123, region us-east-1, zone us-east-1a)",
+		},
+		{
+			testCase:            "gcp provider spec",
+			providerSpec:        `{"kind":"GCPMachineProviderSpec","region":"us-central1","zone":"us-central1-a"}`,
+			providerID:          pointer.StringPtr("gce://project/us-central1-a/instance-0"),
+			expected:            instanceInfo{ID: "instance-0", Region: "us-central1", Zone: "us-central1-a"},
+			expectedDescription: " (instance instance-0, region us-central1, zone us-central1-a)",
+		},
+		{
+			testCase:            "instance ID falls back to the provider ID",
+			providerID:          pointer.StringPtr("aws:///us-east-1a/i-0456"),
+			expected:            instanceInfo{ID: "i-0456"},
+			expectedDescription: " (instance i-0456)",
+		},
+		{
+			testCase:            "unparseable provider spec is ignored",
+			providerSpec:        `{"placement":`,
+			providerStatus:      `not json`,
+			expected:            instanceInfo{},
+			expectedDescription: "",
+		},
+		{
+			testCase:            "nothing known",
+			expected:            instanceInfo{},
+			expectedDescription: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			machine := maotesting.NewMachine("machine", "node")
+			if tc.providerSpec != "" {
+				machine.Spec.ProviderSpec.Value = &runtime.RawExtension{Raw: []byte(tc.providerSpec)}
+			}
+			if tc.providerStatus != "" {
+				machine.Status.ProviderStatus = &runtime.RawExtension{Raw: []byte(tc.providerStatus)}
+			}
+			machine.Spec.ProviderID = tc.providerID
+
+			if info := machineInstanceInfo(machine); info != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, info)
+			}
+			target := target{Machine: *machine}
+			if description := target.instanceDescription(); description != tc.expectedDescription {
+				t.Errorf("Expected description %q, got %q", tc.expectedDescription, description)
+			}
+		})
+	}
+}
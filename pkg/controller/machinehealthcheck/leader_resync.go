@@ -0,0 +1,56 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"fmt"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// leaderResync enqueues every MHC once this controller acquires the leadership. After a failover
+// the new leader re-evaluates all MHCs straight away, catching failures which occurred during
+// the leadership gap instead of waiting for the next event of their machines or nodes, or resync.
+type leaderResync struct {
+	client    client.Client
+	namespace string
+	// events receives the MHCs to reconcile, it is watched as a source of the controller
+	events chan event.GenericEvent
+}
+
+var _ manager.LeaderElectionRunnable = &leaderResync{}
+
+func newLeaderResync(c client.Client, namespace string) *leaderResync {
+	return &leaderResync{
+		client:    c,
+		namespace: namespace,
+		events:    make(chan event.GenericEvent, 1024),
+	}
+}
+
+// NeedLeaderElection makes the manager start the resync once the leadership is acquired,
+// after the caches are synced
+func (lr *leaderResync) NeedLeaderElection() bool {
+	return true
+}
+
+// Start enqueues all MHCs of the watched namespace and returns
+func (lr *leaderResync) Start(ctx context.Context) error {
+	mhcList := &mapiv1.MachineHealthCheckList{}
+	if err := lr.client.List(ctx, mhcList, client.InNamespace(lr.namespace)); err != nil {
+		return fmt.Errorf("failed to list MachineHealthChecks for leader resync: %v", err)
+	}
+
+	klog.V(3).Infof("Leadership acquired, re-evaluating %d MachineHealthChecks", len(mhcList.Items))
+	for i := range mhcList.Items {
+		select {
+		case lr.events <- event.GenericEvent{Object: &mhcList.Items[i]}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+func TestLeaderResync(t *testing.T) {
+	first := maotesting.NewMachineHealthCheck("first")
+	second := maotesting.NewMachineHealthCheck("second")
+	otherNamespace := maotesting.NewMachineHealthCheck("other")
+	otherNamespace.Namespace = "other"
+
+	resync := newLeaderResync(fake.NewFakeClient(first, second, otherNamespace), namespace)
+	if !resync.NeedLeaderElection() {
+		t.Errorf("Expected the resync to run on leader election")
+	}
+
+	// The events are turned into requests the way the controller watches them
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	src := &source.Channel{Source: resync.events}
+	if err := src.InjectStopChannel(ctx.Done()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := src.Start(ctx, &handler.EnqueueRequestForObject{}, queue); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := resync.Start(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		types.NamespacedName{Namespace: namespace, Name: first.Name}.String(),
+		types.NamespacedName{Namespace: namespace, Name: second.Name}.String(),
+	}
+	var requests []string
+	for len(requests) < len(expected) {
+		item, shutdown := queue.Get()
+		if shutdown {
+			break
+		}
+		requests = append(requests, item.(reconcile.Request).String())
+		queue.Done(item)
+	}
+	sort.Strings(requests)
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("Expected requests %v, got %v", expected, requests)
+	}
+	if queue.Len() != 0 {
+		t.Errorf("Expected no other requests, got %d", queue.Len())
+	}
+}
@@ -0,0 +1,28 @@
+package machinehealthcheck
+
+import (
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"k8s.io/klog/v2"
+)
+
+// machinePhaseUnhealthy returns true if the machine of the target is in an unhealthy phase
+// and the unhealthy machine phase policy of the MHC lets the phase win over the node health
+func (t *target) machinePhaseUnhealthy() bool {
+	if !t.hasUnhealthyMachinePhase() {
+		return false
+	}
+
+	switch t.MHC.Spec.UnhealthyMachinePhasePolicy {
+	case mapiv1.UnhealthyMachinePhasePolicyPreferNodeHealth:
+		if t.Node != nil {
+			klog.V(4).Infof("%s: ignoring machine phase %q, preferring the health of its node", t.string(), derefStringPointer(t.Machine.Status.Phase))
+			return false
+		}
+	case mapiv1.UnhealthyMachinePhasePolicyRequireBoth:
+		if t.Node != nil && t.Node.UID != "" && !t.hasUnhealthyCondition() {
+			klog.V(4).Infof("%s: ignoring machine phase %q, its node is healthy", t.string(), derefStringPointer(t.Machine.Status.Phase))
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/utils/pointer"
+)
+
+func TestNeedsRemediationUnhealthyMachinePhasePolicy(t *testing.T) {
+	now := time.Now()
+
+	const (
+		nodeHealthy   = "healthy"
+		nodeUnhealthy = "unhealthy within timeout"
+		nodeNone      = "none"
+	)
+
+	testCases := []struct {
+		testCase                   string
+		policy                     mapiv1beta1.UnhealthyMachinePhasePolicy
+		node                       string
+		expectedNeedsRemediation   bool
+		expectedUnhealthyCondition string
+		expectedNextCheck          time.Duration
+	}{
+		{
+			testCase:                   "default: healthy node, failed machine",
+			node:                       nodeHealthy,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: "MachinePhaseFailed",
+		},
+		{
+			testCase:                   "prefer machine phase: healthy node, failed machine",
+			policy:                     mapiv1beta1.UnhealthyMachinePhasePolicyPreferMachinePhase,
+			node:                       nodeHealthy,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: "MachinePhaseFailed",
+		},
+		{
+			testCase:                   "prefer machine phase: unhealthy node, failed machine",
+			policy:                     mapiv1beta1.UnhealthyMachinePhasePolicyPreferMachinePhase,
+			node:                       nodeUnhealthy,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: "MachinePhaseFailed",
+		},
+		{
+			testCase: "prefer node health: healthy node, failed machine",
+			policy:   mapiv1beta1.UnhealthyMachinePhasePolicyPreferNodeHealth,
+			node:     nodeHealthy,
+		},
+		{
+			testCase:          "prefer node health: unhealthy node, failed machine",
+			policy:            mapiv1beta1.UnhealthyMachinePhasePolicyPreferNodeHealth,
+			node:              nodeUnhealthy,
+			expectedNextCheck: 4*time.Minute + time.Second,
+		},
+		{
+			testCase:                   "prefer node health: no node, failed machine",
+			policy:                     mapiv1beta1.UnhealthyMachinePhasePolicyPreferNodeHealth,
+			node:                       nodeNone,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: "MachinePhaseFailed",
+		},
+		{
+			testCase: "require both: healthy node, failed machine",
+			policy:   mapiv1beta1.UnhealthyMachinePhasePolicyRequireBoth,
+			node:     nodeHealthy,
+		},
+		{
+			testCase:                   "require both: unhealthy node, failed machine",
+			policy:                     mapiv1beta1.UnhealthyMachinePhasePolicyRequireBoth,
+			node:                       nodeUnhealthy,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: "MachinePhaseFailed",
+		},
+		{
+			testCase:                   "require both: no node, failed machine",
+			policy:                     mapiv1beta1.UnhealthyMachinePhasePolicyRequireBoth,
+			node:                       nodeNone,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: "MachinePhaseFailed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			mhc.Spec.UnhealthyMachinePhasePolicy = tc.policy
+			machine := maotesting.NewMachine("machine", "node")
+			machine.Status.Phase = pointer.StringPtr(machinePhaseFailed)
+
+			var node *corev1.Node
+			switch tc.node {
+			case nodeHealthy:
+				node = maotesting.NewNode("node", true)
+				node.Status.Conditions[0].LastTransitionTime = metav1.NewTime(now.Add(-time.Hour))
+			case nodeUnhealthy:
+				node = maotesting.NewNode("node", false)
+				node.Status.Conditions[0].LastTransitionTime = metav1.NewTime(now.Add(-time.Minute))
+			default:
+				machine.Status.NodeRef = nil
+			}
+			target := target{
+				Machine: *machine,
+				Node:    node,
+				MHC:     *mhc,
+				clock:   clock.NewFakeClock(now),
+			}
+
+			needsRemediation, unhealthyCondition, nextCheck, err := target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: defaultNodeStartupTimeout})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if needsRemediation != tc.expectedNeedsRemediation {
+				t.Errorf("Expected needsRemediation: %t, got: %t", tc.expectedNeedsRemediation, needsRemediation)
+			}
+			if unhealthyCondition != tc.expectedUnhealthyCondition {
+				t.Errorf("Expected unhealthy condition %q, got %q", tc.expectedUnhealthyCondition, unhealthyCondition)
+			}
+			if nextCheck != tc.expectedNextCheck {
+				t.Errorf("Expected next check %v, got %v", tc.expectedNextCheck, nextCheck)
+			}
+		})
+	}
+}
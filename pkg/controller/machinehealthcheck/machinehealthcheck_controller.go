@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,15 +17,19 @@ import (
 	"github.com/openshift/machine-api-operator/pkg/metrics"
 	"github.com/openshift/machine-api-operator/pkg/util/conditions"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apimachineryerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	apimachineryutilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -35,15 +40,30 @@ const (
 	machineAnnotationKey          = "machine.openshift.io/machine"
 	machineExternalAnnotationKey  = "host.metal3.io/external-remediation"
 	nodeMasterLabel               = "node-role.kubernetes.io/master"
+	nodeControlPlaneLabel         = "node-role.kubernetes.io/control-plane"
 	machineRoleLabel              = "machine.openshift.io/cluster-api-machine-role"
 	machineMasterRole             = "master"
 	machinePhaseFailed            = "Failed"
 	remediationStrategyAnnotation = "machine.openshift.io/remediation-strategy"
+	remediationStrategyDelete     = mapiv1.RemediationStrategyType("delete")
 	remediationStrategyExternal   = mapiv1.RemediationStrategyType("external-baremetal")
 	defaultNodeStartupTimeout     = 10 * time.Minute
 	machineNodeNameIndex          = "machineNodeNameIndex"
 	controllerName                = "machinehealthcheck-controller"
 
+	// preTerminateHookAnnotationPrefix is the prefix of annotations holding
+	// the deletion of a machine until an external controller removes them
+	preTerminateHookAnnotationPrefix = "pre-terminate.delete.hook.machine.cluster.x-k8s.io"
+	// remediationHeldRequeue is the delay before retrying a remediation held by a hook
+	remediationHeldRequeue = time.Minute
+
+	// Unhealthy conditions reported for targets which are not unhealthy due to a node condition
+	unhealthyConditionMachinePhasePrefix = "MachinePhase"
+	unhealthyConditionNodeStartupTimeout = "NodeStartupTimeout"
+	unhealthyConditionNodeNotFound       = "NodeNotFound"
+	unhealthyConditionCordonedNotReady   = "CordonedNotReady"
+	unhealthyConditionNodeLeaseStale     = "NodeLeaseStale"
+
 	// Event types
 	// EventRemediationRestricted is emitted in case when machine remediation
 	// is restricted by remediation circuit shorting logic
@@ -66,20 +86,261 @@ const (
 	// EventExternalAnnotationAdded is emitted when external annotation was
 	// successfully added to a Node object
 	EventExternalAnnotationAdded string = "ExternalAnnotationAdded"
+	// EventRemediationHeld is emitted in case remediation of a machine is
+	// held by a pre-terminate hook annotation
+	EventRemediationHeld string = "RemediationHeld"
+	// EventSkippedProtectedRole is emitted in case an unhealthy machine has
+	// a protected role and is therefore not remediated
+	EventSkippedProtectedRole string = "SkippedProtectedRole"
+	// EventNoRemediationTriggers is emitted in case a MachineHealthCheck
+	// configures nothing which could mark a machine unhealthy
+	EventNoRemediationTriggers string = "NoRemediationTriggers"
+	// EventFlappingDetected is emitted in case remediation of a machine is
+	// suppressed because it has been remediated too often recently
+	EventFlappingDetected string = "FlappingDetected"
+	// EventMachineSetFlappingDetected is emitted in case remediation of a machine by deletion is
+	// suppressed because too many machines of its MachineSet have been deleted recently
+	EventMachineSetFlappingDetected string = "MachineSetFlappingDetected"
+	// EventMachineSetRemediationRestricted is emitted in case remediation of the machines
+	// of a MachineSet is restricted because the MachineSet exceeds its unhealthy budget
+	EventMachineSetRemediationRestricted string = "MachineSetRemediationRestricted"
+	// EventInvalidSelector is emitted in case the selector of a MachineHealthCheck
+	// cannot be parsed
+	EventInvalidSelector string = "InvalidSelector"
+	// EventStuckFinalizersRemoved is emitted when the finalizers of a machine
+	// stuck deleting are removed to let it finalize
+	EventStuckFinalizersRemoved string = "StuckFinalizersRemoved"
+	// EventMasterRemediationDeferred is emitted in case remediation of a master
+	// is deferred because another master is being remediated or cooling down
+	EventMasterRemediationDeferred string = "MasterRemediationDeferred"
+	// EventClusterRemediationSuspended is emitted in case remediation is suspended
+	// because too many nodes of the cluster are not ready
+	EventClusterRemediationSuspended string = "ClusterRemediationSuspended"
 )
 
+// remediationHeldError is returned when remediation of a target is held by a
+// pre-terminate hook and should be retried later
+type remediationHeldError struct {
+	target string
+	hook   string
+}
+
+func (e *remediationHeldError) Error() string {
+	return fmt.Sprintf("%s: remediation held by pre-terminate hook %q", e.target, e.hook)
+}
+
+// permanentError is returned when reconciling a MachineHealthCheck fails in a
+// way which retrying cannot fix, e.g. due to an invalid spec
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// errInvalidSelector is wrapped by the error returned when the selector of a
+// MachineHealthCheck cannot be parsed
+var errInvalidSelector = errors.New("invalid selector")
+
+// isTransientError returns true if the error is expected to resolve itself on retry
+func isTransientError(err error) bool {
+	return apimachineryerrors.IsConflict(err) ||
+		apimachineryerrors.IsServerTimeout(err) ||
+		apimachineryerrors.IsTimeout(err) ||
+		apimachineryerrors.IsTooManyRequests(err) ||
+		apimachineryerrors.IsServiceUnavailable(err)
+}
+
+// resultForError returns the reconcile result for an error. Permanent errors are not
+// requeued as retrying cannot fix them, a change to the MachineHealthCheck triggers a
+// new reconcile instead. All other errors are requeued with the workqueue backoff.
+func resultForError(request reconcile.Request, err error) (reconcile.Result, error) {
+	var permErr *permanentError
+	if errors.As(err, &permErr) {
+		klog.Errorf("Reconciling %s: permanent error, not requeuing: %v", request.String(), err)
+		return reconcile.Result{}, nil
+	}
+	if isTransientError(err) {
+		klog.V(3).Infof("Reconciling %s: transient error, requeuing with backoff: %v", request.String(), err)
+	}
+	return reconcile.Result{}, err
+}
+
+// Options contains optional configuration of the MachineHealthCheck Controller
+type Options struct {
+	// AuditWebhookURL is the URL a JSON record of every remediation is posted to.
+	// Auditing is disabled when empty.
+	AuditWebhookURL string
+
+	// NodeGracePeriod is the duration after the creation of a node during which
+	// its conditions are not evaluated. The grace period is disabled when zero.
+	NodeGracePeriod time.Duration
+
+	// CordonedNotReadyTimeout is the duration after which a node which is both cordoned and
+	// not ready is unhealthy, regardless of the MHC unhealthy condition timeouts. Such a node
+	// is almost certainly dead, so this is meant to be shorter than the condition timeouts.
+	// It is disabled when zero.
+	CordonedNotReadyTimeout time.Duration
+
+	// NodeNotFoundGracePeriod is the duration a node referenced by a machine may be missing
+	// before the machine is remediated, measured from the first reconcile which found it missing.
+	// Machines whose node is missing are remediated immediately when zero.
+	NodeNotFoundGracePeriod time.Duration
+
+	// NodeLeaseStaleTimeout is the duration after which a node whose lease has not been renewed
+	// is unhealthy, regardless of its conditions. The kubelet renews its lease more often than it
+	// reports its status, so a dead kubelet is detected before the Ready condition flips.
+	// It is disabled when zero.
+	NodeLeaseStaleTimeout time.Duration
+
+	// FailedMachineTimeout is the duration a machine must have been in the Failed phase before it
+	// is remediated, measured from the last update of the machine status. Transient provider errors
+	// sometimes fail machines briefly before they recover. Failed machines are remediated
+	// immediately when zero.
+	FailedMachineTimeout time.Duration
+
+	// ProtectedRoles contains machine roles which are never remediated, e.g. "infra".
+	// The role of a machine is read from its machine role label.
+	ProtectedRoles []string
+
+	// RemediableOwnerKinds contains the controller kinds machines must be owned by to be
+	// remediated, e.g. "MachineSet" or the kind of a custom machine pool. Machines owned by
+	// another kind are skipped like machines without a controller owner, as nothing may replace
+	// them. Machines owned by any controller are remediated when empty.
+	RemediableOwnerKinds []string
+
+	// MasterLabels contains label selectors identifying master nodes and machines, e.g.
+	// "node-role.kubernetes.io/master". A target is a master if any of them matches its node
+	// or its machine. The legacy and current node role labels and the machine master role
+	// label are used when empty.
+	MasterLabels []string
+
+	// DeletePropagationPolicy is the propagation policy used when deleting unhealthy machines,
+	// one of "Foreground", "Background" or "Orphan". The API server default is used when empty.
+	DeletePropagationPolicy string
+
+	// SoleMachineSetMemberPolicy is applied when remediation would delete the only machine of a
+	// single replica MachineSet, leaving it without capacity until a replacement is ready. One of
+	// "Delete", "ScaleFirst", which scales the MachineSet up by one and deletes the machine once
+	// the replacement is ready, or "Refuse". The machine is deleted as usual when empty.
+	SoleMachineSetMemberPolicy string
+
+	// FlappingThreshold is the number of remediations of a machine within FlappingWindow
+	// after which further remediations of the machine are suppressed. Flapping detection
+	// is disabled when zero.
+	FlappingThreshold int
+
+	// MachineSetFlappingThreshold is the number of machines of a MachineSet deleted by remediation
+	// within FlappingWindow after which further deletions of the machines of the MachineSet are
+	// suppressed. Unlike FlappingThreshold, it accounts for failures of the replacement machines
+	// of a MachineSet, which have new names. It is disabled when zero.
+	MachineSetFlappingThreshold int
+
+	// FlappingWindow is the sliding window remediations are counted over for flapping detection.
+	FlappingWindow time.Duration
+
+	// EscalationThreshold is the number of remediations of a machine within EscalationWindow after
+	// which further remediations of the machine are escalated, signalled with a Warning event and a
+	// dedicated metric rather than treated as routine. Escalation is disabled when zero.
+	EscalationThreshold int
+
+	// EscalationWindow is the sliding window remediations are counted over for escalation.
+	EscalationWindow time.Duration
+
+	// MachineSetMaxUnhealthy is the number or percentage of the machines of a single MachineSet
+	// which may need remediation before remediation of the machines of that MachineSet is
+	// restricted, e.g. "1" or "40%". Per MachineSet budgets are disabled when empty.
+	MachineSetMaxUnhealthy string
+
+	// ClusterMaxNotReady is the number or percentage of all nodes of the cluster which may be
+	// not ready before all remediation is suspended, e.g. "50%". So many nodes going not ready
+	// at once points to a control plane or network wide problem rather than to unhealthy machines.
+	// The cluster wide circuit breaker is disabled when empty.
+	ClusterMaxNotReady string
+
+	// MaxRemediationsPerZone is the number of machines of a single availability zone
+	// remediated per reconcile, remaining machines of the zone are remediated on requeue.
+	// Per zone budgets are disabled when zero.
+	MaxRemediationsPerZone int
+
+	// StuckFinalizers contains the finalizers which may be removed from machines stuck deleting.
+	// Removal must also be enabled per MachineHealthCheck with the
+	// machine.openshift.io/remove-stuck-finalizers-after annotation. No finalizer is removed when empty.
+	StuckFinalizers []string
+
+	// MasterRemediationCooldown is the duration after the remediation of a master during which
+	// no other master is remediated. At most one master is remediated per reconcile regardless.
+	MasterRemediationCooldown time.Duration
+
+	// RemediationIdempotencyWindow is the duration after the remediation of a machine during which
+	// it is not remediated again, even by a restarted or concurrent controller. Remediations are
+	// recorded on the machine with the machine.openshift.io/remediated-at annotation.
+	// The window is disabled when zero.
+	RemediationIdempotencyWindow time.Duration
+
+	// MaxUnhealthyDeferral is the cumulative duration a machine with an unhealthy node condition
+	// may be deferred within the condition timeouts before it is remediated regardless, so that a
+	// node oscillating just under the timeouts is eventually remediated. The first deferral is
+	// recorded on the machine with the machine.openshift.io/first-unhealthy-at annotation.
+	// Deferral is not limited when zero.
+	MaxUnhealthyDeferral time.Duration
+
+	// CreateDefaultWorkerMHC enables ensuring a default MachineHealthCheck of worker machines
+	// exists. It is created when absent and left alone once modified by a user.
+	CreateDefaultWorkerMHC bool
+
+	// StatusUpdateInterval is the minimum duration between status writes of a MachineHealthCheck.
+	// Status changes within the interval are written once at its end. Status writes which would
+	// not change the status are always skipped. Coalescing is disabled when zero.
+	StatusUpdateInterval time.Duration
+
+	// MaxConcurrentRemediations is the number of targets of a MachineHealthCheck remediated
+	// concurrently per reconcile, within its remediation budgets. Targets are remediated one
+	// at a time when zero or one.
+	MaxConcurrentRemediations int
+
+	// MinConditionTimeout is the shortest unhealthy condition timeout not warned about. Shorter
+	// timeouts may expire between two regular node status updates, so that healthy nodes are
+	// remediated. The warning is disabled when zero.
+	MinConditionTimeout time.Duration
+}
+
 // Add creates a new MachineHealthCheck Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and start it when the Manager is started.
 func Add(mgr manager.Manager, opts manager.Options) error {
-	r, err := newReconciler(mgr, opts)
+	return AddWithOptions(mgr, opts, Options{})
+}
+
+// AddWithOptions creates a new MachineHealthCheck Controller configured with mhcOpts and adds it to the Manager.
+func AddWithOptions(mgr manager.Manager, opts manager.Options, mhcOpts Options) error {
+	r, err := newReconciler(mgr, opts, mhcOpts)
 	if err != nil {
 		return fmt.Errorf("error building reconciler: %v", err)
 	}
-	return add(mgr, r, r.mhcRequestsFromMachine, r.mhcRequestsFromNode)
+	if mhcOpts.CreateDefaultWorkerMHC {
+		if err := addDefaultMHCController(mgr, opts.Namespace); err != nil {
+			return fmt.Errorf("error adding default MHC controller: %v", err)
+		}
+	}
+	resync := newLeaderResync(mgr.GetClient(), opts.Namespace)
+	if err := mgr.Add(resync); err != nil {
+		return fmt.Errorf("error adding leader resync: %v", err)
+	}
+	if r.auditWebhook != nil {
+		if err := mgr.Add(r.auditWebhook); err != nil {
+			return fmt.Errorf("error adding audit webhook: %v", err)
+		}
+	}
+	return add(mgr, r, r.mhcRequestsFromMachine, r.mhcRequestsFromNode, r.nodeRetrier.events, resync.events)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager, opts manager.Options) (*ReconcileMachineHealthCheck, error) {
+func newReconciler(mgr manager.Manager, opts manager.Options, mhcOpts Options) (*ReconcileMachineHealthCheck, error) {
 	if err := mgr.GetCache().IndexField(context.TODO(),
 		&mapiv1.Machine{},
 		machineNodeNameIndex,
@@ -87,13 +348,85 @@ func newReconciler(mgr manager.Manager, opts manager.Options) (*ReconcileMachine
 	); err != nil {
 		return nil, fmt.Errorf("error setting index fields: %v", err)
 	}
+	if err := mgr.GetCache().IndexField(context.TODO(),
+		&corev1.Node{},
+		nodeMachineAnnotationIndex,
+		indexNodeByMachineAnnotation,
+	); err != nil {
+		return nil, fmt.Errorf("error setting index fields: %v", err)
+	}
+
+	deletePropagationPolicy, err := parseDeletePropagationPolicy(mhcOpts.DeletePropagationPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	soleMemberPolicy, err := parseSoleMemberPolicy(mhcOpts.SoleMachineSetMemberPolicy)
+	if err != nil {
+		return nil, err
+	}
 
-	return &ReconcileMachineHealthCheck{
-		client:    mgr.GetClient(),
-		scheme:    mgr.GetScheme(),
-		namespace: opts.Namespace,
-		recorder:  mgr.GetEventRecorderFor(controllerName),
-	}, nil
+	machineSetMaxUnhealthy, err := parseMachineSetMaxUnhealthy(mhcOpts.MachineSetMaxUnhealthy)
+	if err != nil {
+		return nil, err
+	}
+	clusterMaxNotReady, err := parseClusterMaxNotReady(mhcOpts.ClusterMaxNotReady)
+	if err != nil {
+		return nil, err
+	}
+	masterLabels, err := parseMasterLabels(mhcOpts.MasterLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ReconcileMachineHealthCheck{
+		client:          mgr.GetClient(),
+		apiReader:       mgr.GetAPIReader(),
+		scheme:          mgr.GetScheme(),
+		namespace:       opts.Namespace,
+		recorder:        mgr.GetEventRecorderFor(controllerName),
+		machinesCache:   newMachinesCache(),
+		conditionsCache: newUnhealthyConditionsCache(),
+		cacheSynced:     mgr.GetCache().WaitForCacheSync,
+		nodeGracePeriod: mhcOpts.NodeGracePeriod,
+		protectedRoles:  mhcOpts.ProtectedRoles,
+		masterLabels:    masterLabels,
+
+		cordonedNotReadyTimeout: mhcOpts.CordonedNotReadyTimeout,
+		nodeNotFoundGracePeriod: mhcOpts.NodeNotFoundGracePeriod,
+		nodeLeaseStaleTimeout:   mhcOpts.NodeLeaseStaleTimeout,
+		failedMachineTimeout:    mhcOpts.FailedMachineTimeout,
+
+		deletePropagationPolicy: deletePropagationPolicy,
+		soleMemberPolicy:        soleMemberPolicy,
+		machineSetMaxUnhealthy:  machineSetMaxUnhealthy,
+		clusterMaxNotReady:      clusterMaxNotReady,
+		maxRemediationsPerZone:  mhcOpts.MaxRemediationsPerZone,
+		stuckFinalizers:         mhcOpts.StuckFinalizers,
+
+		remediationIdempotencyWindow: mhcOpts.RemediationIdempotencyWindow,
+		maxUnhealthyDeferral:         mhcOpts.MaxUnhealthyDeferral,
+		minConditionTimeout:          mhcOpts.MinConditionTimeout,
+		maxConcurrentRemediations:    mhcOpts.MaxConcurrentRemediations,
+		remediableOwnerKinds:         mhcOpts.RemediableOwnerKinds,
+		nodeRetrier:                  newNodeRetrier(nodeRetryDelay, nodeRetryAttempts),
+		remediationStrategies:        defaultRemediationStrategies(),
+	}
+	r.masterGuard.cooldown = mhcOpts.MasterRemediationCooldown
+	r.statusWrites.interval = mhcOpts.StatusUpdateInterval
+	if mhcOpts.AuditWebhookURL != "" {
+		r.auditWebhook = newAuditWebhook(mhcOpts.AuditWebhookURL)
+	}
+	if mhcOpts.FlappingThreshold > 0 {
+		r.remediationTracker = newRemediationTracker(mhcOpts.FlappingThreshold, mhcOpts.FlappingWindow)
+	}
+	if mhcOpts.MachineSetFlappingThreshold > 0 {
+		r.machineSetFlappingTracker = newRemediationTracker(mhcOpts.MachineSetFlappingThreshold, mhcOpts.FlappingWindow)
+	}
+	if mhcOpts.EscalationThreshold > 0 {
+		r.escalationTracker = newRemediationTracker(mhcOpts.EscalationThreshold, mhcOpts.EscalationWindow)
+	}
+	return r, nil
 }
 
 func indexMachineByNodeName(object client.Object) []string {
@@ -111,7 +444,7 @@ func indexMachineByNodeName(object client.Object) []string {
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler, mapMachineToMHC, mapNodeToMHC handler.MapFunc) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, mapMachineToMHC, mapNodeToMHC handler.MapFunc, nodeRetries, mhcResyncs <-chan event.GenericEvent) error {
 	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
 	if err != nil {
 		return err
@@ -127,7 +460,17 @@ func add(mgr manager.Manager, r reconcile.Reconciler, mapMachineToMHC, mapNodeTo
 		return err
 	}
 
-	return c.Watch(&source.Kind{Type: &corev1.Node{}}, handler.EnqueueRequestsFromMapFunc(mapNodeToMHC))
+	err = c.Watch(&source.Kind{Type: &corev1.Node{}}, handler.EnqueueRequestsFromMapFunc(mapNodeToMHC))
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Channel{Source: nodeRetries}, handler.EnqueueRequestsFromMapFunc(mapNodeToMHC))
+	if err != nil {
+		return err
+	}
+
+	return c.Watch(&source.Channel{Source: mhcResyncs}, &handler.EnqueueRequestForObject{})
 }
 
 var _ reconcile.Reconciler = &ReconcileMachineHealthCheck{}
@@ -136,64 +479,333 @@ var _ reconcile.Reconciler = &ReconcileMachineHealthCheck{}
 type ReconcileMachineHealthCheck struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client    client.Client
+	client client.Client
+	// apiReader reads objects from the apiserver, it is used for objects outside of the
+	// namespace the cache of the manager is restricted to, e.g. node leases
+	apiReader client.Reader
 	scheme    *runtime.Scheme
 	namespace string
 	recorder  record.EventRecorder
+	// machinesCache caches the machines matched by MHC selectors,
+	// caching is disabled when nil
+	machinesCache *machinesCache
+	// conditionsCache caches the unhealthy conditions of MHCs,
+	// caching is disabled when nil
+	conditionsCache *unhealthyConditionsCache
+	// masterLabels contains label selectors identifying master nodes and machines,
+	// defaultMasterLabels are used when nil
+	masterLabels []string
+	// auditWebhook receives a record of every remediation, auditing is disabled when nil
+	auditWebhook *auditWebhook
+	// nodeGracePeriod is the duration after node creation during which node
+	// conditions are not evaluated
+	nodeGracePeriod time.Duration
+	// cordonedNotReadyTimeout is the duration after which a cordoned node which is
+	// not ready is unhealthy, it is disabled when zero
+	cordonedNotReadyTimeout time.Duration
+	// nodeNotFoundGracePeriod is the duration a node referenced by a machine may be
+	// missing before the machine is remediated, it is disabled when zero
+	nodeNotFoundGracePeriod time.Duration
+	// nodeLeaseStaleTimeout is the duration after which a node whose lease has not been
+	// renewed is unhealthy, it is disabled when zero
+	nodeLeaseStaleTimeout time.Duration
+	// failedMachineTimeout is the duration a machine must have been failed before
+	// it is remediated, failed machines are remediated immediately when zero
+	failedMachineTimeout time.Duration
+	// cacheSynced reports whether the caches read by the reconciler are synced,
+	// the caches are assumed synced when nil
+	cacheSynced cacheSyncCheck
+	// missingNodes records when the missing nodes of targets were first found missing
+	missingNodes missingNodeTracker
+	// conditionTransitions records the condition transition times observed for each node
+	conditionTransitions conditionTransitionTracker
+	// nodeReachable confirms that the node of an unhealthy machine is unreachable before
+	// remediation for MHCs opting in, the node lease is checked when nil
+	nodeReachable nodeReachabilityCheck
+	// protectedRoles contains machine roles which are skipped by remediation
+	protectedRoles []string
+	// remediableOwnerKinds contains the controller kinds machines must be owned by to be
+	// remediated, machines owned by any controller are remediated when empty
+	remediableOwnerKinds []string
+	// deletePropagationPolicy is the propagation policy used when deleting machines,
+	// the API server default is used when nil
+	deletePropagationPolicy *metav1.DeletionPropagation
+	// soleMemberPolicy is applied before deleting the only machine of a single replica
+	// MachineSet, the machine is deleted as usual when empty
+	soleMemberPolicy string
+	// clock is used to evaluate the health of targets, remediation windows and flapping,
+	// the real clock is used when nil
+	clock clock.PassiveClock
+	// remediationTracker detects flapping machines, flapping detection is disabled when nil
+	remediationTracker *remediationTracker
+	// machineSetFlappingTracker detects MachineSets whose machines keep being deleted,
+	// MachineSet flapping detection is disabled when nil
+	machineSetFlappingTracker *remediationTracker
+	// remediationStrategies holds the strategies MHCs may select to remediate their targets,
+	// the built-in strategies are used when nil
+	remediationStrategies *remediationStrategyRegistry
+	// escalationTracker classifies repeated remediations of nodes as escalated,
+	// escalation is disabled when nil
+	escalationTracker *remediationTracker
+	// machineSetMaxUnhealthy is the number or percentage of the machines of a MachineSet
+	// which may need remediation, per MachineSet budgets are disabled when nil
+	machineSetMaxUnhealthy *intstr.IntOrString
+	// clusterMaxNotReady is the number or percentage of the nodes of the cluster which may be
+	// not ready before all remediation is suspended, the circuit breaker is disabled when nil
+	clusterMaxNotReady *intstr.IntOrString
+	// maxRemediationsPerZone is the number of machines of an availability zone
+	// remediated per reconcile, per zone budgets are disabled when zero
+	maxRemediationsPerZone int
+	// stuckFinalizers contains the finalizers which may be removed from machines stuck deleting
+	stuckFinalizers []string
+	// masterGuard enforces the cool-down between master remediations
+	masterGuard masterRemediationGuard
+	// remediationIdempotencyWindow is the duration after the remediation of a machine
+	// during which it is not remediated again, the window is disabled when zero
+	remediationIdempotencyWindow time.Duration
+	// maxUnhealthyDeferral is the cumulative duration a target with an unhealthy node condition
+	// may be deferred before it is remediated regardless, deferral is not limited when zero
+	maxUnhealthyDeferral time.Duration
+	// minConditionTimeout is the shortest unhealthy condition timeout not warned about,
+	// the warning is disabled when zero
+	minConditionTimeout time.Duration
+	// maxConcurrentRemediations is the number of targets of an MHC remediated concurrently,
+	// targets are remediated one at a time when zero or one
+	maxConcurrentRemediations int
+	// remediationFailures counts the consecutive reconciles of each MHC failing to remediate
+	remediationFailures remediationFailureTracker
+	// remediationBatches accumulates the verdicts of MHCs in batch mode between interval boundaries
+	remediationBatches remediationBatchTracker
+	// statusWrites coalesces the status writes of each MHC
+	statusWrites statusWriteCoalescer
+	// nodeRetrier maps nodes to MHCs again when their machine cannot be resolved yet,
+	// retries are disabled when nil
+	nodeRetrier *nodeRetrier
+}
+
+// now returns the current time of the reconciler clock
+func (r *ReconcileMachineHealthCheck) now() time.Time {
+	if r.clock == nil {
+		return time.Now()
+	}
+	return r.clock.Now()
+}
+
+// parseDeletePropagationPolicy validates the given propagation policy,
+// an empty policy results in the API server default being used
+func parseDeletePropagationPolicy(policy string) (*metav1.DeletionPropagation, error) {
+	if policy == "" {
+		return nil, nil
+	}
+	propagationPolicy := metav1.DeletionPropagation(policy)
+	switch propagationPolicy {
+	case metav1.DeletePropagationForeground, metav1.DeletePropagationBackground, metav1.DeletePropagationOrphan:
+		return &propagationPolicy, nil
+	}
+	return nil, fmt.Errorf("invalid delete propagation policy %q, must be one of %q, %q or %q",
+		policy,
+		metav1.DeletePropagationForeground,
+		metav1.DeletePropagationBackground,
+		metav1.DeletePropagationOrphan,
+	)
+}
+
+// deleteOptions returns the options used when deleting machines
+func (r *ReconcileMachineHealthCheck) deleteOptions() []client.DeleteOption {
+	if r.deletePropagationPolicy == nil {
+		return nil
+	}
+	return []client.DeleteOption{client.PropagationPolicy(*r.deletePropagationPolicy)}
+}
+
+// defaultMasterLabels contains the legacy and the current node role labels
+// as well as the machine role label used to identify masters
+var defaultMasterLabels = []string{
+	nodeMasterLabel,
+	nodeControlPlaneLabel,
+	fmt.Sprintf("%s=%s", machineRoleLabel, machineMasterRole),
 }
 
 type target struct {
 	Machine mapiv1.Machine
 	Node    *corev1.Node
 	MHC     mapiv1.MachineHealthCheck
+
+	// NodeMissingSince is the time the node of the target was first found missing, it is
+	// set when fetching the target if the node not found grace period is enabled
+	NodeMissingSince *metav1.Time
+	// NodeLeaseRenewTime is the last renewal of the node lease of the target, it is set
+	// when fetching the target if the node lease stale timeout is enabled
+	NodeLeaseRenewTime *metav1.Time
+
+	// UnhealthyConditions are the unhealthy conditions of the MHC, they are set when
+	// fetching the target if the conditions cache is enabled
+	UnhealthyConditions []mapiv1.UnhealthyCondition
+
+	// UnhealthyCondition identifies the condition which made the target
+	// need remediation, it is set when health checking the target
+	UnhealthyCondition string
+
+	// SkipReason identifies why the remediation of the target is skipped or
+	// restricted, it is empty for regular targets and set when fetching the target
+	SkipReason string
+
+	// clock is used to evaluate the health of the target, it is set to the clock of
+	// the reconciler when fetching the target, the real clock is used when nil
+	clock clock.PassiveClock
 }
 
+const (
+	// skipReasonNoOwner is the skip reason of targets without a controller owner to replace them
+	skipReasonNoOwner = metrics.UnremediatableReasonNoOwner
+	// skipReasonProtected is the skip reason of targets with a protected role
+	skipReasonProtected = metrics.UnremediatableReasonProtected
+	// skipReasonMaster is the skip reason of masters, whose remediation is restricted to one at a time
+	skipReasonMaster = metrics.UnremediatableReasonMaster
+	// skipReasonInconsistent is the skip reason of targets whose node is annotated with another
+	// machine, they are health checked but never remediated
+	skipReasonInconsistent = metrics.UnremediatableReasonInconsistent
+)
+
 // Reconcile fetch all targets for a MachineHealthCheck request and does health checking for each of them
 func (r *ReconcileMachineHealthCheck) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	klog.Infof("Reconciling %s", request.String())
 
+	// defer reconciling until the caches are synced, as health checking against partial caches
+	// could report missing nodes or skip targets
+	if !r.cachesSynced(ctx) {
+		klog.Infof("Reconciling %s: caches not synced yet, requeuing in %v", request.String(), cacheSyncRequeue)
+		return reconcile.Result{RequeueAfter: cacheSyncRequeue}, nil
+	}
+
 	mhc := &mapiv1.MachineHealthCheck{}
 	if err := r.client.Get(context.TODO(), request.NamespacedName, mhc); err != nil {
 		if apimachineryerrors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
 			// In the event that this was a deletion, we need to remove the associated metric label
 			metrics.DeleteMachineHealthCheckNodesCovered(request.NamespacedName.Name, request.NamespacedName.Namespace)
+			metrics.DeleteMachineHealthCheckMatchedMachines(request.NamespacedName.Name, request.NamespacedName.Namespace)
+			metrics.ObserveMachineHealthCheckUnhealthyMachines(request.NamespacedName.Name, request.NamespacedName.Namespace, nil)
+			metrics.DeleteMachineHealthCheckNextCheck(request.NamespacedName.Name, request.NamespacedName.Namespace)
+			metrics.DeleteMachineHealthCheckTargetsEvaluated(request.NamespacedName.Name, request.NamespacedName.Namespace)
+			metrics.ObserveMachineHealthCheckMachinesHealth(request.NamespacedName.Name, request.NamespacedName.Namespace, nil)
+			metrics.DeleteMachineHealthCheckUnremediatableMachines(request.NamespacedName.Name, request.NamespacedName.Namespace)
+			metrics.DeleteMachineHealthCheckConfig(request.NamespacedName.Name, request.NamespacedName.Namespace)
+			metrics.DeleteMachineHealthCheckShortConditionTimeouts(request.NamespacedName.Name, request.NamespacedName.Namespace)
+			metrics.DeleteMachineHealthCheckOverlapping(request.NamespacedName.Name, request.NamespacedName.Namespace)
+			r.statusWrites.forget(request.NamespacedName.String())
+			r.remediationFailures.forget(request.NamespacedName.String())
+			r.remediationBatches.forget(request.NamespacedName.String())
+			if r.conditionsCache != nil {
+				r.conditionsCache.forget(request.NamespacedName.String())
+			}
 			return reconcile.Result{}, nil
 		}
 		klog.Errorf("Reconciling %s: failed to get MHC: %v", request.String(), err)
 		return reconcile.Result{}, err
 	}
 
+	if !mhc.GetDeletionTimestamp().IsZero() {
+		// The MHC is being deleted, do not remediate and remove the associated metric label
+		klog.Infof("Reconciling %s: MHC is being deleted, skipping", request.String())
+		metrics.DeleteMachineHealthCheckNodesCovered(mhc.Name, mhc.Namespace)
+		metrics.DeleteMachineHealthCheckMatchedMachines(mhc.Name, mhc.Namespace)
+		metrics.ObserveMachineHealthCheckUnhealthyMachines(mhc.Name, mhc.Namespace, nil)
+		metrics.DeleteMachineHealthCheckNextCheck(mhc.Name, mhc.Namespace)
+		metrics.DeleteMachineHealthCheckTargetsEvaluated(mhc.Name, mhc.Namespace)
+		metrics.ObserveMachineHealthCheckMachinesHealth(mhc.Name, mhc.Namespace, nil)
+		metrics.DeleteMachineHealthCheckUnremediatableMachines(mhc.Name, mhc.Namespace)
+		metrics.DeleteMachineHealthCheckConfig(mhc.Name, mhc.Namespace)
+		metrics.DeleteMachineHealthCheckShortConditionTimeouts(mhc.Name, mhc.Namespace)
+		metrics.DeleteMachineHealthCheckOverlapping(mhc.Name, mhc.Namespace)
+		return reconcile.Result{}, nil
+	}
+
+	metrics.ObserveMachineHealthCheckConfig(mhc.Name, mhc.Namespace, maxUnhealthyString(mhc), len(unhealthyConditions(mhc)), mhc.Spec.NodeStartupTimeout.Duration)
+	r.warnShortConditionTimeouts(mhc)
+	r.warnOverlappingMHCs(mhc)
+
+	if err := validateRemediationTriggers(mhc); err != nil {
+		r.recorder.Eventf(mhc, corev1.EventTypeWarning, EventNoRemediationTriggers, "%v", err)
+		return resultForError(request, err)
+	}
+	if err := r.validateRemediationStrategy(mhc); err != nil {
+		var permErr *permanentError
+		if errors.As(err, &permErr) {
+			r.recorder.Eventf(mhc, corev1.EventTypeWarning, EventStrategyUnsupportedOnPlatform, "%v", err)
+		}
+		return resultForError(request, err)
+	}
+
 	// Create a base from which the MHC status patch will be calculated
-	mergeBase := client.MergeFrom(mhc.DeepCopy())
+	base := mhc.DeepCopy()
 
-	// fetch all targets
-	klog.V(3).Infof("Reconciling %s: finding targets", request.String())
-	targets, err := r.getTargetsFromMHC(*mhc)
+	// resolve the selector before fetching targets, an invalid selector cannot match any
+	var targets []target
+	selector, err := effectiveSelector(mhc)
+	mhc.Status.EffectiveSelector = selector
+	if err == nil {
+		// fetch all targets
+		klog.V(3).Infof("Reconciling %s: finding targets", request.String())
+		targets, err = r.getTargetsFromMHC(*mhc)
+	}
 	if err != nil {
-		return reconcile.Result{}, err
+		if errors.Is(err, errInvalidSelector) {
+			// the MHC is requeued by its watch once its spec is fixed
+			r.recorder.Eventf(mhc, corev1.EventTypeWarning, EventInvalidSelector, "%v", err)
+			conditions.Set(mhc, conditions.FalseCondition(
+				mapiv1.RemediationAllowedCondition,
+				mapiv1.InvalidSelectorReason,
+				mapiv1.ConditionSeverityError,
+				"%v",
+				err,
+			))
+			statusRequeue, statusErr := r.reconcileStatus(base, mhc)
+			if statusErr != nil {
+				klog.Errorf("Reconciling %s: error patching status: %v", request.String(), statusErr)
+				return reconcile.Result{}, statusErr
+			}
+			if statusRequeue > 0 {
+				return reconcile.Result{RequeueAfter: statusRequeue}, nil
+			}
+		}
+		return resultForError(request, err)
 	}
 	totalTargets := len(targets)
 
 	metrics.ObserveMachineHealthCheckNodesCovered(mhc.Name, mhc.Namespace, totalTargets)
+	metrics.ObserveMachineHealthCheckUnremediatableMachines(mhc.Name, mhc.Namespace, unremediatableCounts(targets))
 
 	// health check all targets and reconcile mhc status
-	currentHealthy, needRemediationTargets, nextCheckTimes, errList := r.healthCheckTargets(targets, mhc.Spec.NodeStartupTimeout.Duration)
+	evaluationStart := time.Now()
+	healthyTargets, needRemediationTargets, nextCheckTimes, errList := r.healthCheckTargets(targets, mhc.Spec.NodeStartupTimeout.Duration)
+	metrics.ObserveMachineHealthCheckTargetsEvaluated(mhc.Name, mhc.Namespace, totalTargets, time.Since(evaluationStart).Seconds())
+	currentHealthy := len(healthyTargets)
 	mhc.Status.CurrentHealthy = &currentHealthy
-	mhc.Status.ExpectedMachines = &totalTargets
-	unhealthyCount := totalTargets - currentHealthy
+	// machines already being deleted are not expected to be healthy
+	expectedMachines := expectedTargetCount(targets)
+	mhc.Status.ExpectedMachines = &expectedMachines
+	mhc.Status.Targets = targetStatuses(mhc, targets, healthyTargets, needRemediationTargets)
+
+	unhealthyMachines := map[string]string{}
+	for _, t := range needRemediationTargets {
+		unhealthyMachines[t.Machine.Name] = t.UnhealthyCondition
+	}
+	metrics.ObserveMachineHealthCheckUnhealthyMachines(mhc.Name, mhc.Namespace, unhealthyMachines)
+	metrics.ObserveMachineHealthCheckMachinesHealth(mhc.Name, mhc.Namespace, machinesHealth(targets, healthyTargets))
+	unhealthyCount := expectedMachines - currentHealthy
 
 	// check MHC current health against MaxUnhealthy
 	if !isAllowedRemediation(mhc) {
 		klog.Warningf("Reconciling %s: total targets: %v,  maxUnhealthy: %v, unhealthy: %v. Short-circuiting remediation",
 			request.String(),
-			totalTargets,
+			expectedMachines,
 			mhc.Spec.MaxUnhealthy,
-			totalTargets-currentHealthy,
+			expectedMachines-currentHealthy,
 		)
 
 		message := fmt.Sprintf("Remediation is not allowed, the number of not started or unhealthy machines exceeds maxUnhealthy (total: %v, unhealthy: %v, maxUnhealthy: %v)",
-			totalTargets,
+			expectedMachines,
 			unhealthyCount,
 			mhc.Spec.MaxUnhealthy,
 		)
@@ -207,8 +819,14 @@ func (r *ReconcileMachineHealthCheck) Reconcile(ctx context.Context, request rec
 			Reason:   mapiv1.TooManyUnhealthyReason,
 			Message:  message,
 		})
-
-		if err := r.reconcileStatus(mergeBase, mhc); err != nil {
+		conditions.Set(mhc, conditions.FalseCondition(
+			mapiv1.RemediationInProgressCondition,
+			mapiv1.TooManyUnhealthyReason,
+			mapiv1.ConditionSeverityWarning,
+			"Remediation is not allowed",
+		))
+
+		if _, err := r.reconcileStatus(base, mhc); err != nil {
 			klog.Errorf("Reconciling %s: error patching status: %v", request.String(), err)
 			return reconcile.Result{}, err
 		}
@@ -218,36 +836,171 @@ func (r *ReconcileMachineHealthCheck) Reconcile(ctx context.Context, request rec
 			corev1.EventTypeWarning,
 			EventRemediationRestricted,
 			"Remediation restricted due to exceeded number of unhealthy machines (total: %v, unhealthy: %v, maxUnhealthy: %v)",
-			totalTargets,
+			expectedMachines,
 			unhealthyCount,
 			mhc.Spec.MaxUnhealthy,
 		)
 		metrics.ObserveMachineHealthCheckShortCircuitEnabled(mhc.Name, mhc.Namespace)
+		metrics.ObserveMachineHealthCheckRemediationBlocked(mhc.Name, mhc.Namespace, metrics.RemediationBlockedReasonMaxUnhealthy, len(needRemediationTargets))
 		return reconcile.Result{Requeue: true}, nil
 	}
 	klog.V(3).Infof("Remediations are allowed for %s: total targets: %v,  max unhealthy: %v, unhealthy targets: %v",
 		request.String(),
-		totalTargets,
+		expectedMachines,
 		mhc.Spec.MaxUnhealthy,
 		unhealthyCount,
 	)
 	metrics.ObserveMachineHealthCheckShortCircuitDisabled(mhc.Name, mhc.Namespace)
 
+	// outside of the remediation window, requeue to the opening of the window
+	inWindow, untilWindow, err := inRemediationWindow(mhc.Spec.RemediationWindow, r.now())
+	if err != nil {
+		return resultForError(request, err)
+	}
+	if !inWindow && len(needRemediationTargets) > 0 {
+		klog.Infof("Reconciling %s: outside of the remediation window, postponing remediation of %d targets by %v",
+			request.String(),
+			len(needRemediationTargets),
+			untilWindow,
+		)
+		metrics.ObserveMachineHealthCheckRemediationBlocked(mhc.Name, mhc.Namespace, metrics.RemediationBlockedReasonWindow, len(needRemediationTargets))
+		nextCheckTimes = append(nextCheckTimes, untilWindow)
+		needRemediationTargets = nil
+	}
+
+	// in batch mode, hold the targets until the next interval boundary
+	batchDue, untilBatch, err := r.remediationBatchDue(mhc, needRemediationTargets)
+	if err != nil {
+		return resultForError(request, err)
+	}
+	if !batchDue && len(needRemediationTargets) > 0 {
+		klog.Infof("Reconciling %s: batch remediation, postponing remediation of %d targets by %v",
+			request.String(),
+			len(needRemediationTargets),
+			untilBatch,
+		)
+		metrics.ObserveMachineHealthCheckRemediationBlocked(mhc.Name, mhc.Namespace, metrics.RemediationBlockedReasonBatch, len(needRemediationTargets))
+		nextCheckTimes = append(nextCheckTimes, untilBatch)
+		needRemediationTargets = nil
+	}
+
+	// suspend all remediation during a cluster wide outage
+	suspended, outage, err := r.clusterRemediationSuspended()
+	if err != nil {
+		return resultForError(request, err)
+	}
+	if suspended && len(needRemediationTargets) > 0 {
+		klog.Warningf("Reconciling %s: too many nodes of the cluster are not ready (%s), suspending remediation of %d targets",
+			request.String(),
+			outage,
+			len(needRemediationTargets),
+		)
+		r.recorder.Eventf(
+			mhc,
+			corev1.EventTypeWarning,
+			EventClusterRemediationSuspended,
+			"Remediation suspended due to exceeded number of not ready nodes in the cluster (%s)",
+			outage,
+		)
+		nextCheckTimes = append(nextCheckTimes, clusterBreakerRequeue)
+		needRemediationTargets = nil
+	}
+
+	// skip targets whose node and machine reference each other inconsistently
+	needRemediationTargets = filterInconsistentTargets(needRemediationTargets)
+
+	// do not drain any single MachineSet beyond its budget
+	unfiltered := len(needRemediationTargets)
+	needRemediationTargets = r.filterByMachineSetBudget(mhc, targets, needRemediationTargets)
+	metrics.ObserveMachineHealthCheckRemediationBlocked(mhc.Name, mhc.Namespace, metrics.RemediationBlockedReasonMachineSetBudget, unfiltered-len(needRemediationTargets))
+
+	// requeue targets of zones which reached their budget for this pass
+	needRemediationTargets, deferredTargets := r.filterByZoneBudget(mhc, needRemediationTargets)
+	if len(deferredTargets) > 0 {
+		metrics.ObserveMachineHealthCheckRemediationBlocked(mhc.Name, mhc.Namespace, metrics.RemediationBlockedReasonZoneBudget, len(deferredTargets))
+		nextCheckTimes = append(nextCheckTimes, zoneBudgetRequeue)
+	}
+
+	// never remediate more than one master at a time
+	unfiltered = len(needRemediationTargets)
+	needRemediationTargets, masterRequeue := r.filterByMasterGuard(mhc, needRemediationTargets)
+	if masterRequeue > 0 {
+		metrics.ObserveMachineHealthCheckRemediationBlocked(mhc.Name, mhc.Namespace, metrics.RemediationBlockedReasonRateLimit, unfiltered-len(needRemediationTargets))
+		nextCheckTimes = append(nextCheckTimes, masterRequeue)
+	}
+
 	conditions.MarkTrue(mhc, mapiv1.RemediationAllowedCondition)
-	if err := r.reconcileStatus(mergeBase, mhc); err != nil {
+	setRemediationInProgressCondition(mhc, targets, needRemediationTargets)
+	statusRequeue, err := r.reconcileStatus(base, mhc)
+	if err != nil {
 		klog.Errorf("Reconciling %s: error patching status: %v", request.String(), err)
 		return reconcile.Result{}, err
 	}
+	if statusRequeue > 0 {
+		nextCheckTimes = append(nextCheckTimes, statusRequeue)
+	}
 
 	// remediate
-	for _, t := range needRemediationTargets {
-		klog.V(3).Infof("Reconciling %s: meet unhealthy criteria, triggers remediation", t.string())
-		if err := t.remediate(r); err != nil {
+	var failedRemediations, blockedRemediations int
+	remediationErrs := r.remediateTargets(needRemediationTargets)
+	for i, t := range needRemediationTargets {
+		if err := remediationErrs[i]; err != nil {
+			var heldErr *remediationHeldError
+			if errors.As(err, &heldErr) {
+				klog.Infof("Reconciling %s: %v, requeuing in %v", t.string(), err, remediationHeldRequeue)
+				nextCheckTimes = append(nextCheckTimes, remediationHeldRequeue)
+				continue
+			}
+			var pendingErr *finalizersPendingError
+			if errors.As(err, &pendingErr) {
+				klog.V(3).Infof("Reconciling %s: %v, requeuing", t.string(), err)
+				nextCheckTimes = append(nextCheckTimes, pendingErr.after)
+				continue
+			}
+			var remediatedErr *recentlyRemediatedError
+			if errors.As(err, &remediatedErr) {
+				klog.Infof("Reconciling %s: %v, requeuing", t.string(), err)
+				nextCheckTimes = append(nextCheckTimes, remediatedErr.after)
+				continue
+			}
+			var soleErr *soleMemberError
+			if errors.As(err, &soleErr) {
+				klog.Infof("Reconciling %s: %v, requeuing in %v", t.string(), err, soleMemberRequeue)
+				nextCheckTimes = append(nextCheckTimes, soleMemberRequeue)
+				continue
+			}
+			var blockedErr *controlPlaneDeletionBlockedError
+			if errors.As(err, &blockedErr) {
+				klog.Warningf("Reconciling %s: %v, skipping remediation", t.string(), err)
+				blockedRemediations++
+				continue
+			}
+			var reachableErr *nodeReachableError
+			if errors.As(err, &reachableErr) {
+				klog.Infof("Reconciling %s: %v, requeuing in %v", t.string(), err, nodeReachableRequeue)
+				nextCheckTimes = append(nextCheckTimes, nodeReachableRequeue)
+				continue
+			}
 			klog.Errorf("Reconciling %s: error remediating: %v", t.string(), err)
 			errList = append(errList, err)
+			failedRemediations++
 		}
 	}
 
+	metrics.ObserveMachineHealthCheckRemediationBlocked(mhc.Name, mhc.Namespace, metrics.RemediationBlockedReasonControlPlaneDeletion, blockedRemediations)
+
+	// report repeatedly failing remediation in the MHC status, the status was patched before remediating
+	base = mhc.DeepCopy()
+	r.setRemediationSucceedingCondition(mhc, failedRemediations, blockedRemediations)
+	statusRequeue, err = r.reconcileStatus(base, mhc)
+	if err != nil {
+		klog.Errorf("Reconciling %s: error patching status: %v", request.String(), err)
+		errList = append(errList, err)
+	}
+	if statusRequeue > 0 {
+		nextCheckTimes = append(nextCheckTimes, statusRequeue)
+	}
+
 	// return values
 	if len(errList) > 0 {
 		requeueError := apimachineryutilerrors.NewAggregate(errList)
@@ -255,7 +1008,9 @@ func (r *ReconcileMachineHealthCheck) Reconcile(ctx context.Context, request rec
 		return reconcile.Result{}, requeueError
 	}
 
-	if minNextCheck := minDuration(nextCheckTimes); minNextCheck > 0 {
+	minNextCheck := minDuration(nextCheckTimes)
+	metrics.ObserveMachineHealthCheckNextCheck(mhc.Name, mhc.Namespace, minNextCheck.Seconds())
+	if minNextCheck > 0 {
 		klog.V(3).Infof("Reconciling %s: some targets might go unhealthy. Ensuring a requeue happens in %v", request.String(), minNextCheck)
 		return reconcile.Result{RequeueAfter: minNextCheck}, nil
 	}
@@ -274,6 +1029,14 @@ func isAllowedRemediation(mhc *mapiv1.MachineHealthCheck) bool {
 	return unhealthyMachineCount(mhc) <= maxUnhealthy
 }
 
+// maxUnhealthyString returns maxUnhealthy as it is set in the MHC spec, or its default when unset
+func maxUnhealthyString(mhc *mapiv1.MachineHealthCheck) string {
+	if mhc.Spec.MaxUnhealthy == nil {
+		return "100%"
+	}
+	return mhc.Spec.MaxUnhealthy.String()
+}
+
 func getMaxUnhealthy(mhc *mapiv1.MachineHealthCheck) (int, error) {
 	if mhc.Spec.MaxUnhealthy == nil {
 		// This value should be defaulted, but if not, 100% is the default
@@ -292,6 +1055,21 @@ func getMaxUnhealthy(mhc *mapiv1.MachineHealthCheck) (int, error) {
 	return maxUnhealthy, nil
 }
 
+// remainingRemediationBudget returns how many more machines may become unhealthy before
+// remediation is short-circuited, ie the delta between maxUnhealthy and the number of presently
+// unhealthy or missing machines. It is never negative.
+func remainingRemediationBudget(mhc *mapiv1.MachineHealthCheck) (int, error) {
+	maxUnhealthy, err := getMaxUnhealthy(mhc)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get value for maxUnhealthy: %v", err)
+	}
+	remainingBudget := maxUnhealthy - unhealthyMachineCount(mhc)
+	if remainingBudget < 0 {
+		return 0, nil
+	}
+	return remainingBudget, nil
+}
+
 // unhealthyMachineCount calculates the number of presently unhealthy or missing machines
 // ie the delta between the expected number of machines and the current number deemed healthy
 func unhealthyMachineCount(mhc *mapiv1.MachineHealthCheck) int {
@@ -305,32 +1083,127 @@ func derefInt(i *int) int {
 	return 0
 }
 
-func (r *ReconcileMachineHealthCheck) reconcileStatus(baseToPatch client.Patch, mhc *mapiv1.MachineHealthCheck) error {
-	maxUnhealthy, err := getMaxUnhealthy(mhc)
+// unhealthyConditions returns the unhealthy conditions of the MHC, including the
+// Ready=False and Ready=Unknown conditions synthesized from its NodeReadyTimeout.
+// Explicit conditions take precedence over synthesized ones.
+func unhealthyConditions(mhc *mapiv1.MachineHealthCheck) []mapiv1.UnhealthyCondition {
+	if mhc.Spec.NodeReadyTimeout.Duration <= 0 {
+		return mhc.Spec.UnhealthyConditions
+	}
+
+	unhealthyConditions := append([]mapiv1.UnhealthyCondition{}, mhc.Spec.UnhealthyConditions...)
+	for _, status := range []corev1.ConditionStatus{corev1.ConditionFalse, corev1.ConditionUnknown} {
+		explicit := false
+		for _, c := range mhc.Spec.UnhealthyConditions {
+			if c.Type == corev1.NodeReady && c.Status == status {
+				explicit = true
+				break
+			}
+		}
+		if !explicit {
+			unhealthyConditions = append(unhealthyConditions, mapiv1.UnhealthyCondition{
+				Type:    corev1.NodeReady,
+				Status:  status,
+				Timeout: mhc.Spec.NodeReadyTimeout,
+			})
+		}
+	}
+	return unhealthyConditions
+}
+
+// validateRemediationTriggers returns a permanent error if the MHC can never consider
+// a machine unhealthy, i.e. it has no unhealthy conditions, no unhealthy machine phases
+// and no node startup timeout
+func validateRemediationTriggers(mhc *mapiv1.MachineHealthCheck) error {
+	if len(unhealthyConditions(mhc)) > 0 {
+		return nil
+	}
+	// a nil list of phases defaults to the Failed phase
+	if mhc.Spec.UnhealthyMachinePhases == nil || len(mhc.Spec.UnhealthyMachinePhases) > 0 {
+		return nil
+	}
+	if mhc.Spec.NodeStartupTimeout.Duration > 0 {
+		return nil
+	}
+	return &permanentError{err: errors.New("unhealthyConditions is empty and none of nodeReadyTimeout, unhealthyMachinePhases or nodeStartupTimeout is set, no machine can ever be remediated")}
+}
+
+// setRemediationInProgressCondition sets the RemediationInProgress condition of the MHC,
+// listing the machines of the targets about to be remediated and of the targets whose
+// remediation is still in progress
+func setRemediationInProgressCondition(mhc *mapiv1.MachineHealthCheck, targets []target, needRemediationTargets []target) {
+	remediating := map[string]bool{}
+	for _, t := range needRemediationTargets {
+		remediating[t.Machine.Name] = true
+	}
+	for _, t := range targets {
+		if t.isRemediating() {
+			remediating[t.Machine.Name] = true
+		}
+	}
+
+	if len(remediating) == 0 {
+		conditions.Set(mhc, conditions.FalseCondition(
+			mapiv1.RemediationInProgressCondition,
+			mapiv1.NoUnhealthyMachinesReason,
+			mapiv1.ConditionSeverityInfo,
+			"No machines are being remediated",
+		))
+		return
+	}
+
+	machines := make([]string, 0, len(remediating))
+	for machine := range remediating {
+		machines = append(machines, machine)
+	}
+	sort.Strings(machines)
+	conditions.Set(mhc, &mapiv1.Condition{
+		Type:    mapiv1.RemediationInProgressCondition,
+		Status:  corev1.ConditionTrue,
+		Message: fmt.Sprintf("Remediating machines: %s", strings.Join(machines, ", ")),
+	})
+}
+
+// reconcileStatus patches the status of the MHC from the given base. The write is skipped when the
+// status did not change. When the status of the MHC was written within the status update interval,
+// the write is deferred and the returned duration is the delay before the MHC should be requeued
+// to write it.
+func (r *ReconcileMachineHealthCheck) reconcileStatus(base, mhc *mapiv1.MachineHealthCheck) (time.Duration, error) {
+	remainingBudget, err := remainingRemediationBudget(mhc)
 	if err != nil {
-		return fmt.Errorf("failed to get value for maxUnhealthy: %v", err)
+		return 0, err
 	}
-	mhc.Status.RemediationsAllowed = int32(maxUnhealthy - unhealthyMachineCount(mhc))
-	if mhc.Status.RemediationsAllowed < 0 {
-		mhc.Status.RemediationsAllowed = 0
+	mhc.Status.RemediationsAllowed = int32(remainingBudget)
+
+	if equality.Semantic.DeepEqual(base.Status, mhc.Status) {
+		klog.V(4).Infof("%s: status unchanged, skipping status write", namespacedName(mhc))
+		return 0, nil
+	}
+	key := namespacedName(mhc).String()
+	if after := r.statusWrites.remaining(key, r.now()); after > 0 {
+		klog.V(3).Infof("%s: status written recently, deferring status write by %v", key, after)
+		return after, nil
 	}
 
-	if err := r.client.Status().Patch(context.Background(), mhc, baseToPatch); err != nil {
-		return err
+	if err := r.client.Status().Patch(context.Background(), mhc, client.MergeFrom(base)); err != nil {
+		return 0, err
 	}
-	return nil
+	r.statusWrites.record(key, r.now())
+	return 0, nil
 }
 
 // healthCheckTargets health checks a slice of targets
 // and gives a data to measure the average health
-func (r *ReconcileMachineHealthCheck) healthCheckTargets(targets []target, timeoutForMachineToHaveNode time.Duration) (int, []target, []time.Duration, []error) {
+func (r *ReconcileMachineHealthCheck) healthCheckTargets(targets []target, timeoutForMachineToHaveNode time.Duration) ([]target, []target, []time.Duration, []error) {
 	var nextCheckTimes []time.Duration
 	var errList []error
 	var needRemediationTargets []target
-	var currentHealthy int
+	var healthyTargets []target
+	timeouts := r.healthCheckTimeouts(timeoutForMachineToHaveNode)
 	for _, t := range targets {
 		klog.V(3).Infof("Reconciling %s: health checking", t.string())
-		needsRemediation, nextCheck, err := t.needsRemediation(timeoutForMachineToHaveNode)
+		r.observeConditionTransitions(&t)
+		needsRemediation, unhealthyCondition, nextCheck, err := t.needsRemediation(timeouts)
 		if err != nil {
 			klog.Errorf("Reconciling %s: error health checking: %v", t.string(), err)
 			errList = append(errList, err)
@@ -338,10 +1211,26 @@ func (r *ReconcileMachineHealthCheck) healthCheckTargets(targets []target, timeo
 		}
 
 		if needsRemediation {
+			t.UnhealthyCondition = unhealthyCondition
 			needRemediationTargets = append(needRemediationTargets, t)
 			continue
 		}
 
+		if nextCheck > 0 && r.maxUnhealthyDeferral > 0 && t.hasUnhealthyCondition() {
+			exceeded, deferralCheck, err := t.checkMaxDeferral(r)
+			if err != nil {
+				klog.Errorf("Reconciling %s: error checking deferral: %v", t.string(), err)
+				errList = append(errList, err)
+				continue
+			}
+			if exceeded {
+				t.UnhealthyCondition = unhealthyConditionMaxDeferralExceeded
+				needRemediationTargets = append(needRemediationTargets, t)
+				continue
+			}
+			nextCheck = minDuration([]time.Duration{nextCheck, deferralCheck})
+		}
+
 		if nextCheck > 0 {
 			klog.V(3).Infof("Reconciling %s: is likely to go unhealthy in %v", t.string(), nextCheck)
 			r.recorder.Eventf(
@@ -356,47 +1245,154 @@ func (r *ReconcileMachineHealthCheck) healthCheckTargets(targets []target, timeo
 			continue
 		}
 
-		if t.Machine.DeletionTimestamp == nil {
-			currentHealthy++
+		if r.maxUnhealthyDeferral > 0 {
+			if err := t.forgetMaxDeferral(r); err != nil {
+				klog.Errorf("Reconciling %s: error forgetting deferral: %v", t.string(), err)
+				errList = append(errList, err)
+			}
+		}
+
+		if err := t.clearGitOpsRemediation(r); err != nil {
+			klog.Errorf("Reconciling %s: error clearing GitOps remediation: %v", t.string(), err)
+			errList = append(errList, err)
+		}
+
+		// a target being remediated is not counted as healthy until remediation completes
+		if !t.isRemediating() {
+			healthyTargets = append(healthyTargets, t)
 		}
 	}
-	return currentHealthy, needRemediationTargets, nextCheckTimes, errList
+	return healthyTargets, needRemediationTargets, nextCheckTimes, errList
+}
+
+// machinesHealth maps the namespaced names of the machines of all targets to whether
+// the target is one of the healthy targets
+func machinesHealth(targets []target, healthyTargets []target) map[string]bool {
+	machines := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		machines[namespacedName(&t.Machine).String()] = false
+	}
+	for _, t := range healthyTargets {
+		machines[namespacedName(&t.Machine).String()] = true
+	}
+	return machines
 }
 
 func (r *ReconcileMachineHealthCheck) getTargetsFromMHC(mhc mapiv1.MachineHealthCheck) ([]target, error) {
 	machines, err := r.getMachinesFromMHC(mhc)
 	if err != nil {
-		return nil, fmt.Errorf("error getting machines from MHC: %v", err)
+		return nil, fmt.Errorf("error getting machines from MHC: %w", err)
 	}
+	metrics.ObserveMachineHealthCheckMatchedMachines(mhc.Name, mhc.Namespace, len(machines))
 	if len(machines) == 0 {
 		return nil, nil
 	}
 
+	var conditions []mapiv1.UnhealthyCondition
+	if r.conditionsCache != nil {
+		conditions = r.conditionsCache.get(&mhc)
+	}
+
 	var targets []target
 	for k := range machines {
 		target := target{
-			MHC:     mhc,
-			Machine: machines[k],
+			MHC:                 mhc,
+			Machine:             machines[k],
+			UnhealthyConditions: conditions,
+			clock:               r.clock,
 		}
+		var inconsistent bool
 		node, err := r.getNodeFromMachine(machines[k])
 		if err != nil {
 			if !apimachineryerrors.IsNotFound(err) {
-				return nil, fmt.Errorf("error getting node: %v", err)
+				return nil, fmt.Errorf("error getting node: %w", err)
 			}
 			// a node with only a name represents a
 			// not found node in the target
 			node.Name = machines[k].Status.NodeRef.Name
+		} else if node != nil {
+			consistent, err := r.hasConsistentNodeReference(machines[k], node)
+			if err != nil {
+				return nil, fmt.Errorf("error checking node reference: %v", err)
+			}
+			if !consistent {
+				klog.Warningf("%s/%s: node %q is annotated with machine %q, skipping remediation of inconsistent target",
+					machines[k].Namespace, machines[k].Name, node.Name, node.Annotations[machineAnnotationKey])
+				metrics.ObserveMachineHealthCheckInconsistentTarget(mhc.Name, mhc.Namespace)
+				inconsistent = true
+			}
+			duplicates, err := r.duplicateNodes(machines[k], node)
+			if err != nil {
+				return nil, fmt.Errorf("error checking duplicate nodes: %v", err)
+			}
+			if len(duplicates) > 0 {
+				klog.Warningf("%s/%s: nodes %v are also annotated with the machine, ignoring them in favor of node %q",
+					machines[k].Namespace, machines[k].Name, duplicates, node.Name)
+				metrics.ObserveMachineHealthCheckDuplicateNodeAnnotations(mhc.Name, mhc.Namespace, len(duplicates))
+			}
 		}
 		target.Node = node
+		if node != nil {
+			// the machine may have moved to a node registered under a new name
+			r.missingNodes.forgetPreviousNodes(&target)
+		}
+		if r.nodeNotFoundGracePeriod > 0 && node != nil {
+			if node.UID == "" {
+				since := metav1.NewTime(r.missingNodes.observe(target.string(), r.now()))
+				target.NodeMissingSince = &since
+			} else {
+				r.missingNodes.forget(target.string())
+			}
+		}
+		if r.nodeLeaseStaleTimeout > 0 && node != nil && node.UID != "" {
+			renewTime, err := r.getNodeLeaseRenewTime(node)
+			if err != nil {
+				return nil, fmt.Errorf("error getting node lease: %v", err)
+			}
+			if renewTime == nil {
+				// without a renewal the lease cannot tell whether the kubelet is dead
+				klog.Warningf("%s: node lease of node %q not found or never renewed, node lease stale timeout is not evaluated", target.string(), node.Name)
+			}
+			target.NodeLeaseRenewTime = renewTime
+		}
+		target.SkipReason = r.skipReason(&target)
+		if inconsistent {
+			target.SkipReason = skipReasonInconsistent
+		}
 		targets = append(targets, target)
 	}
+
+	// the machines are listed in no particular order, sort the targets so that
+	// logs, events and metrics derived from them are stable across reconciles
+	sort.SliceStable(targets, func(i, j int) bool {
+		return namespacedName(&targets[i].Machine).String() < namespacedName(&targets[j].Machine).String()
+	})
 	return targets, nil
 }
 
+// effectiveSelector returns the string form of the label selector of the MachineHealthCheck,
+// or a permanent error if the selector cannot be parsed
+func effectiveSelector(mhc *mapiv1.MachineHealthCheck) (string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&mhc.Spec.Selector)
+	if err != nil {
+		return "", &permanentError{err: fmt.Errorf("%w: %v", errInvalidSelector, err)}
+	}
+	return selector.String(), nil
+}
+
 func (r *ReconcileMachineHealthCheck) getMachinesFromMHC(mhc mapiv1.MachineHealthCheck) ([]mapiv1.Machine, error) {
 	selector, err := metav1.LabelSelectorAsSelector(&mhc.Spec.Selector)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build selector")
+		return nil, &permanentError{err: fmt.Errorf("%w: %v", errInvalidSelector, err)}
+	}
+
+	var generation uint64
+	if r.machinesCache != nil {
+		machines, cacheGeneration, ok := r.machinesCache.get(mhc.GetNamespace(), selector)
+		if ok {
+			return machines, nil
+		}
+		generation = cacheGeneration
 	}
 
 	options := client.ListOptions{
@@ -405,11 +1401,44 @@ func (r *ReconcileMachineHealthCheck) getMachinesFromMHC(mhc mapiv1.MachineHealt
 	}
 	machineList := &mapiv1.MachineList{}
 	if err := r.client.List(context.Background(), machineList, &options); err != nil {
-		return nil, fmt.Errorf("failed to list machines: %v", err)
+		return nil, fmt.Errorf("failed to list machines: %w", err)
+	}
+
+	if r.machinesCache != nil {
+		r.machinesCache.set(mhc.GetNamespace(), selector, machineList.Items, generation)
 	}
 	return machineList.Items, nil
 }
 
+// hasConsistentNodeReference checks that the machine annotation of the node,
+// when set, refers back to the machine referencing the node. Annotations
+// referring to machines which no longer exist are considered stale and ignored.
+func (r *ReconcileMachineHealthCheck) hasConsistentNodeReference(machine mapiv1.Machine, node *corev1.Node) (bool, error) {
+	annotation, ok := node.Annotations[machineAnnotationKey]
+	if !ok {
+		return true, nil
+	}
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(annotation)
+	if err != nil {
+		klog.Warningf("Node %q has invalid machine annotation %q: %v", node.Name, annotation, err)
+		metrics.ObserveMachineHealthCheckBadMachineAnnotation(node.Name)
+		return false, nil
+	}
+	if namespace == machine.Namespace && name == machine.Name {
+		return true, nil
+	}
+
+	annotatedMachine := &mapiv1.Machine{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: name}, annotatedMachine); err != nil {
+		if apimachineryerrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return false, nil
+}
+
 func (r *ReconcileMachineHealthCheck) getMachineFromNode(nodeName string) (*mapiv1.Machine, error) {
 	machineList := &mapiv1.MachineList{}
 	if err := r.client.List(
@@ -428,39 +1457,87 @@ func (r *ReconcileMachineHealthCheck) getMachineFromNode(nodeName string) (*mapi
 func (r *ReconcileMachineHealthCheck) mhcRequestsFromNode(o client.Object) []reconcile.Request {
 	klog.V(4).Infof("Getting MHC requests from node %q", namespacedName(o).String())
 	node := &corev1.Node{}
+	nodeFound := true
 	if err := r.client.Get(context.Background(), namespacedName(o), node); err != nil {
 		if apimachineryerrors.IsNotFound(err) {
 			node.Name = o.GetName()
+			nodeFound = false
+			// the targets of a deleted node, e.g. a node whose machine was remediated,
+			// may never be reconciled again to find the node missing
+			r.forgetConditionTransitions(node.Name)
 		} else {
 			klog.Errorf("No-op: Unable to retrieve node %q from store: %v", namespacedName(o).String(), err)
 			return nil
 		}
 	}
 
+	mhcs, err := r.mhcsForNode(node)
+	if err != nil {
+		// a node replaced by a node re-registered under a new name will never be resolved
+		if replacement, replacementErr := r.replacementNode(node); replacementErr != nil {
+			klog.Errorf("No-op: %v", replacementErr)
+		} else if nodeFound && replacement != "" {
+			klog.V(3).Infof("No-op: node %q of machine %s was replaced by node %q, ignoring stale node",
+				node.Name, node.Annotations[machineAnnotationKey], replacement)
+			if r.nodeRetrier != nil {
+				r.nodeRetrier.forget(node.Name)
+			}
+			return nil
+		}
+		klog.Errorf("No-op: %v", err)
+		// the node may have joined before the nodeRef of its machine was set
+		if r.nodeRetrier != nil {
+			if nodeFound {
+				r.nodeRetrier.retry(node)
+			} else {
+				r.nodeRetrier.forget(node.Name)
+			}
+		}
+		return nil
+	}
+	if r.nodeRetrier != nil {
+		r.nodeRetrier.forget(node.Name)
+	}
+
+	var requests []reconcile.Request
+	for k := range mhcs {
+		requests = append(requests, reconcile.Request{NamespacedName: namespacedName(&mhcs[k])})
+	}
+	return requests
+}
+
+// mhcsForNode returns all MHCs which selectors match the machine of the given node
+func (r *ReconcileMachineHealthCheck) mhcsForNode(node *corev1.Node) ([]mapiv1.MachineHealthCheck, error) {
 	machine, err := r.getMachineFromNode(node.Name)
 	if machine == nil || err != nil {
-		klog.Errorf("No-op: Unable to retrieve machine from node %q: %v", namespacedName(node).String(), err)
-		return nil
+		return nil, fmt.Errorf("unable to retrieve machine from node %q: %v", namespacedName(node).String(), err)
 	}
+	return r.mhcsForMachine(machine)
+}
 
+// mhcsForMachine returns all MHCs which selectors match the given machine
+func (r *ReconcileMachineHealthCheck) mhcsForMachine(machine *mapiv1.Machine) ([]mapiv1.MachineHealthCheck, error) {
 	mhcList := &mapiv1.MachineHealthCheckList{}
 	if err := r.client.List(context.Background(), mhcList); err != nil {
-		klog.Errorf("No-op: Unable to list mhc: %v", err)
-		return nil
+		return nil, fmt.Errorf("unable to list mhc: %v", err)
 	}
 
-	// get all MHCs which selectors match this machine
-	var requests []reconcile.Request
+	var mhcs []mapiv1.MachineHealthCheck
 	for k := range mhcList.Items {
 		if hasMatchingLabels(&mhcList.Items[k], machine) {
-			requests = append(requests, reconcile.Request{NamespacedName: namespacedName(&mhcList.Items[k])})
+			mhcs = append(mhcs, mhcList.Items[k])
 		}
 	}
-	return requests
+	return mhcs, nil
 }
 
 func (r *ReconcileMachineHealthCheck) mhcRequestsFromMachine(o client.Object) []reconcile.Request {
 	klog.V(4).Infof("Getting MHC requests from machine %q", namespacedName(o).String())
+	if r.machinesCache != nil {
+		// Any machine event may change the set of machines matched by a selector
+		r.machinesCache.invalidate(o.GetNamespace(), o.GetLabels())
+	}
+
 	machine := &mapiv1.Machine{}
 	if err := r.client.Get(context.Background(),
 		client.ObjectKey{
@@ -473,33 +1550,90 @@ func (r *ReconcileMachineHealthCheck) mhcRequestsFromMachine(o client.Object) []
 		return nil
 	}
 
-	mhcList := &mapiv1.MachineHealthCheckList{}
-	if err := r.client.List(context.Background(), mhcList); err != nil {
-		klog.Errorf("No-op: Unable to list mhc: %v", err)
+	mhcs, err := r.mhcsForMachine(machine)
+	if err != nil {
+		klog.Errorf("No-op: %v", err)
 		return nil
 	}
 
 	var requests []reconcile.Request
-	for k := range mhcList.Items {
-		if hasMatchingLabels(&mhcList.Items[k], machine) {
-			requests = append(requests, reconcile.Request{NamespacedName: namespacedName(&mhcList.Items[k])})
-		}
+	for k := range mhcs {
+		requests = append(requests, reconcile.Request{NamespacedName: namespacedName(&mhcs[k])})
 	}
 	return requests
 }
 
 func (t *target) remediate(r *ReconcileMachineHealthCheck) error {
 	klog.Infof(" %s: start remediation logic", t.string())
+	if t.isMaster(r.getMasterLabels()) {
+		klog.Infof("%s: remediating master machine", t.string())
+	}
 
-	if derefStringPointer(t.Machine.Status.Phase) != machinePhaseFailed {
-		if remediationStrategy, ok := t.MHC.Annotations[remediationStrategyAnnotation]; ok {
-			if mapiv1.RemediationStrategyType(remediationStrategy) == remediationStrategyExternal {
-				return t.remediationStrategyExternal(r)
-			}
+	if t.SkipReason == skipReasonProtected {
+		role, _ := t.hasProtectedRole(r.protectedRoles)
+		r.recorder.Eventf(
+			&t.Machine,
+			corev1.EventTypeNormal,
+			EventSkippedProtectedRole,
+			"Machine %v has protected role %q, skipping remediation",
+			t.string(),
+			role,
+		)
+		klog.Infof("%s: protected role %q, skipping remediation", t.string(), role)
+		return nil
+	}
+
+	// noop MHCs never act on their targets, whatever their machine phase
+	if t.isNoop() {
+		return t.remediationStrategyNoop(r)
+	}
+
+	// a machine already being deleted is not remediated again, only its stuck finalizers are escalated
+	if t.isDeleting() {
+		return t.removeStuckFinalizers(r, t.Machine.DeepCopy())
+	}
+
+	if err := t.confirmNodeUnreachable(r); err != nil {
+		return err
+	}
+
+	// failed machines are always deleted, other strategies cannot bring them back,
+	// except for GitOps managed machines which are recreated by their GitOps controller
+	strategyName := remediationStrategyDelete
+	if remediationStrategy, ok := t.MHC.Annotations[remediationStrategyAnnotation]; ok {
+		if derefStringPointer(t.Machine.Status.Phase) != machinePhaseFailed || remediationStrategy == string(remediationStrategyGitOps) {
+			strategyName = mapiv1.RemediationStrategyType(remediationStrategy)
 		}
 	}
+	strategy, err := r.strategies().lookup(strategyName)
+	if err != nil {
+		klog.Warningf("%s: %v, falling back to %s", t.string(), err, remediationStrategyDelete)
+		strategyName = remediationStrategyDelete
+		strategy = remediationStrategyFunc((*target).remediationStrategyDelete)
+	}
+	supported, err := t.platformSupportsStrategy(r, strategyName)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		strategyName = remediationStrategyDelete
+		strategy = remediationStrategyFunc((*target).remediationStrategyDelete)
+	}
+	// the remediation is recorded for flapping detection before the keys are released
+	defer t.lockFlapping(r, string(strategyName))()
+	if t.flappingDetected(r, string(strategyName)) {
+		return nil
+	}
+	if err := t.checkControlPlaneDeletion(r, strategyName); err != nil {
+		return err
+	}
+	return strategy.remediate(t, r)
+}
 
-	if !t.hasControllerOwner() {
+// remediationStrategyDelete remediates the target by deleting its machine,
+// which is replaced by the controller owning the machine
+func (t *target) remediationStrategyDelete(r *ReconcileMachineHealthCheck) error {
+	if t.SkipReason == skipReasonNoOwner {
 		r.recorder.Eventf(
 			&t.Machine,
 			corev1.EventTypeNormal,
@@ -522,12 +1656,39 @@ func (t *target) remediate(r *ReconcileMachineHealthCheck) error {
 	}
 
 	if !machine.GetDeletionTimestamp().IsZero() {
-		// Delete already initiated
-		return nil
+		// Delete already initiated, escalate if the machine is stuck deleting
+		return t.removeStuckFinalizers(r, machine)
+	}
+
+	if hook, ok := activePreTerminateHook(machine, r.now()); ok {
+		r.recorder.Eventf(
+			&t.Machine,
+			corev1.EventTypeNormal,
+			EventRemediationHeld,
+			"Machine %v remediation is held by pre-terminate hook %v",
+			t.string(),
+			hook,
+		)
+		return &remediationHeldError{target: t.string(), hook: hook}
+	}
+
+	if err := t.checkRecentlyRemediated(r, machine); err != nil {
+		return err
+	}
+	surgedMachineSet, err := t.prepareSoleMemberDeletion(r)
+	if err != nil {
+		return err
+	}
+	if err := t.markRemediated(r, machine); err != nil {
+		return err
 	}
 
 	klog.Infof("%s: deleting", t.string())
-	if err := r.client.Delete(context.TODO(), &t.Machine); err != nil {
+	if err := r.client.Delete(context.TODO(), &t.Machine, r.deleteOptions()...); err != nil {
+		if clearErr := t.clearRemediated(r, machine); clearErr != nil {
+			klog.Errorf("%v", clearErr)
+		}
+		t.audit(r, string(remediationStrategyDelete), err)
 		r.recorder.Eventf(
 			&t.Machine,
 			corev1.EventTypeWarning,
@@ -542,11 +1703,18 @@ func (t *target) remediate(r *ReconcileMachineHealthCheck) error {
 		&t.Machine,
 		corev1.EventTypeNormal,
 		EventMachineDeleted,
-		"Machine %v has been remediated by requesting to delete Machine object",
+		"Machine %v has been remediated by requesting to delete Machine object%s",
 		t.string(),
+		t.instanceDescription(),
 	)
 	metrics.ObserveMachineHealthCheckRemediationSuccess(t.MHC.Name, t.MHC.Namespace)
+	t.observeTimeToRemediate()
+	t.recordRemediation(r, string(remediationStrategyDelete))
+	t.audit(r, string(remediationStrategyDelete), nil)
 
+	if surgedMachineSet != nil {
+		return t.releaseSoleMemberSurge(r, surgedMachineSet)
+	}
 	return nil
 }
 
@@ -563,6 +1731,7 @@ func (t *target) remediationStrategyExternal(r *ReconcileMachineHealthCheck) err
 	klog.Infof("Machine %s has been unhealthy for too long, adding external annotation", t.Machine.Name)
 	t.Machine.Annotations[machineExternalAnnotationKey] = ""
 	if err := r.client.Update(context.TODO(), &t.Machine); err != nil {
+		t.audit(r, string(remediationStrategyExternal), err)
 		r.recorder.Eventf(
 			&t.Machine,
 			corev1.EventTypeWarning,
@@ -577,12 +1746,89 @@ func (t *target) remediationStrategyExternal(r *ReconcileMachineHealthCheck) err
 		&t.Machine,
 		corev1.EventTypeNormal,
 		EventExternalAnnotationAdded,
-		"Requesting external remediation of node associated with machine %v",
+		"Requesting external remediation of node associated with machine %v%s",
 		t.string(),
+		t.instanceDescription(),
 	)
+	t.observeTimeToRemediate()
+	t.recordRemediation(r, string(remediationStrategyExternal))
+	t.audit(r, string(remediationStrategyExternal), nil)
 	return nil
 }
 
+// recordRemediation records the remediation of the target for flapping detection, escalation
+// and the cool-down between master remediations, forgets its missing node and records the
+// remediation on the node. Failing to annotate the node does not fail the remediation.
+func (t *target) recordRemediation(r *ReconcileMachineHealthCheck, strategy string) {
+	if t.isMaster(r.getMasterLabels()) {
+		r.masterGuard.record(r.now())
+	}
+	r.missingNodes.forget(t.string())
+	t.recordFlapping(r, strategy)
+	t.escalateRemediation(r)
+	if err := t.annotateNodeRemediation(r, strategy); err != nil {
+		klog.Errorf("%v", err)
+	}
+}
+
+// observeTimeToRemediate records the delay between the target exceeding an
+// unhealthy condition timeout and the remediation, along with how long the
+// node condition which triggered the remediation had been unhealthy
+func (t *target) observeTimeToRemediate() {
+	condition, unhealthySince := t.trippingCondition()
+	if condition == nil {
+		return
+	}
+	now := t.now()
+	metrics.ObserveMachineHealthCheckTimeToRemediate(t.MHC.Name, t.MHC.Namespace, now.Sub(unhealthySince).Seconds())
+	metrics.ObserveMachineHealthCheckUnhealthyDurationAtRemediation(t.MHC.Name, t.MHC.Namespace, now.Sub(condition.LastTransitionTime.Time).Seconds())
+}
+
+// activePreTerminateHook returns the name of the first pre-terminate hook
+// annotation of the machine which has not expired. A hook expires once the
+// RFC3339 timestamp in its value has passed, hooks without a valid timestamp
+// never expire.
+func activePreTerminateHook(machine *mapiv1.Machine, now time.Time) (string, bool) {
+	var hooks []string
+	for key := range machine.Annotations {
+		if strings.HasPrefix(key, preTerminateHookAnnotationPrefix+"/") {
+			hooks = append(hooks, key)
+		}
+	}
+	sort.Strings(hooks)
+
+	for _, hook := range hooks {
+		expiry, err := time.Parse(time.RFC3339, machine.Annotations[hook])
+		if err == nil && expiry.Before(now) {
+			klog.V(3).Infof("%s/%s: ignoring expired pre-terminate hook %q", machine.Namespace, machine.Name, hook)
+			continue
+		}
+		return hook, true
+	}
+	return "", false
+}
+
+// parseMasterLabels validates the given master label selectors,
+// nil is returned when empty so that defaultMasterLabels are used
+func parseMasterLabels(masterLabels []string) ([]string, error) {
+	if len(masterLabels) == 0 {
+		return nil, nil
+	}
+	for _, masterLabel := range masterLabels {
+		if _, err := labels.Parse(masterLabel); err != nil {
+			return nil, fmt.Errorf("invalid master label %q: %v", masterLabel, err)
+		}
+	}
+	return masterLabels, nil
+}
+
+func (r *ReconcileMachineHealthCheck) getMasterLabels() []string {
+	if r.masterLabels == nil {
+		return defaultMasterLabels
+	}
+	return r.masterLabels
+}
+
 func (r *ReconcileMachineHealthCheck) getNodeFromMachine(machine mapiv1.Machine) (*corev1.Node, error) {
 	if machine.Status.NodeRef == nil {
 		return nil, nil
@@ -606,6 +1852,14 @@ func (t *target) string() string {
 	)
 }
 
+// now returns the current time according to the clock of the target
+func (t *target) now() time.Time {
+	if t.clock == nil {
+		return time.Now()
+	}
+	return t.clock.Now()
+}
+
 func (t *target) nodeName() string {
 	if t.Node != nil {
 		return t.Node.GetName()
@@ -613,39 +1867,141 @@ func (t *target) nodeName() string {
 	return ""
 }
 
-func (t *target) needsRemediation(timeoutForMachineToHaveNode time.Duration) (bool, time.Duration, error) {
-	var nextCheckTimes []time.Duration
-	now := time.Now()
+// healthCheckTimeouts contains the timeouts targets are health checked with,
+// each of them is disabled when zero unless stated otherwise
+type healthCheckTimeouts struct {
+	// nodeStartupTimeout is the duration a machine may take to get a node, it is read from the MHC
+	nodeStartupTimeout time.Duration
+	// nodeGracePeriod is the duration after node creation during which node conditions are not evaluated
+	nodeGracePeriod time.Duration
+	// cordonedNotReadyTimeout is the duration after which a cordoned node which is not ready is unhealthy
+	cordonedNotReadyTimeout time.Duration
+	// nodeNotFoundGracePeriod is the duration a node referenced by a machine may be missing
+	nodeNotFoundGracePeriod time.Duration
+	// nodeLeaseStaleTimeout is the duration after which a node whose lease was not renewed is unhealthy
+	nodeLeaseStaleTimeout time.Duration
+	// failedMachineTimeout is the duration a machine must have been failed before it is remediated
+	failedMachineTimeout time.Duration
+}
+
+// healthCheckTimeouts returns the timeouts targets are health checked with, nodeStartupTimeout
+// is the node startup timeout of the MHC of the targets
+func (r *ReconcileMachineHealthCheck) healthCheckTimeouts(nodeStartupTimeout time.Duration) healthCheckTimeouts {
+	return healthCheckTimeouts{
+		nodeStartupTimeout:      nodeStartupTimeout,
+		nodeGracePeriod:         r.nodeGracePeriod,
+		cordonedNotReadyTimeout: r.cordonedNotReadyTimeout,
+		nodeNotFoundGracePeriod: r.nodeNotFoundGracePeriod,
+		nodeLeaseStaleTimeout:   r.nodeLeaseStaleTimeout,
+		failedMachineTimeout:    r.failedMachineTimeout,
+	}
+}
 
-	// machine has failed
-	if derefStringPointer(t.Machine.Status.Phase) == machinePhaseFailed {
-		klog.V(3).Infof("%s: unhealthy: machine phase is %q", t.string(), machinePhaseFailed)
-		return true, time.Duration(0), nil
+// needsRemediation evaluates the health of the target. It returns whether the
+// target needs remediation along with the unhealthy condition which triggered
+// it, or the duration after which the target should be checked again.
+func (t *target) needsRemediation(timeouts healthCheckTimeouts) (bool, string, time.Duration, error) {
+	var nextCheckTimes []time.Duration
+	now := t.now()
+
+	// an external agent vouching for the node overrides any unhealthy verdict, the node
+	// watch requeues the MHC once the veto condition changes
+	if t.vetoed() {
+		klog.V(3).Infof("%s: healthy: node reports veto condition %s=True", t.string(), t.MHC.Spec.VetoCondition)
+		return false, "", time.Duration(0), nil
+	}
+
+	// machine is in a phase considered unhealthy, unless its node wins according to the MHC policy
+	if t.machinePhaseUnhealthy() {
+		phase := derefStringPointer(t.Machine.Status.Phase)
+		// transient provider errors may fail a machine briefly, the failed machine timeout
+		// is measured from the last update of the machine status by the machine controller
+		if phase == machinePhaseFailed && timeouts.failedMachineTimeout > 0 && t.Machine.Status.LastUpdated != nil {
+			if durationFailed := elapsedSince(t.Machine.Status.LastUpdated.Time, now); durationFailed <= timeouts.failedMachineTimeout {
+				klog.V(3).Infof("%s: machine failed for %v, within timeout of %v", t.string(), durationFailed, timeouts.failedMachineTimeout)
+				return false, "", timeouts.failedMachineTimeout - durationFailed + time.Second, nil
+			}
+		}
+		klog.V(3).Infof("%s: unhealthy: machine phase is %q", t.string(), phase)
+		return true, unhealthyConditionMachinePhasePrefix + phase, time.Duration(0), nil
 	}
 
-	// the node has not been set yet
+	// the node has not been set yet, the node startup timeout is measured from
+	// the last update of the machine status by the machine controller, or from
+	// the creation of the machine if its status was not updated yet
 	if t.Node == nil {
-		// status not updated yet
-		if t.Machine.Status.LastUpdated == nil {
-			return false, timeoutForMachineToHaveNode, nil
+		startedAt := t.Machine.CreationTimestamp
+		if t.Machine.Status.LastUpdated != nil {
+			startedAt = *t.Machine.Status.LastUpdated
+		}
+		// neither created nor updated, e.g. a machine not persisted yet
+		if startedAt.IsZero() {
+			return false, "", timeouts.nodeStartupTimeout, nil
 		}
-		if t.Machine.Status.LastUpdated.Add(timeoutForMachineToHaveNode).Before(now) {
-			klog.V(3).Infof("%s: unhealthy: machine has no node after %v", t.string(), timeoutForMachineToHaveNode)
-			return true, time.Duration(0), nil
+		durationUnhealthy := elapsedSince(startedAt.Time, now)
+		if durationUnhealthy > timeouts.nodeStartupTimeout {
+			klog.V(3).Infof("%s: unhealthy: machine has no node after %v", t.string(), timeouts.nodeStartupTimeout)
+			return true, unhealthyConditionNodeStartupTimeout, time.Duration(0), nil
 		}
-		durationUnhealthy := now.Sub(t.Machine.Status.LastUpdated.Time)
-		nextCheck := timeoutForMachineToHaveNode - durationUnhealthy + time.Second
-		return false, nextCheck, nil
+		nextCheck := timeouts.nodeStartupTimeout - durationUnhealthy + time.Second
+		return false, "", nextCheck, nil
 	}
 
-	// the node does not exist
+	// the node does not exist, it may have been deleted out of band
 	if t.Node != nil && t.Node.UID == "" {
-		return true, time.Duration(0), nil
+		if timeouts.nodeNotFoundGracePeriod > 0 && t.NodeMissingSince != nil {
+			if durationMissing := elapsedSince(t.NodeMissingSince.Time, now); durationMissing <= timeouts.nodeNotFoundGracePeriod {
+				klog.V(3).Infof("%s: node missing for %v, within grace period of %v", t.string(), durationMissing, timeouts.nodeNotFoundGracePeriod)
+				return false, "", timeouts.nodeNotFoundGracePeriod - durationMissing + time.Second, nil
+			}
+		}
+		return true, unhealthyConditionNodeNotFound, time.Duration(0), nil
+	}
+
+	// the node has been created recently, its conditions may not be up to date yet
+	if nodeAge := now.Sub(t.Node.CreationTimestamp.Time); nodeAge < timeouts.nodeGracePeriod {
+		klog.V(3).Infof("%s: node was created %v ago, within grace period of %v", t.string(), nodeAge, timeouts.nodeGracePeriod)
+		return false, "", timeouts.nodeGracePeriod - nodeAge, nil
+	}
+
+	// the kubelet renews the node lease more often than it reports node conditions,
+	// a stale lease detects a dead kubelet before the ready condition flips
+	if timeouts.nodeLeaseStaleTimeout > 0 && t.NodeLeaseRenewTime != nil {
+		sinceRenewal := elapsedSince(t.NodeLeaseRenewTime.Time, now)
+		if sinceRenewal > timeouts.nodeLeaseStaleTimeout {
+			klog.V(3).Infof("%s: unhealthy: node lease not renewed for %v", t.string(), sinceRenewal)
+			return true, unhealthyConditionNodeLeaseStale, time.Duration(0), nil
+		}
+		nextCheckTimes = append(nextCheckTimes, timeouts.nodeLeaseStaleTimeout-sinceRenewal+time.Second)
+	}
+
+	// a cordoned node which is not ready is almost certainly dead, the accelerated timeout
+	// is measured from the last transition time of the node ready condition
+	if readyCondition, ok := t.cordonedNotReady(timeouts.cordonedNotReadyTimeout); ok {
+		durationUnhealthy := elapsedSince(readyCondition.LastTransitionTime.Time, now)
+		if durationUnhealthy > timeouts.cordonedNotReadyTimeout {
+			klog.V(3).Infof("%s: unhealthy: node cordoned and not ready longer than %v", t.string(), timeouts.cordonedNotReadyTimeout)
+			return true, unhealthyConditionCordonedNotReady, time.Duration(0), nil
+		}
+		nextCheckTimes = append(nextCheckTimes, timeouts.cordonedNotReadyTimeout-durationUnhealthy+time.Second)
+	}
+
+	// with AND semantics, the conditions are only met once all of them are
+	if t.MHC.Spec.UnhealthyConditionsMode == mapiv1.UnhealthyConditionsModeAll {
+		met, condition, nextCheck := t.allUnhealthyConditionsMet(now)
+		if met {
+			klog.V(3).Infof("%s: unhealthy: conditions %s all met longer than their timeouts", t.string(), condition)
+			return true, condition, time.Duration(0), nil
+		}
+		if nextCheck > 0 {
+			nextCheckTimes = append(nextCheckTimes, nextCheck)
+		}
+		return false, "", minDuration(nextCheckTimes), nil
 	}
 
-	// check conditions
-	for _, c := range t.MHC.Spec.UnhealthyConditions {
-		now := time.Now()
+	// check conditions, condition timeouts are measured from the last transition
+	// time of the node condition reported by the kubelet
+	for _, c := range t.unhealthyConditions() {
 		nodeCondition := conditions.GetNodeCondition(t.Node, c.Type)
 
 		// Skip when current node condition is different from the one reported
@@ -654,26 +2010,262 @@ func (t *target) needsRemediation(timeoutForMachineToHaveNode time.Duration) (bo
 			continue
 		}
 
+		durationUnhealthy := elapsedSince(nodeCondition.LastTransitionTime.Time, now)
+		remaining := c.Timeout.Duration - durationUnhealthy
+		if remaining < 0 {
+			remaining = time.Duration(0)
+		}
+		klog.V(2).InfoS("Evaluated unhealthy condition",
+			"target", t.string(),
+			"type", c.Type,
+			"status", nodeCondition.Status,
+			"timeout", c.Timeout.Duration.String(),
+			"remaining", remaining.String(),
+		)
+
 		// If the condition has been in the unhealthy state for longer than the
 		// timeout, return true with no requeue time.
-		if nodeCondition.LastTransitionTime.Add(c.Timeout.Duration).Before(now) {
+		if durationUnhealthy > c.Timeout.Duration {
 			klog.V(3).Infof("%s: unhealthy: condition %v in state %v longer than %v", t.string(), c.Type, c.Status, c.Timeout)
-			return true, time.Duration(0), nil
+			return true, fmt.Sprintf("%s=%s", c.Type, c.Status), time.Duration(0), nil
 		}
 
-		durationUnhealthy := now.Sub(nodeCondition.LastTransitionTime.Time)
 		nextCheck := c.Timeout.Duration - durationUnhealthy + time.Second
 		if nextCheck > 0 {
 			nextCheckTimes = append(nextCheckTimes, nextCheck)
 		}
 	}
-	return false, minDuration(nextCheckTimes), nil
+	return false, "", minDuration(nextCheckTimes), nil
+}
+
+// cordonedNotReady returns the ready condition of the target node and true if the accelerated
+// timeout is enabled and the node is both unschedulable and not ready
+func (t *target) cordonedNotReady(cordonedNotReadyTimeout time.Duration) (*corev1.NodeCondition, bool) {
+	if cordonedNotReadyTimeout <= 0 || t.Node == nil || !t.Node.Spec.Unschedulable {
+		return nil, false
+	}
+	readyCondition := conditions.GetNodeCondition(t.Node, corev1.NodeReady)
+	if readyCondition == nil || readyCondition.Status == corev1.ConditionTrue {
+		return nil, false
+	}
+	return readyCondition, true
+}
+
+// elapsedSince returns the duration elapsed between the reference a timeout is measured from and now.
+// The references are set by other components, the kubelet for node conditions and the machine
+// controller for the machine status, so they may be ahead of the local clock due to clock skew.
+// A reference in the future is treated as now, so that the timeout is never considered expired
+// early nor extended by the skew.
+func elapsedSince(reference, now time.Time) time.Duration {
+	if reference.After(now) {
+		klog.V(3).Infof("Timeout reference %v is in the future, assuming clock skew", reference)
+		return 0
+	}
+	return now.Sub(reference)
+}
+
+// hasUnhealthyMachinePhase returns true if the machine phase is listed in the
+// MHC unhealthyMachinePhases. If the list is not set, only the Failed phase is
+// considered unhealthy.
+func (t *target) hasUnhealthyMachinePhase() bool {
+	phase := derefStringPointer(t.Machine.Status.Phase)
+	if phase == "" {
+		return false
+	}
+
+	unhealthyPhases := t.MHC.Spec.UnhealthyMachinePhases
+	if unhealthyPhases == nil {
+		unhealthyPhases = []string{machinePhaseFailed}
+	}
+	for _, unhealthyPhase := range unhealthyPhases {
+		if phase == unhealthyPhase {
+			return true
+		}
+	}
+	return false
+}
+
+// isMaster returns true if either the node or the machine of the target
+// match any of the given master label selectors
+func (t *target) isMaster(masterLabels []string) bool {
+	for _, masterLabel := range masterLabels {
+		selector, err := labels.Parse(masterLabel)
+		if err != nil {
+			klog.Warningf("%s: unable to parse master label %q: %v", t.string(), masterLabel, err)
+			continue
+		}
+		if t.Node != nil && selector.Matches(labels.Set(t.Node.Labels)) {
+			return true
+		}
+		if selector.Matches(labels.Set(t.Machine.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasProtectedRole returns the role of the target machine and true if the
+// role is one of the given protected roles
+func (t *target) hasProtectedRole(protectedRoles []string) (string, bool) {
+	role, ok := t.Machine.Labels[machineRoleLabel]
+	if !ok {
+		return "", false
+	}
+	for _, protectedRole := range protectedRoles {
+		if role == protectedRole {
+			return role, true
+		}
+	}
+	return "", false
+}
+
+// unhealthyReason returns a human readable description of why the target is unhealthy
+func (t *target) unhealthyReason() string {
+	if t.machinePhaseUnhealthy() {
+		return fmt.Sprintf("machine phase is %q", derefStringPointer(t.Machine.Status.Phase))
+	}
+	if t.Node == nil {
+		return "machine has no node"
+	}
+	if t.Node.UID == "" {
+		return "node does not exist"
+	}
+	if t.UnhealthyCondition == unhealthyConditionCordonedNotReady {
+		return "node cordoned and not ready"
+	}
+	if t.UnhealthyCondition == unhealthyConditionMaxDeferralExceeded {
+		return "node unhealthy for longer than the maximum deferral"
+	}
+	if t.UnhealthyCondition == unhealthyConditionNodeLeaseStale {
+		return "node lease not renewed"
+	}
+	if t.MHC.Spec.UnhealthyConditionsMode == mapiv1.UnhealthyConditionsModeAll && t.UnhealthyCondition != "" {
+		return fmt.Sprintf("conditions %s all met longer than their timeouts", t.UnhealthyCondition)
+	}
+
+	now := t.now()
+	for _, c := range t.unhealthyConditions() {
+		nodeCondition := conditions.GetNodeCondition(t.Node, c.Type)
+		if nodeCondition == nil || nodeCondition.Status != c.Status {
+			continue
+		}
+		if elapsedSince(nodeCondition.LastTransitionTime.Time, now) > c.Timeout.Duration {
+			return fmt.Sprintf("condition %v in state %v longer than %v", c.Type, c.Status, c.Timeout.Duration)
+		}
+	}
+	return "unknown"
+}
+
+// unhealthySince returns the earliest time at which a node condition of the
+// target exceeded the timeout of the matching MHC unhealthy condition
+func (t *target) unhealthySince() (time.Time, bool) {
+	condition, since := t.trippingCondition()
+	return since, condition != nil
+}
+
+// trippingCondition returns the node condition of the target which exceeded the timeout of the
+// matching MHC unhealthy condition first, along with the time it exceeded it. A nil condition is
+// returned when no node condition exceeded its timeout.
+func (t *target) trippingCondition() (*corev1.NodeCondition, time.Time) {
+	if t.Node == nil {
+		return nil, time.Time{}
+	}
+
+	var tripping *corev1.NodeCondition
+	var since time.Time
+	now := t.now()
+	for _, c := range t.unhealthyConditions() {
+		nodeCondition := conditions.GetNodeCondition(t.Node, c.Type)
+		if nodeCondition == nil || nodeCondition.Status != c.Status {
+			continue
+		}
+
+		timedOut := nodeCondition.LastTransitionTime.Add(c.Timeout.Duration)
+		if timedOut.After(now) {
+			continue
+		}
+		if tripping == nil || timedOut.Before(since) {
+			tripping = nodeCondition
+			since = timedOut
+		}
+	}
+	return tripping, since
+}
+
+// isRemediating returns true if the target is currently being remediated, either because its
+// machine is being deleted or because external remediation of its machine was requested
+func (t *target) isRemediating() bool {
+	if t.isDeleting() {
+		return true
+	}
+	_, ok := t.Machine.Annotations[machineExternalAnnotationKey]
+	return ok
 }
 
 func (t *target) hasControllerOwner() bool {
 	return metav1.GetControllerOf(&t.Machine) != nil
 }
 
+// hasRemediableOwner returns true if the machine has a controller owner of one of the given
+// kinds, which is expected to replace the machine once remediated. Any controller owner is
+// accepted when no kind is given.
+func (t *target) hasRemediableOwner(ownerKinds []string) bool {
+	owner := metav1.GetControllerOf(&t.Machine)
+	if owner == nil {
+		return false
+	}
+	if len(ownerKinds) == 0 {
+		return true
+	}
+	for _, kind := range ownerKinds {
+		if owner.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// skipReason returns why the remediation of the target is skipped or restricted, or an empty
+// string if it is not. Reasons skipping remediation take precedence over masters, whose
+// remediation is only restricted.
+func (r *ReconcileMachineHealthCheck) skipReason(t *target) string {
+	if _, ok := t.hasProtectedRole(r.protectedRoles); ok {
+		return skipReasonProtected
+	}
+	if !t.hasRemediableOwner(r.remediableOwnerKinds) {
+		return skipReasonNoOwner
+	}
+	if t.isMaster(r.getMasterLabels()) {
+		return skipReasonMaster
+	}
+	return ""
+}
+
+// filterInconsistentTargets drops the targets whose node is annotated with another machine,
+// as the health of the node may not be the health of the machine of the target
+func filterInconsistentTargets(targets []target) []target {
+	var filtered []target
+	for _, t := range targets {
+		if t.SkipReason == skipReasonInconsistent {
+			klog.Infof("%s: node %q is annotated with another machine, skipping remediation", t.string(), t.nodeName())
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	return filtered
+}
+
+// unremediatableCounts counts the targets whose remediation is skipped or restricted, by reason
+func unremediatableCounts(targets []target) map[string]int {
+	counts := map[string]int{}
+	for _, t := range targets {
+		if t.SkipReason != "" {
+			counts[t.SkipReason]++
+		}
+	}
+	return counts
+}
+
 func derefStringPointer(stringPointer *string) string {
 	if stringPointer != nil {
 		return *stringPointer
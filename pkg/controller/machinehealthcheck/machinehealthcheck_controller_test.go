@@ -2,26 +2,36 @@ package machinehealthcheck
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
 	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
 	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/metrics"
 	"github.com/openshift/machine-api-operator/pkg/util/conditions"
 	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -39,6 +49,7 @@ var (
 func init() {
 	// Add types to scheme
 	mapiv1beta1.AddToScheme(scheme.Scheme)
+	configv1.AddToScheme(scheme.Scheme)
 }
 
 func TestHasMatchingLabels(t *testing.T) {
@@ -182,6 +193,7 @@ func newFakeReconcilerWithCustomRecorder(recorder record.EventRecorder, initObje
 	fakeClient := fake.NewFakeClient(initObjects...)
 	return &ReconcileMachineHealthCheck{
 		client:    fakeClient,
+		apiReader: fakeClient,
 		scheme:    scheme.Scheme,
 		namespace: namespace,
 		recorder:  recorder,
@@ -261,6 +273,20 @@ func TestReconcile(t *testing.T) {
 		Type:   mapiv1beta1.RemediationAllowedCondition,
 		Status: corev1.ConditionTrue,
 	}
+	remediationNotInProgressCondition := mapiv1beta1.Condition{
+		Type:     mapiv1beta1.RemediationInProgressCondition,
+		Status:   corev1.ConditionFalse,
+		Severity: mapiv1beta1.ConditionSeverityInfo,
+		Reason:   mapiv1beta1.NoUnhealthyMachinesReason,
+		Message:  "No machines are being remediated",
+	}
+	remediationInProgressCondition := func(machine string) mapiv1beta1.Condition {
+		return mapiv1beta1.Condition{
+			Type:    mapiv1beta1.RemediationInProgressCondition,
+			Status:  corev1.ConditionTrue,
+			Message: "Remediating machines: " + machine,
+		}
+	}
 
 	testCases := []struct {
 		testCase       string
@@ -287,6 +313,7 @@ func TestReconcile(t *testing.T) {
 				RemediationsAllowed: 0,
 				Conditions: mapiv1beta1.Conditions{
 					remediationAllowedCondition,
+					remediationInProgressCondition("machineUnhealthyForTooLong"),
 				},
 			},
 		},
@@ -306,6 +333,7 @@ func TestReconcile(t *testing.T) {
 				RemediationsAllowed: 1,
 				Conditions: mapiv1beta1.Conditions{
 					remediationAllowedCondition,
+					remediationNotInProgressCondition,
 				},
 			},
 		},
@@ -328,6 +356,7 @@ func TestReconcile(t *testing.T) {
 				RemediationsAllowed: 0,
 				Conditions: mapiv1beta1.Conditions{
 					remediationAllowedCondition,
+					remediationNotInProgressCondition,
 				},
 			},
 		},
@@ -347,6 +376,7 @@ func TestReconcile(t *testing.T) {
 				RemediationsAllowed: 0,
 				Conditions: mapiv1beta1.Conditions{
 					remediationAllowedCondition,
+					remediationNotInProgressCondition,
 				},
 			},
 		},
@@ -366,6 +396,7 @@ func TestReconcile(t *testing.T) {
 				RemediationsAllowed: 0,
 				Conditions: mapiv1beta1.Conditions{
 					remediationAllowedCondition,
+					remediationNotInProgressCondition,
 				},
 			},
 		},
@@ -385,6 +416,7 @@ func TestReconcile(t *testing.T) {
 				RemediationsAllowed: 0,
 				Conditions: mapiv1beta1.Conditions{
 					remediationAllowedCondition,
+					remediationInProgressCondition("machineWithoutOwnerController"),
 				},
 			},
 		},
@@ -406,6 +438,7 @@ func TestReconcile(t *testing.T) {
 				RemediationsAllowed: 0,
 				Conditions: mapiv1beta1.Conditions{
 					remediationAllowedCondition,
+					remediationNotInProgressCondition,
 				},
 			},
 		},
@@ -420,11 +453,12 @@ func TestReconcile(t *testing.T) {
 			},
 			expectedEvents: []string{},
 			expectedStatus: &mapiv1beta1.MachineHealthCheckStatus{
-				ExpectedMachines:    IntPtr(1),
+				ExpectedMachines:    IntPtr(0),
 				CurrentHealthy:      IntPtr(0),
 				RemediationsAllowed: 0,
 				Conditions: mapiv1beta1.Conditions{
 					remediationAllowedCondition,
+					remediationInProgressCondition("machineAlreadyDeleted"),
 				},
 			},
 		},
@@ -444,6 +478,7 @@ func TestReconcile(t *testing.T) {
 				RemediationsAllowed: 0,
 				Conditions: mapiv1beta1.Conditions{
 					remediationAllowedCondition,
+					remediationNotInProgressCondition,
 				},
 			},
 		},
@@ -471,6 +506,13 @@ func TestReconcile(t *testing.T) {
 						Reason:   mapiv1beta1.TooManyUnhealthyReason,
 						Message:  "Remediation is not allowed, the number of not started or unhealthy machines exceeds maxUnhealthy (total: 1, unhealthy: 1, maxUnhealthy: -1)",
 					},
+					{
+						Type:     mapiv1beta1.RemediationInProgressCondition,
+						Status:   corev1.ConditionFalse,
+						Severity: mapiv1beta1.ConditionSeverityWarning,
+						Reason:   mapiv1beta1.TooManyUnhealthyReason,
+						Message:  "Remediation is not allowed",
+					},
 				},
 			},
 		},
@@ -525,6 +567,448 @@ func TestReconcile(t *testing.T) {
 	}
 }
 
+func TestReconcileRemediationInProgressCondition(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("inProgress")
+	node := maotesting.NewNode("node", false)
+	// Without a controller owner the machine is not deleted and stays unhealthy
+	machine := maotesting.NewMachine("fakeMachine", node.Name)
+	machine.OwnerReferences = nil
+
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), mhc, node, machine)
+	request := reconcile.Request{NamespacedName: namespacedName(mhc)}
+
+	inProgress := func() *mapiv1beta1.Condition {
+		got := &mapiv1beta1.MachineHealthCheck{}
+		if err := r.client.Get(ctx, request.NamespacedName, got); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return conditions.Get(got, mapiv1beta1.RemediationInProgressCondition)
+	}
+
+	if _, err := r.Reconcile(ctx, request); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	condition := inProgress()
+	if condition == nil || condition.Status != corev1.ConditionTrue {
+		t.Fatalf("Expected RemediationInProgress to be True, got %+v", condition)
+	}
+	if condition.Message != "Remediating machines: fakeMachine" {
+		t.Errorf("Expected message to list the machine, got %q", condition.Message)
+	}
+
+	// Once the node recovers, the condition is cleared
+	node.Status.Conditions[0].Status = corev1.ConditionTrue
+	if err := r.client.Update(ctx, node); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, request); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	condition = inProgress()
+	if condition == nil || condition.Status != corev1.ConditionFalse || condition.Reason != mapiv1beta1.NoUnhealthyMachinesReason {
+		t.Errorf("Expected RemediationInProgress to be False, got %+v", condition)
+	}
+}
+
+func TestReconcileDeletingMHC(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("deleting")
+	now := metav1.Now()
+	mhc.DeletionTimestamp = &now
+	mhc.Finalizers = []string{"test"}
+	node := maotesting.NewNode("node", false)
+	machine := maotesting.NewMachine("machine", node.Name)
+
+	recorder := record.NewFakeRecorder(2)
+	r := newFakeReconcilerWithCustomRecorder(recorder, mhc, node, machine)
+	result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, reconcile.Result{}) {
+		t.Errorf("Expected empty result, got %+v", result)
+	}
+	assertEvents(t, "deleting MHC", []string{}, recorder.Events)
+
+	if err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{}); err != nil {
+		t.Errorf("Expected machine not to be remediated, got: %v", err)
+	}
+
+	got := &mapiv1beta1.MachineHealthCheck{}
+	if err := r.client.Get(ctx, namespacedName(mhc), got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.Status.ExpectedMachines != nil {
+		t.Errorf("Expected status not to be updated, got %+v", got.Status)
+	}
+}
+
+// listErrorClient fails all List calls with the given error
+type listErrorClient struct {
+	client.Client
+	err error
+}
+
+func (c *listErrorClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.err
+}
+
+func TestReconcileErrorBackoff(t *testing.T) {
+	invalidSelector := metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "foo", Operator: "Bogus", Values: []string{"bar"}},
+		},
+	}
+	machinesResource := schema.GroupResource{Group: "machine.openshift.io", Resource: "machines"}
+
+	testCases := []struct {
+		testCase      string
+		selector      *metav1.LabelSelector
+		listErr       error
+		expectedError bool
+	}{
+		{
+			testCase:      "invalid selector is not requeued",
+			selector:      &invalidSelector,
+			expectedError: false,
+		},
+		{
+			testCase:      "conflict is requeued with backoff",
+			listErr:       apierrors.NewConflict(machinesResource, "", errors.New("conflict")),
+			expectedError: true,
+		},
+		{
+			testCase:      "timeout is requeued with backoff",
+			listErr:       apierrors.NewServerTimeout(machinesResource, "list", 1),
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			if tc.selector != nil {
+				mhc.Spec.Selector = *tc.selector
+			}
+			r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(2), mhc)
+			if tc.listErr != nil {
+				r.client = &listErrorClient{Client: r.client, err: tc.listErr}
+			}
+
+			result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+			if (err != nil) != tc.expectedError {
+				t.Errorf("Expected error: %t, got: %v", tc.expectedError, err)
+			}
+			if tc.listErr != nil && !errors.Is(err, tc.listErr) {
+				t.Errorf("Expected error to wrap %v, got: %v", tc.listErr, err)
+			}
+			if !reflect.DeepEqual(result, reconcile.Result{}) {
+				t.Errorf("Expected empty result, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestReconcileInvalidSelector(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("invalidSelector")
+	mhc.Spec.Selector = metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "foo", Operator: "Bogus", Values: []string{"bar"}},
+		},
+	}
+	node := maotesting.NewNode("node", true)
+	machine := maotesting.NewMachine("machine", node.Name)
+
+	recorder := record.NewFakeRecorder(2)
+	r := newFakeReconcilerWithCustomRecorder(recorder, mhc, node, machine)
+	request := reconcile.Request{NamespacedName: namespacedName(mhc)}
+
+	// an invalid selector can only be fixed by editing the spec, so it must not be requeued
+	result, err := r.Reconcile(ctx, request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, reconcile.Result{}) {
+		t.Errorf("Expected empty result, got %+v", result)
+	}
+	assertEvents(t, "invalid selector", []string{EventInvalidSelector}, recorder.Events)
+
+	got := &mapiv1beta1.MachineHealthCheck{}
+	if err := r.client.Get(ctx, request.NamespacedName, got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	condition := conditions.Get(got, mapiv1beta1.RemediationAllowedCondition)
+	if condition == nil || condition.Status != corev1.ConditionFalse || condition.Reason != mapiv1beta1.InvalidSelectorReason {
+		t.Errorf("Expected RemediationAllowed to be False with reason %s, got: %+v", mapiv1beta1.InvalidSelectorReason, condition)
+	}
+
+	// fixing the spec triggers a watch-driven reconcile which evaluates the MHC again
+	got.Spec.Selector = metav1.LabelSelector{MatchLabels: maotesting.FooBar()}
+	if err := r.client.Update(ctx, got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, request); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertEvents(t, "fixed selector", []string{}, recorder.Events)
+
+	if err := r.client.Get(ctx, request.NamespacedName, got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if condition := conditions.Get(got, mapiv1beta1.RemediationAllowedCondition); condition == nil || condition.Status != corev1.ConditionTrue {
+		t.Errorf("Expected RemediationAllowed to be True once the selector is fixed, got: %+v", got.Status.Conditions)
+	}
+	if got.Status.ExpectedMachines == nil || *got.Status.ExpectedMachines != 1 {
+		t.Errorf("Expected the fixed selector to match 1 machine, got: %v", got.Status.ExpectedMachines)
+	}
+	if got.Status.EffectiveSelector != "foo=bar" {
+		t.Errorf("Expected effective selector %q, got %q", "foo=bar", got.Status.EffectiveSelector)
+	}
+}
+
+func TestEffectiveSelector(t *testing.T) {
+	testCases := []struct {
+		testCase         string
+		selector         metav1.LabelSelector
+		expectedSelector string
+		expectedError    bool
+	}{
+		{
+			testCase:         "empty selector",
+			selector:         metav1.LabelSelector{},
+			expectedSelector: "",
+		},
+		{
+			testCase: "match labels",
+			selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"foo": "bar", "baz": "qux"},
+			},
+			expectedSelector: "baz=qux,foo=bar",
+		},
+		{
+			testCase: "match expressions",
+			selector: metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "role", Operator: metav1.LabelSelectorOpIn, Values: []string{"worker", "infra"}},
+					{Key: "zone", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"a"}},
+					{Key: "gpu", Operator: metav1.LabelSelectorOpExists},
+					{Key: "spot", Operator: metav1.LabelSelectorOpDoesNotExist},
+				},
+			},
+			expectedSelector: "gpu,role in (infra,worker),!spot,zone notin (a)",
+		},
+		{
+			testCase: "match labels and expressions",
+			selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"foo": "bar"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "role", Operator: metav1.LabelSelectorOpIn, Values: []string{"worker"}},
+				},
+			},
+			expectedSelector: "foo=bar,role in (worker)",
+		},
+		{
+			testCase: "invalid operator",
+			selector: metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "foo", Operator: "Bogus", Values: []string{"bar"}},
+				},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			mhc.Spec.Selector = tc.selector
+			selector, err := effectiveSelector(mhc)
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("Expected error: %t, got: %v", tc.expectedError, err)
+			}
+			if tc.expectedError && !errors.Is(err, errInvalidSelector) {
+				t.Errorf("Expected an invalid selector error, got: %v", err)
+			}
+			if selector != tc.expectedSelector {
+				t.Errorf("Expected selector %q, got %q", tc.expectedSelector, selector)
+			}
+		})
+	}
+}
+
+func TestValidateRemediationTriggers(t *testing.T) {
+	testCases := []struct {
+		testCase      string
+		mutate        func(*mapiv1beta1.MachineHealthCheck)
+		expectedError bool
+	}{
+		{
+			testCase:      "unhealthy conditions",
+			mutate:        func(*mapiv1beta1.MachineHealthCheck) {},
+			expectedError: false,
+		},
+		{
+			testCase: "empty conditions with default phases",
+			mutate: func(mhc *mapiv1beta1.MachineHealthCheck) {
+				mhc.Spec.UnhealthyConditions = nil
+			},
+			expectedError: false,
+		},
+		{
+			testCase: "empty conditions with node startup timeout",
+			mutate: func(mhc *mapiv1beta1.MachineHealthCheck) {
+				mhc.Spec.UnhealthyConditions = nil
+				mhc.Spec.UnhealthyMachinePhases = []string{}
+				mhc.Spec.NodeStartupTimeout = metav1.Duration{Duration: 10 * time.Minute}
+			},
+			expectedError: false,
+		},
+		{
+			testCase: "empty conditions with node ready timeout",
+			mutate: func(mhc *mapiv1beta1.MachineHealthCheck) {
+				mhc.Spec.UnhealthyConditions = nil
+				mhc.Spec.UnhealthyMachinePhases = []string{}
+				mhc.Spec.NodeReadyTimeout = metav1.Duration{Duration: 5 * time.Minute}
+			},
+			expectedError: false,
+		},
+		{
+			testCase: "no triggers",
+			mutate: func(mhc *mapiv1beta1.MachineHealthCheck) {
+				mhc.Spec.UnhealthyConditions = []mapiv1beta1.UnhealthyCondition{}
+				mhc.Spec.UnhealthyMachinePhases = []string{}
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			tc.mutate(mhc)
+			err := validateRemediationTriggers(mhc)
+			if (err != nil) != tc.expectedError {
+				t.Errorf("Expected error: %t, got: %v", tc.expectedError, err)
+			}
+			var permErr *permanentError
+			if err != nil && !errors.As(err, &permErr) {
+				t.Errorf("Expected a permanent error, got: %v", err)
+			}
+		})
+	}
+}
+
+// emptyPhasesClient returns MHCs with an empty list of unhealthy machine phases,
+// which the fake client cannot store as it drops empty lists
+type emptyPhasesClient struct {
+	client.Client
+}
+
+func (c *emptyPhasesClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if err := c.Client.Get(ctx, key, obj); err != nil {
+		return err
+	}
+	if mhc, ok := obj.(*mapiv1beta1.MachineHealthCheck); ok {
+		mhc.Spec.UnhealthyMachinePhases = []string{}
+	}
+	return nil
+}
+
+func TestReconcileNoRemediationTriggers(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("noTriggers")
+	mhc.Spec.UnhealthyConditions = []mapiv1beta1.UnhealthyCondition{}
+
+	recorder := record.NewFakeRecorder(2)
+	r := newFakeReconcilerWithCustomRecorder(recorder, mhc)
+	r.client = &emptyPhasesClient{Client: r.client}
+	result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+	if err != nil {
+		t.Errorf("Expected no requeue on invalid MHC, got error: %v", err)
+	}
+	if !reflect.DeepEqual(result, reconcile.Result{}) {
+		t.Errorf("Expected empty result, got %+v", result)
+	}
+	assertEvents(t, "no remediation triggers", []string{EventNoRemediationTriggers}, recorder.Events)
+}
+
+func TestIsTransientError(t *testing.T) {
+	resource := schema.GroupResource{Resource: "machines"}
+	testCases := []struct {
+		err      error
+		expected bool
+	}{
+		{err: apierrors.NewConflict(resource, "", errors.New("conflict")), expected: true},
+		{err: apierrors.NewServerTimeout(resource, "list", 1), expected: true},
+		{err: apierrors.NewTooManyRequests("slow down", 1), expected: true},
+		{err: apierrors.NewNotFound(resource, "machine"), expected: false},
+		{err: errors.New("generic"), expected: false},
+	}
+	for _, tc := range testCases {
+		if got := isTransientError(tc.err); got != tc.expected {
+			t.Errorf("Expected %t for %v, got %t", tc.expected, tc.err, got)
+		}
+	}
+}
+
+func TestIsRemediating(t *testing.T) {
+	machineExternalRemediation := maotesting.NewMachine("machineExternalRemediation", "node")
+	machineExternalRemediation.Annotations[machineExternalAnnotationKey] = ""
+
+	machineDeleting := maotesting.NewMachine("machineDeleting", "node")
+	now := metav1.Now()
+	machineDeleting.DeletionTimestamp = &now
+
+	testCases := []struct {
+		testCase string
+		machine  *mapiv1beta1.Machine
+		expected bool
+	}{
+		{
+			testCase: "external remediation annotation set",
+			machine:  machineExternalRemediation,
+			expected: true,
+		},
+		{
+			testCase: "machine deleting",
+			machine:  machineDeleting,
+			expected: true,
+		},
+		{
+			testCase: "neither",
+			machine:  maotesting.NewMachine("machine", "node"),
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			target := target{Machine: *tc.machine}
+			if got := target.isRemediating(); got != tc.expected {
+				t.Errorf("Expected remediating: %t, got: %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetRemediationInProgressConditionIncludesRemediatingTargets(t *testing.T) {
+	machineDeleting := maotesting.NewMachine("deleting", "node")
+	now := metav1.Now()
+	machineDeleting.DeletionTimestamp = &now
+	deleting := target{Machine: *machineDeleting}
+	unhealthy := target{Machine: *maotesting.NewMachine("unhealthy", "node")}
+	healthy := target{Machine: *maotesting.NewMachine("healthy", "node")}
+
+	mhc := maotesting.NewMachineHealthCheck("mhc")
+	setRemediationInProgressCondition(mhc, []target{deleting, unhealthy, healthy}, []target{unhealthy})
+
+	condition := conditions.Get(mhc, mapiv1beta1.RemediationInProgressCondition)
+	if condition == nil || condition.Status != corev1.ConditionTrue {
+		t.Fatalf("Expected RemediationInProgress condition to be true, got %+v", condition)
+	}
+	if expected := "Remediating machines: deleting, unhealthy"; condition.Message != expected {
+		t.Errorf("Expected message %q, got %q", expected, condition.Message)
+	}
+}
+
 func TestHasControllerOwner(t *testing.T) {
 	machineWithMachineSet := maotesting.NewMachine("machineWithMachineSet", "node")
 
@@ -584,47 +1068,304 @@ func TestHasControllerOwner(t *testing.T) {
 
 }
 
-func TestApplyRemediationExternal(t *testing.T) {
-	nodeUnhealthyForTooLong := maotesting.NewNode("nodeUnhealthyForTooLong", false)
-	nodeUnhealthyForTooLong.Annotations = map[string]string{
-		machineAnnotationKey: fmt.Sprintf("%s/%s", namespace, "machineUnhealthyForTooLong"),
-	}
-	machineUnhealthyForTooLong := maotesting.NewMachine("machineUnhealthyForTooLong", nodeUnhealthyForTooLong.Name)
-	machineHealthCheck := maotesting.NewMachineHealthCheck("machineHealthCheck")
-	request := reconcile.Request{
-		NamespacedName: types.NamespacedName{
-			Namespace: namespace,
-			Name:      machineUnhealthyForTooLong.Name,
+func TestReconcileRemediableOwnerKinds(t *testing.T) {
+	testCases := []struct {
+		testCase        string
+		ownerKinds      []string
+		expectedDeleted bool
+		expectedEvents  []string
+	}{
+		{
+			testCase:        "any controller kind",
+			ownerKinds:      nil,
+			expectedDeleted: true,
+			expectedEvents:  []string{EventMachineDeleted},
+		},
+		{
+			testCase:        "allowlisted custom kind",
+			ownerKinds:      []string{"MachineSet", "MachinePool"},
+			expectedDeleted: true,
+			expectedEvents:  []string{EventMachineDeleted},
+		},
+		{
+			testCase:        "custom kind not allowlisted",
+			ownerKinds:      []string{"MachineSet"},
+			expectedDeleted: false,
+			expectedEvents:  []string{EventSkippedNoController},
 		},
-	}
-	recorder := record.NewFakeRecorder(2)
-	r := newFakeReconcilerWithCustomRecorder(recorder, nodeUnhealthyForTooLong, machineUnhealthyForTooLong, machineHealthCheck)
-	target := target{
-		Node:    nodeUnhealthyForTooLong,
-		Machine: *machineUnhealthyForTooLong,
-		MHC:     mapiv1beta1.MachineHealthCheck{},
-	}
-	if err := target.remediationStrategyExternal(r); err != nil {
-		t.Fatalf("unexpected error %v", err)
-	}
-	assertEvents(
-		t,
-		"apply remediation external",
-		[]string{EventExternalAnnotationAdded},
-		recorder.Events,
-	)
-
-	machine := &mapiv1beta1.Machine{}
-	if err := r.client.Get(context.TODO(), request.NamespacedName, machine); err != nil {
-		t.Errorf("Expected: no error, got: %v", err)
 	}
 
-	if _, ok := machine.Annotations[machineExternalAnnotationKey]; !ok {
-		t.Errorf("Expected: machine to have external annotion %s, got: %v", machineExternalAnnotationKey, machine.Annotations)
-	}
-}
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("owner-kinds")
+			node := maotesting.NewNode("node", false)
+			machine := maotesting.NewMachine("machine", node.Name)
+			machine.OwnerReferences = []metav1.OwnerReference{
+				{
+					Kind:       "MachinePool",
+					Name:       "pool",
+					Controller: pointer.BoolPtr(true),
+				},
+			}
+			node.Annotations[machineAnnotationKey] = namespacedName(machine).String()
 
-func TestMHCRequestsFromMachine(t *testing.T) {
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder, mhc, node, machine)
+			r.remediableOwnerKinds = tc.ownerKinds
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+
+			err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{})
+			if deleted := apierrors.IsNotFound(err); deleted != tc.expectedDeleted {
+				t.Errorf("Expected deleted: %t, got: %v", tc.expectedDeleted, err)
+			}
+		})
+	}
+}
+
+func TestIsMaster(t *testing.T) {
+	nodeLegacyMaster := maotesting.NewNode("nodeLegacyMaster", true)
+	nodeLegacyMaster.Labels[nodeMasterLabel] = ""
+
+	nodeControlPlane := maotesting.NewNode("nodeControlPlane", true)
+	nodeControlPlane.Labels[nodeControlPlaneLabel] = ""
+
+	nodeWorker := maotesting.NewNode("nodeWorker", true)
+	nodeWorker.Labels["node-role.kubernetes.io/worker"] = ""
+
+	machineMaster := maotesting.NewMachine("machineMaster", "node")
+	machineMaster.Labels[machineRoleLabel] = machineMasterRole
+
+	machineWorker := maotesting.NewMachine("machineWorker", "node")
+	machineWorker.Labels[machineRoleLabel] = "worker"
+
+	testCases := []struct {
+		testCase     string
+		target       target
+		masterLabels []string
+		expected     bool
+	}{
+		{
+			testCase: "legacy master node label",
+			target: target{
+				Machine: *machineWorker,
+				Node:    nodeLegacyMaster,
+			},
+			masterLabels: defaultMasterLabels,
+			expected:     true,
+		},
+		{
+			testCase: "control plane node label",
+			target: target{
+				Machine: *machineWorker,
+				Node:    nodeControlPlane,
+			},
+			masterLabels: defaultMasterLabels,
+			expected:     true,
+		},
+		{
+			testCase: "master machine role label",
+			target: target{
+				Machine: *machineMaster,
+				Node:    nodeWorker,
+			},
+			masterLabels: defaultMasterLabels,
+			expected:     true,
+		},
+		{
+			testCase: "master machine without node",
+			target: target{
+				Machine: *machineMaster,
+			},
+			masterLabels: defaultMasterLabels,
+			expected:     true,
+		},
+		{
+			testCase: "worker",
+			target: target{
+				Machine: *machineWorker,
+				Node:    nodeWorker,
+			},
+			masterLabels: defaultMasterLabels,
+			expected:     false,
+		},
+		{
+			testCase: "control plane node label not configured",
+			target: target{
+				Machine: *machineWorker,
+				Node:    nodeControlPlane,
+			},
+			masterLabels: []string{nodeMasterLabel},
+			expected:     false,
+		},
+		{
+			testCase: "only control plane node label configured",
+			target: target{
+				Machine: *machineWorker,
+				Node:    nodeControlPlane,
+			},
+			masterLabels: []string{nodeControlPlaneLabel},
+			expected:     true,
+		},
+		{
+			testCase: "invalid master label is ignored",
+			target: target{
+				Machine: *machineWorker,
+				Node:    nodeLegacyMaster,
+			},
+			masterLabels: []string{"!!invalid", nodeMasterLabel},
+			expected:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			if got := tc.target.isMaster(tc.masterLabels); got != tc.expected {
+				t.Errorf("Expected: %t, got: %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestGetMasterLabels(t *testing.T) {
+	r := newFakeReconciler()
+	if !reflect.DeepEqual(r.getMasterLabels(), defaultMasterLabels) {
+		t.Errorf("Expected default master labels %v, got %v", defaultMasterLabels, r.getMasterLabels())
+	}
+
+	r.masterLabels = []string{nodeControlPlaneLabel}
+	if !reflect.DeepEqual(r.getMasterLabels(), []string{nodeControlPlaneLabel}) {
+		t.Errorf("Expected configured master labels, got %v", r.getMasterLabels())
+	}
+}
+
+func TestHasUnhealthyMachinePhase(t *testing.T) {
+	machineFailed := maotesting.NewMachine("machineFailed", "node")
+	machineFailed.Status.Phase = pointer.StringPtr(machinePhaseFailed)
+
+	machineDeleting := maotesting.NewMachine("machineDeleting", "node")
+	machineDeleting.Status.Phase = pointer.StringPtr("Deleting")
+
+	machineNoPhase := maotesting.NewMachine("machineNoPhase", "node")
+
+	testCases := []struct {
+		testCase       string
+		machine        *mapiv1beta1.Machine
+		unhealthyPhase []string
+		expected       bool
+	}{
+		{
+			testCase:       "failed machine with default phases",
+			machine:        machineFailed,
+			unhealthyPhase: nil,
+			expected:       true,
+		},
+		{
+			testCase:       "failed machine with empty phases",
+			machine:        machineFailed,
+			unhealthyPhase: []string{},
+			expected:       false,
+		},
+		{
+			testCase:       "failed machine with failed phase listed",
+			machine:        machineFailed,
+			unhealthyPhase: []string{machinePhaseFailed},
+			expected:       true,
+		},
+		{
+			testCase:       "failed machine with only deleting phase listed",
+			machine:        machineFailed,
+			unhealthyPhase: []string{"Deleting"},
+			expected:       false,
+		},
+		{
+			testCase:       "deleting machine with deleting phase listed",
+			machine:        machineDeleting,
+			unhealthyPhase: []string{machinePhaseFailed, "Deleting"},
+			expected:       true,
+		},
+		{
+			testCase:       "deleting machine with default phases",
+			machine:        machineDeleting,
+			unhealthyPhase: nil,
+			expected:       false,
+		},
+		{
+			testCase:       "machine without phase",
+			machine:        machineNoPhase,
+			unhealthyPhase: []string{""},
+			expected:       false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("test")
+			mhc.Spec.UnhealthyMachinePhases = tc.unhealthyPhase
+			target := target{
+				Machine: *tc.machine,
+				Node:    maotesting.NewNode("node", true),
+				MHC:     *mhc,
+			}
+
+			if got := target.hasUnhealthyMachinePhase(); got != tc.expected {
+				t.Errorf("Expected: %t, got: %t", tc.expected, got)
+			}
+
+			needsRemediation, _, _, err := target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: defaultNodeStartupTimeout})
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if needsRemediation != tc.expected {
+				t.Errorf("Expected needsRemediation: %t, got: %t", tc.expected, needsRemediation)
+			}
+		})
+	}
+}
+
+func TestApplyRemediationExternal(t *testing.T) {
+	nodeUnhealthyForTooLong := maotesting.NewNode("nodeUnhealthyForTooLong", false)
+	nodeUnhealthyForTooLong.Annotations = map[string]string{
+		machineAnnotationKey: fmt.Sprintf("%s/%s", namespace, "machineUnhealthyForTooLong"),
+	}
+	machineUnhealthyForTooLong := maotesting.NewMachine("machineUnhealthyForTooLong", nodeUnhealthyForTooLong.Name)
+	machineHealthCheck := maotesting.NewMachineHealthCheck("machineHealthCheck")
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: namespace,
+			Name:      machineUnhealthyForTooLong.Name,
+		},
+	}
+	recorder := record.NewFakeRecorder(2)
+	r := newFakeReconcilerWithCustomRecorder(recorder, nodeUnhealthyForTooLong, machineUnhealthyForTooLong, machineHealthCheck)
+	target := target{
+		Node:    nodeUnhealthyForTooLong,
+		Machine: *machineUnhealthyForTooLong,
+		MHC:     mapiv1beta1.MachineHealthCheck{},
+	}
+	if err := target.remediationStrategyExternal(r); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assertEvents(
+		t,
+		"apply remediation external",
+		[]string{EventExternalAnnotationAdded},
+		recorder.Events,
+	)
+
+	machine := &mapiv1beta1.Machine{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, machine); err != nil {
+		t.Errorf("Expected: no error, got: %v", err)
+	}
+
+	if _, ok := machine.Annotations[machineExternalAnnotationKey]; !ok {
+		t.Errorf("Expected: machine to have external annotion %s, got: %v", machineExternalAnnotationKey, machine.Annotations)
+	}
+}
+
+func TestMHCRequestsFromMachine(t *testing.T) {
 	testCases := []struct {
 		testCase         string
 		mhcs             []*mapiv1beta1.MachineHealthCheck
@@ -980,10 +1721,22 @@ func TestMHCRequestsFromNode(t *testing.T) {
 				objects = append(objects, runtime.Object(tc.mhcs[i]))
 			}
 
-			requests := newFakeReconciler(objects...).mhcRequestsFromNode(tc.node)
+			r := newFakeReconciler(objects...)
+			requests := r.mhcRequestsFromNode(tc.node)
 			if !reflect.DeepEqual(requests, tc.expectedRequests) {
 				t.Errorf("Expected: %v, got: %v", tc.expectedRequests, requests)
 			}
+
+			// mhcsForNode must return exactly the MHCs the requests were derived from
+			mhcs, _ := r.mhcsForNode(tc.node)
+			if len(mhcs) != len(tc.expectedRequests) {
+				t.Fatalf("Expected %d MHCs, got: %d", len(tc.expectedRequests), len(mhcs))
+			}
+			for i := range mhcs {
+				if namespacedName(&mhcs[i]) != tc.expectedRequests[i].NamespacedName {
+					t.Errorf("Expected MHC %v, got: %v", tc.expectedRequests[i].NamespacedName, namespacedName(&mhcs[i]))
+				}
+			}
 		})
 	}
 }
@@ -1163,8 +1916,8 @@ func TestGetTargetsFromMHC(t *testing.T) {
 			testCase: "more than one match",
 			mhc:      mhc,
 			machines: []*mapiv1beta1.Machine{
-				machine1,
 				machine2,
+				machine1,
 			},
 			nodes: []*corev1.Node{
 				{
@@ -1280,17 +2033,54 @@ func TestGetTargetsFromMHC(t *testing.T) {
 						Spec:   mapiv1beta1.MachineSpec{},
 						Status: mapiv1beta1.MachineStatus{},
 					},
-					Node: nil,
+					Node:       nil,
+					SkipReason: skipReasonNoOwner,
 				},
 			},
 		},
 		{
-			testCase: "node not found",
+			testCase: "node references another machine",
 			mhc:      mhc,
 			machines: []*mapiv1beta1.Machine{
 				machine1,
+				machine2,
+			},
+			nodes: []*corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "node1",
+						Namespace: metav1.NamespaceNone,
+						Annotations: map[string]string{
+							machineAnnotationKey: fmt.Sprintf("%s/%s", namespace, "match2"),
+						},
+						Labels: map[string]string{},
+					},
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "Node",
+						APIVersion: "v1",
+					},
+					Status: corev1.NodeStatus{
+						Conditions: []corev1.NodeCondition{},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "node2",
+						Namespace: metav1.NamespaceNone,
+						Annotations: map[string]string{
+							machineAnnotationKey: fmt.Sprintf("%s/%s", namespace, "match2"),
+						},
+						Labels: map[string]string{},
+					},
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "Node",
+						APIVersion: "v1",
+					},
+					Status: corev1.NodeStatus{
+						Conditions: []corev1.NodeCondition{},
+					},
+				},
 			},
-			nodes: []*corev1.Node{},
 			expectedTargets: []target{
 				{
 					MHC:     *mhc,
@@ -1299,50 +2089,188 @@ func TestGetTargetsFromMHC(t *testing.T) {
 						ObjectMeta: metav1.ObjectMeta{
 							Name:      "node1",
 							Namespace: metav1.NamespaceNone,
+							Annotations: map[string]string{
+								machineAnnotationKey: fmt.Sprintf("%s/%s", namespace, "match2"),
+							},
+							Labels: map[string]string{},
+						},
+						TypeMeta: metav1.TypeMeta{
+							Kind:       "Node",
+							APIVersion: "v1",
+						},
+						Status: corev1.NodeStatus{
+							Conditions: []corev1.NodeCondition{},
+						},
+					},
+					SkipReason: skipReasonInconsistent,
+				},
+				{
+					MHC:     *mhc,
+					Machine: *machine2,
+					Node: &corev1.Node{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "node2",
+							Namespace: metav1.NamespaceNone,
+							Annotations: map[string]string{
+								machineAnnotationKey: fmt.Sprintf("%s/%s", namespace, "match2"),
+							},
+							Labels: map[string]string{},
+						},
+						TypeMeta: metav1.TypeMeta{
+							Kind:       "Node",
+							APIVersion: "v1",
+						},
+						Status: corev1.NodeStatus{
+							Conditions: []corev1.NodeCondition{},
 						},
 					},
 				},
 			},
 		},
-	}
-	for _, tc := range testCases {
-		var objects []runtime.Object
-		objects = append(objects, runtime.Object(tc.mhc))
-		for i := range tc.machines {
-			objects = append(objects, runtime.Object(tc.machines[i]))
-		}
-		for i := range tc.nodes {
-			objects = append(objects, runtime.Object(tc.nodes[i]))
-		}
-
-		t.Run(tc.testCase, func(t *testing.T) {
-			got, err := newFakeReconciler(objects...).getTargetsFromMHC(*tc.mhc)
-			if !equality.Semantic.DeepEqual(got, tc.expectedTargets) {
-				t.Errorf("Case: %v. Got: %+v, expected: %+v", tc.testCase, got, tc.expectedTargets)
-			}
-			if tc.expectedError != (err != nil) {
-				t.Errorf("Case: %v. Got: %v, expected error: %v", tc.testCase, err, tc.expectedError)
-			}
-		})
-	}
-}
-
-func TestGetNodeFromMachine(t *testing.T) {
-	testCases := []struct {
-		testCase      string
-		machine       *mapiv1beta1.Machine
-		node          *corev1.Node
-		expectedNode  *corev1.Node
-		expectedError bool
-	}{
 		{
-			testCase: "match",
-			machine: &mapiv1beta1.Machine{
-				TypeMeta: metav1.TypeMeta{Kind: "Machine"},
-				ObjectMeta: metav1.ObjectMeta{
-					Annotations:     make(map[string]string),
-					Name:            "machine",
-					Namespace:       namespace,
+			testCase: "node references a machine which does not exist",
+			mhc:      mhc,
+			machines: []*mapiv1beta1.Machine{
+				machine1,
+			},
+			nodes: []*corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "node1",
+						Namespace: metav1.NamespaceNone,
+						Annotations: map[string]string{
+							machineAnnotationKey: fmt.Sprintf("%s/%s", namespace, "deleted"),
+						},
+						Labels: map[string]string{},
+					},
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "Node",
+						APIVersion: "v1",
+					},
+					Status: corev1.NodeStatus{
+						Conditions: []corev1.NodeCondition{},
+					},
+				},
+			},
+			expectedTargets: []target{
+				{
+					MHC:     *mhc,
+					Machine: *machine1,
+					Node: &corev1.Node{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "node1",
+							Namespace: metav1.NamespaceNone,
+							Annotations: map[string]string{
+								machineAnnotationKey: fmt.Sprintf("%s/%s", namespace, "deleted"),
+							},
+							Labels: map[string]string{},
+						},
+						TypeMeta: metav1.TypeMeta{
+							Kind:       "Node",
+							APIVersion: "v1",
+						},
+						Status: corev1.NodeStatus{
+							Conditions: []corev1.NodeCondition{},
+						},
+					},
+				},
+			},
+		},
+		{
+			testCase: "node not found",
+			mhc:      mhc,
+			machines: []*mapiv1beta1.Machine{
+				machine1,
+			},
+			nodes: []*corev1.Node{},
+			expectedTargets: []target{
+				{
+					MHC:     *mhc,
+					Machine: *machine1,
+					Node: &corev1.Node{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "node1",
+							Namespace: metav1.NamespaceNone,
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		var objects []runtime.Object
+		objects = append(objects, runtime.Object(tc.mhc))
+		for i := range tc.machines {
+			objects = append(objects, runtime.Object(tc.machines[i]))
+		}
+		for i := range tc.nodes {
+			objects = append(objects, runtime.Object(tc.nodes[i]))
+		}
+
+		t.Run(tc.testCase, func(t *testing.T) {
+			got, err := newFakeReconciler(objects...).getTargetsFromMHC(*tc.mhc)
+			if !equality.Semantic.DeepEqual(got, tc.expectedTargets) {
+				t.Errorf("Case: %v. Got: %+v, expected: %+v", tc.testCase, got, tc.expectedTargets)
+			}
+			if tc.expectedError != (err != nil) {
+				t.Errorf("Case: %v. Got: %v, expected error: %v", tc.testCase, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestGetTargetsFromMHCMatchedMachines(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("matchedMachines")
+	matched1 := maotesting.NewMachine("matched1", "node1")
+	matched2 := maotesting.NewMachine("matched2", "node2")
+	unmatched := maotesting.NewMachine("unmatched", "node3")
+	unmatched.Labels = map[string]string{"no": "match"}
+
+	r := newFakeReconciler(mhc, matched1, matched2, unmatched)
+	if _, err := r.getTargetsFromMHC(*mhc); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	gauge, err := metrics.MachineHealthCheckMatchedMachines.GetMetricWithLabelValues(mhc.Name, mhc.Namespace)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	metric := &dto.Metric{}
+	if err := gauge.(prometheus.Metric).Write(metric); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := metric.GetGauge().GetValue(); got != 2 {
+		t.Errorf("Expected 2 matched machines, got %v", got)
+	}
+
+	// the series is removed once the MHC is gone
+	if err := r.client.Delete(ctx, mhc); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if metrics.MachineHealthCheckMatchedMachines.Delete(prometheus.Labels{"name": mhc.Name, "namespace": mhc.Namespace}) {
+		t.Errorf("Expected matched machines of a deleted MHC to be removed")
+	}
+}
+
+func TestGetNodeFromMachine(t *testing.T) {
+	testCases := []struct {
+		testCase      string
+		machine       *mapiv1beta1.Machine
+		node          *corev1.Node
+		expectedNode  *corev1.Node
+		expectedError bool
+	}{
+		{
+			testCase: "match",
+			machine: &mapiv1beta1.Machine{
+				TypeMeta: metav1.TypeMeta{Kind: "Machine"},
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations:     make(map[string]string),
+					Name:            "machine",
+					Namespace:       namespace,
 					Labels:          map[string]string{"foo": "bar"},
 					OwnerReferences: []metav1.OwnerReference{{Kind: "MachineSet"}},
 				},
@@ -1462,6 +2390,7 @@ func TestGetNodeFromMachine(t *testing.T) {
 }
 
 func TestNeedsRemediation(t *testing.T) {
+	now := time.Now()
 	knownDate := metav1.Time{Time: time.Date(1985, 06, 03, 0, 0, 0, 0, time.Local)}
 	machineFailed := machinePhaseFailed
 	testCases := []struct {
@@ -1588,7 +2517,7 @@ func TestNeedsRemediation(t *testing.T) {
 					},
 					Spec: mapiv1beta1.MachineSpec{},
 					Status: mapiv1beta1.MachineStatus{
-						LastUpdated: &metav1.Time{Time: time.Now().Add(time.Duration(-defaultNodeStartupTimeout) - 1*time.Second)},
+						LastUpdated: &metav1.Time{Time: now.Add(time.Duration(-defaultNodeStartupTimeout) - 1*time.Second)},
 					},
 				},
 				Node: nil,
@@ -1641,7 +2570,7 @@ func TestNeedsRemediation(t *testing.T) {
 					},
 					Spec: mapiv1beta1.MachineSpec{},
 					Status: mapiv1beta1.MachineStatus{
-						LastUpdated: &metav1.Time{Time: time.Now().Add(time.Duration(-defaultNodeStartupTimeout) - 1*time.Second)},
+						LastUpdated: &metav1.Time{Time: now.Add(time.Duration(-defaultNodeStartupTimeout) - 1*time.Second)},
 					},
 				},
 				Node: &corev1.Node{
@@ -1662,7 +2591,7 @@ func TestNeedsRemediation(t *testing.T) {
 							{
 								Type:               corev1.NodeReady,
 								Status:             corev1.ConditionFalse,
-								LastTransitionTime: metav1.Time{Time: time.Now().Add(time.Duration(-400) * time.Second)},
+								LastTransitionTime: metav1.Time{Time: now.Add(time.Duration(-400) * time.Second)},
 							},
 						},
 					},
@@ -1769,7 +2698,7 @@ func TestNeedsRemediation(t *testing.T) {
 					},
 					Spec: mapiv1beta1.MachineSpec{},
 					Status: mapiv1beta1.MachineStatus{
-						LastUpdated: &metav1.Time{Time: time.Now().Add(time.Duration(-defaultNodeStartupTimeout) - 1*time.Second)},
+						LastUpdated: &metav1.Time{Time: now.Add(time.Duration(-defaultNodeStartupTimeout) - 1*time.Second)},
 					},
 				},
 				Node: &corev1.Node{
@@ -1790,7 +2719,7 @@ func TestNeedsRemediation(t *testing.T) {
 							{
 								Type:               corev1.NodeReady,
 								Status:             corev1.ConditionFalse,
-								LastTransitionTime: metav1.Time{Time: time.Now().Add(time.Duration(-200) * time.Second)},
+								LastTransitionTime: metav1.Time{Time: now.Add(time.Duration(-200) * time.Second)},
 							},
 						},
 					},
@@ -1827,29 +2756,20 @@ func TestNeedsRemediation(t *testing.T) {
 			},
 			timeoutForMachineToHaveNode: defaultNodeStartupTimeout,
 			expectedNeedsRemediation:    false,
-			expectedNextCheck:           time.Duration(1 * time.Minute), // 300-200 rounded
+			expectedNextCheck:           101 * time.Second, // 300-200 plus one second
 			expectedError:               false,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.testCase, func(t *testing.T) {
-			needsRemediation, nextCheck, err := tc.target.needsRemediation(tc.timeoutForMachineToHaveNode)
+			tc.target.clock = clock.NewFakeClock(now)
+			needsRemediation, _, nextCheck, err := tc.target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: tc.timeoutForMachineToHaveNode})
 			if needsRemediation != tc.expectedNeedsRemediation {
 				t.Errorf("Case: %v. Got: %v, expected: %v", tc.testCase, needsRemediation, tc.expectedNeedsRemediation)
 			}
-			if tc.expectedNextCheck == time.Duration(0) {
-				if nextCheck != tc.expectedNextCheck {
-					t.Errorf("Case: %v. Got: %v, expected: %v", tc.testCase, int(nextCheck), int(tc.expectedNextCheck))
-				}
-			}
-			if tc.expectedNextCheck != time.Duration(0) {
-				now := time.Now()
-				// since isUnhealthy will check timeout against now() again, the nextCheck must be slightly lower to the
-				// margin calculated here
-				if now.Add(nextCheck).Before(now.Add(tc.expectedNextCheck)) {
-					t.Errorf("Case: %v. Got: %v, expected: %v", tc.testCase, nextCheck, tc.expectedNextCheck)
-				}
+			if nextCheck != tc.expectedNextCheck {
+				t.Errorf("Case: %v. Got next check: %v, expected: %v", tc.testCase, nextCheck, tc.expectedNextCheck)
 			}
 			if tc.expectedError != (err != nil) {
 				t.Errorf("Case: %v. Got: %v, expected error: %v", tc.testCase, err, tc.expectedError)
@@ -1858,6 +2778,915 @@ func TestNeedsRemediation(t *testing.T) {
 	}
 }
 
+// testLogger is a logr.Logger which records all info log lines
+type testLogger struct {
+	entries *[]testLogEntry
+}
+
+type testLogEntry struct {
+	msg           string
+	keysAndValues map[string]interface{}
+}
+
+func (l testLogger) Enabled() bool { return true }
+
+func (l testLogger) Info(msg string, keysAndValues ...interface{}) {
+	entry := testLogEntry{msg: msg, keysAndValues: map[string]interface{}{}}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		entry.keysAndValues[fmt.Sprint(keysAndValues[i])] = keysAndValues[i+1]
+	}
+	*l.entries = append(*l.entries, entry)
+}
+
+func (l testLogger) Error(err error, msg string, keysAndValues ...interface{}) {}
+
+func (l testLogger) V(level int) logr.Logger { return l }
+
+func (l testLogger) WithValues(keysAndValues ...interface{}) logr.Logger { return l }
+
+func (l testLogger) WithName(name string) logr.Logger { return l }
+
+func TestNeedsRemediationTimeoutUnits(t *testing.T) {
+	testCases := []struct {
+		timeout         string
+		expectedTimeout time.Duration
+		expectedError   bool
+	}{
+		{timeout: "300s", expectedTimeout: 300 * time.Second},
+		{timeout: "5m", expectedTimeout: 5 * time.Minute},
+		{timeout: "1h", expectedTimeout: time.Hour},
+		{timeout: "1h30m", expectedTimeout: 90 * time.Minute},
+		{timeout: "1.5h", expectedTimeout: 90 * time.Minute},
+		// timeouts without a unit are ambiguous and rejected
+		{timeout: "300", expectedError: true},
+		{timeout: "badTimeout", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.timeout, func(t *testing.T) {
+			condition := mapiv1beta1.UnhealthyCondition{}
+			data := fmt.Sprintf(`{"type": "Ready", "status": "Unknown", "timeout": %q}`, tc.timeout)
+			err := json.Unmarshal([]byte(data), &condition)
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("Expected error: %t, got: %v", tc.expectedError, err)
+			}
+			if tc.expectedError {
+				return
+			}
+			if condition.Timeout.Duration != tc.expectedTimeout {
+				t.Fatalf("Expected timeout %v, got %v", tc.expectedTimeout, condition.Timeout.Duration)
+			}
+
+			mhc := maotesting.NewMachineHealthCheck("timeout")
+			mhc.Spec.UnhealthyConditions = []mapiv1beta1.UnhealthyCondition{condition}
+			node := maotesting.NewNode("node", false)
+			target := target{
+				Machine: *maotesting.NewMachine("machine", node.Name),
+				Node:    node,
+				MHC:     *mhc,
+			}
+
+			// unhealthy for a minute less than the timeout
+			node.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(time.Minute - tc.expectedTimeout))
+			needsRemediation, _, nextCheck, err := target.needsRemediation(healthCheckTimeouts{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if needsRemediation {
+				t.Errorf("Expected no remediation before the timeout")
+			}
+			if nextCheck < 59*time.Second || nextCheck > 61*time.Second {
+				t.Errorf("Expected a next check in about a minute, got %v", nextCheck)
+			}
+
+			// unhealthy for a minute longer than the timeout
+			node.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Minute - tc.expectedTimeout))
+			if needsRemediation, _, _, err := target.needsRemediation(healthCheckTimeouts{}); err != nil || !needsRemediation {
+				t.Errorf("Expected remediation after the timeout, got: %t, %v", needsRemediation, err)
+			}
+		})
+	}
+}
+
+func TestNeedsRemediationFutureTimeoutReference(t *testing.T) {
+	// the reference is ahead of the local clock, e.g. due to clock skew with the kubelet
+	future := metav1.NewTime(time.Now().Add(30 * time.Second))
+
+	testCases := []struct {
+		testCase string
+		timeout  time.Duration
+		nodeless bool
+	}{
+		{
+			testCase: "condition with zero timeout",
+			timeout:  0,
+		},
+		{
+			testCase: "condition with timeout",
+			timeout:  5 * time.Minute,
+		},
+		{
+			testCase: "machine without node",
+			timeout:  5 * time.Minute,
+			nodeless: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			mhc.Spec.UnhealthyConditions = []mapiv1beta1.UnhealthyCondition{
+				{
+					Type:    corev1.NodeReady,
+					Status:  corev1.ConditionUnknown,
+					Timeout: metav1.Duration{Duration: tc.timeout},
+				},
+			}
+			node := maotesting.NewNode("node", false)
+			node.Status.Conditions[0].LastTransitionTime = future
+			machine := maotesting.NewMachine("machine", node.Name)
+			machine.Status.LastUpdated = &future
+			target := target{Machine: *machine, Node: node, MHC: *mhc}
+			if tc.nodeless {
+				target.Node = nil
+			}
+
+			needsRemediation, _, nextCheck, err := target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: tc.timeout})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if needsRemediation {
+				t.Errorf("Expected a timeout reference in the future not to be timed out")
+			}
+			// the timeout starts from now rather than being extended by the skew
+			if nextCheck <= 0 || nextCheck > tc.timeout+time.Second {
+				t.Errorf("Expected next check within %v, got %v", tc.timeout+time.Second, nextCheck)
+			}
+		})
+	}
+}
+
+func TestNeedsRemediationNodelessWithoutLastUpdated(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	testCases := []struct {
+		testCase                 string
+		created                  metav1.Time
+		expectedNeedsRemediation bool
+		expectedNextCheck        time.Duration
+	}{
+		{
+			testCase:          "created within node startup timeout",
+			created:           metav1.NewTime(now.Add(-4 * time.Minute)),
+			expectedNextCheck: 6*time.Minute + time.Second,
+		},
+		{
+			testCase:                 "created before node startup timeout",
+			created:                  metav1.NewTime(now.Add(-defaultNodeStartupTimeout - time.Second)),
+			expectedNeedsRemediation: true,
+		},
+		{
+			testCase:          "not created yet",
+			expectedNextCheck: defaultNodeStartupTimeout,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			machine := maotesting.NewMachine("machine", "")
+			machine.Status.NodeRef = nil
+			machine.Status.LastUpdated = nil
+			machine.CreationTimestamp = tc.created
+			target := target{
+				Machine: *machine,
+				MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+				clock:   clock.NewFakePassiveClock(now),
+			}
+
+			needsRemediation, _, nextCheck, err := target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: defaultNodeStartupTimeout})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if needsRemediation != tc.expectedNeedsRemediation {
+				t.Errorf("Expected needsRemediation: %t, got: %t", tc.expectedNeedsRemediation, needsRemediation)
+			}
+			if nextCheck != tc.expectedNextCheck {
+				t.Errorf("Expected next check in %v, got %v", tc.expectedNextCheck, nextCheck)
+			}
+		})
+	}
+}
+
+func TestElapsedSince(t *testing.T) {
+	now := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	if got := elapsedSince(now.Add(-time.Minute), now); got != time.Minute {
+		t.Errorf("Expected %v elapsed, got %v", time.Minute, got)
+	}
+	if got := elapsedSince(now.Add(time.Minute), now); got != 0 {
+		t.Errorf("Expected no time elapsed for a reference in the future, got %v", got)
+	}
+}
+
+func TestUnhealthyConditionsNodeReadyTimeout(t *testing.T) {
+	readyTimeout := metav1.Duration{Duration: 5 * time.Minute}
+	explicitTimeout := metav1.Duration{Duration: 10 * time.Minute}
+	diskPressure := mapiv1beta1.UnhealthyCondition{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue, Timeout: explicitTimeout}
+
+	testCases := []struct {
+		testCase string
+		explicit []mapiv1beta1.UnhealthyCondition
+		timeout  metav1.Duration
+		expected []mapiv1beta1.UnhealthyCondition
+	}{
+		{
+			testCase: "no node ready timeout",
+			explicit: []mapiv1beta1.UnhealthyCondition{diskPressure},
+			expected: []mapiv1beta1.UnhealthyCondition{diskPressure},
+		},
+		{
+			testCase: "node ready timeout only",
+			timeout:  readyTimeout,
+			expected: []mapiv1beta1.UnhealthyCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Timeout: readyTimeout},
+				{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Timeout: readyTimeout},
+			},
+		},
+		{
+			testCase: "node ready timeout combined with other conditions",
+			explicit: []mapiv1beta1.UnhealthyCondition{diskPressure},
+			timeout:  readyTimeout,
+			expected: []mapiv1beta1.UnhealthyCondition{
+				diskPressure,
+				{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Timeout: readyTimeout},
+				{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Timeout: readyTimeout},
+			},
+		},
+		{
+			testCase: "explicit ready condition takes precedence",
+			explicit: []mapiv1beta1.UnhealthyCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Timeout: explicitTimeout},
+			},
+			timeout: readyTimeout,
+			expected: []mapiv1beta1.UnhealthyCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Timeout: explicitTimeout},
+				{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Timeout: readyTimeout},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			mhc.Spec.UnhealthyConditions = tc.explicit
+			mhc.Spec.NodeReadyTimeout = tc.timeout
+			if got := unhealthyConditions(mhc); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected conditions %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestNeedsRemediationNodeReadyTimeoutMatchesExplicitConditions(t *testing.T) {
+	timeout := metav1.Duration{Duration: 5 * time.Minute}
+	explicit := maotesting.NewMachineHealthCheck("explicit")
+	explicit.Spec.UnhealthyConditions = []mapiv1beta1.UnhealthyCondition{
+		{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Timeout: timeout},
+		{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Timeout: timeout},
+	}
+	shortcut := maotesting.NewMachineHealthCheck("shortcut")
+	shortcut.Spec.UnhealthyConditions = nil
+	shortcut.Spec.NodeReadyTimeout = timeout
+
+	testCases := []struct {
+		testCase string
+		status   corev1.ConditionStatus
+		since    time.Duration
+	}{
+		{testCase: "ready", status: corev1.ConditionTrue, since: time.Hour},
+		{testCase: "not ready within timeout", status: corev1.ConditionFalse, since: time.Minute},
+		{testCase: "not ready beyond timeout", status: corev1.ConditionFalse, since: time.Hour},
+		{testCase: "unknown within timeout", status: corev1.ConditionUnknown, since: time.Minute},
+		{testCase: "unknown beyond timeout", status: corev1.ConditionUnknown, since: time.Hour},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			node := maotesting.NewNode("node", true)
+			node.Status.Conditions[0].Status = tc.status
+			node.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-tc.since))
+			machine := maotesting.NewMachine("machine", node.Name)
+
+			explicitTarget := target{Machine: *machine, Node: node, MHC: *explicit}
+			shortcutTarget := target{Machine: *machine, Node: node, MHC: *shortcut}
+
+			expectedNeedsRemediation, expectedCondition, expectedNextCheck, expectedErr := explicitTarget.needsRemediation(healthCheckTimeouts{})
+			needsRemediation, condition, nextCheck, err := shortcutTarget.needsRemediation(healthCheckTimeouts{})
+			if err != nil || expectedErr != nil {
+				t.Fatalf("Unexpected errors: %v, %v", expectedErr, err)
+			}
+			if needsRemediation != expectedNeedsRemediation || condition != expectedCondition {
+				t.Errorf("Expected needs remediation %t with condition %q, got %t with condition %q",
+					expectedNeedsRemediation, expectedCondition, needsRemediation, condition)
+			}
+			// both are computed from the current time, allow for the time between the calls
+			if diff := expectedNextCheck - nextCheck; diff > time.Second || diff < -time.Second {
+				t.Errorf("Expected next check %v, got %v", expectedNextCheck, nextCheck)
+			}
+		})
+	}
+}
+
+func TestReconcileCustomNodeCondition(t *testing.T) {
+	kernelDeadlock := corev1.NodeConditionType("KernelDeadlock")
+
+	mhc := maotesting.NewMachineHealthCheck("customCondition")
+	mhc.Spec.UnhealthyConditions = []mapiv1beta1.UnhealthyCondition{
+		{
+			Type:    kernelDeadlock,
+			Status:  corev1.ConditionTrue,
+			Timeout: metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+
+	objects := []runtime.Object{mhc}
+	for name, status := range map[string]corev1.ConditionStatus{
+		"deadlocked": corev1.ConditionTrue,
+		"healthy":    corev1.ConditionFalse,
+	} {
+		// the node is ready, only the custom condition reports a problem
+		node := maotesting.NewNode(name+"-node", true)
+		node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+			Type:               kernelDeadlock,
+			Status:             status,
+			LastTransitionTime: maotesting.KnownDate,
+		})
+		machine := maotesting.NewMachine(name, node.Name)
+		node.Annotations[machineAnnotationKey] = fmt.Sprintf("%s/%s", machine.Namespace, machine.Name)
+		objects = append(objects, machine, node)
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	r := newFakeReconcilerWithCustomRecorder(recorder, objects...)
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertEvents(t, "custom node condition", []string{EventMachineDeleted}, recorder.Events)
+
+	for name, expectedDeleted := range map[string]bool{"deadlocked": true, "healthy": false} {
+		err := r.client.Get(ctx, namespacedName(maotesting.NewMachine(name, "")), &mapiv1beta1.Machine{})
+		if deleted := apierrors.IsNotFound(err); deleted != expectedDeleted {
+			t.Errorf("%s: expected deleted: %t, got: %v", name, expectedDeleted, err)
+		}
+	}
+}
+
+func TestNeedsRemediationLogsConditionSummary(t *testing.T) {
+	flags := &flag.FlagSet{}
+	klog.InitFlags(flags)
+	if err := flags.Set("v", "2"); err != nil {
+		t.Fatal(err)
+	}
+	var entries []testLogEntry
+	klog.SetLogger(testLogger{entries: &entries})
+	defer func() {
+		klog.SetLogger(nil)
+		if err := flags.Set("v", "0"); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	node := maotesting.NewNode("node", false)
+	node.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Minute))
+	target := target{
+		Machine: *maotesting.NewMachine("machine", node.Name),
+		Node:    node,
+		MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+	}
+
+	needsRemediation, _, _, err := target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: defaultNodeStartupTimeout})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if needsRemediation {
+		t.Fatalf("Expected target not to need remediation")
+	}
+
+	var summaries []testLogEntry
+	for _, entry := range entries {
+		if entry.msg == "Evaluated unhealthy condition" {
+			summaries = append(summaries, entry)
+		}
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("Expected 1 condition summary log line, got %d", len(summaries))
+	}
+
+	summary := summaries[0].keysAndValues
+	if summary["target"] != target.string() {
+		t.Errorf("Expected target %q, got %v", target.string(), summary["target"])
+	}
+	if summary["type"] != corev1.NodeReady {
+		t.Errorf("Expected type %q, got %v", corev1.NodeReady, summary["type"])
+	}
+	if summary["status"] != corev1.ConditionUnknown {
+		t.Errorf("Expected status %q, got %v", corev1.ConditionUnknown, summary["status"])
+	}
+	if summary["timeout"] != "5m0s" {
+		t.Errorf("Expected timeout 5m0s, got %v", summary["timeout"])
+	}
+	remaining, err := time.ParseDuration(fmt.Sprint(summary["remaining"]))
+	if err != nil {
+		t.Fatalf("Unable to parse remaining duration: %v", err)
+	}
+	if remaining <= 3*time.Minute || remaining > 4*time.Minute {
+		t.Errorf("Expected remaining duration of about 4m, got %v", remaining)
+	}
+}
+
+func TestNeedsRemediationNodeGracePeriod(t *testing.T) {
+	nodeGracePeriod := 5 * time.Minute
+
+	testCases := []struct {
+		testCase                 string
+		nodeAge                  time.Duration
+		nodeGracePeriod          time.Duration
+		expectedNeedsRemediation bool
+		expectedNextCheckMin     time.Duration
+		expectedNextCheckMax     time.Duration
+	}{
+		{
+			testCase:                 "node just created",
+			nodeAge:                  time.Minute,
+			nodeGracePeriod:          nodeGracePeriod,
+			expectedNeedsRemediation: false,
+			expectedNextCheckMin:     3 * time.Minute,
+			expectedNextCheckMax:     4 * time.Minute,
+		},
+		{
+			testCase:                 "node older than grace period",
+			nodeAge:                  time.Hour,
+			nodeGracePeriod:          nodeGracePeriod,
+			expectedNeedsRemediation: true,
+		},
+		{
+			testCase:                 "grace period disabled",
+			nodeAge:                  time.Minute,
+			nodeGracePeriod:          0,
+			expectedNeedsRemediation: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			// The node has been unhealthy for longer than the MHC timeout
+			node := maotesting.NewNode("node", false)
+			node.CreationTimestamp = metav1.NewTime(time.Now().Add(-tc.nodeAge))
+			target := target{
+				Machine: *maotesting.NewMachine("machine", node.Name),
+				Node:    node,
+				MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+			}
+
+			needsRemediation, _, nextCheck, err := target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: defaultNodeStartupTimeout, nodeGracePeriod: tc.nodeGracePeriod})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if needsRemediation != tc.expectedNeedsRemediation {
+				t.Errorf("Expected needsRemediation: %t, got: %t", tc.expectedNeedsRemediation, needsRemediation)
+			}
+			if !tc.expectedNeedsRemediation && (nextCheck < tc.expectedNextCheckMin || nextCheck > tc.expectedNextCheckMax) {
+				t.Errorf("Expected next check between %v and %v, got %v", tc.expectedNextCheckMin, tc.expectedNextCheckMax, nextCheck)
+			}
+		})
+	}
+}
+
+func TestNeedsRemediationCordonedNotReady(t *testing.T) {
+	// the node has not been ready for 10 minutes, the MHC condition timeouts are 300s
+	notReadySince := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	newNode := func(unschedulable bool, status corev1.ConditionStatus) *corev1.Node {
+		node := maotesting.NewNode("node", true)
+		node.Spec.Unschedulable = unschedulable
+		node.Status.Conditions = []corev1.NodeCondition{
+			{
+				Type:               corev1.NodeReady,
+				Status:             status,
+				LastTransitionTime: notReadySince,
+			},
+		}
+		return node
+	}
+
+	testCases := []struct {
+		testCase                   string
+		node                       *corev1.Node
+		cordonedNotReadyTimeout    time.Duration
+		expectedNeedsRemediation   bool
+		expectedUnhealthyCondition string
+	}{
+		{
+			testCase:                   "cordoned and not ready past accelerated timeout",
+			node:                       newNode(true, corev1.ConditionFalse),
+			cordonedNotReadyTimeout:    5 * time.Minute,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: unhealthyConditionCordonedNotReady,
+		},
+		{
+			testCase:                 "cordoned and not ready within accelerated timeout",
+			node:                     newNode(true, corev1.ConditionFalse),
+			cordonedNotReadyTimeout:  time.Hour,
+			expectedNeedsRemediation: true,
+			// the regular condition timeout still applies
+			expectedUnhealthyCondition: "Ready=False",
+		},
+		{
+			testCase:                   "schedulable and not ready uses condition timeout",
+			node:                       newNode(false, corev1.ConditionFalse),
+			cordonedNotReadyTimeout:    5 * time.Minute,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: "Ready=False",
+		},
+		{
+			testCase:                 "cordoned and ready",
+			node:                     newNode(true, corev1.ConditionTrue),
+			cordonedNotReadyTimeout:  5 * time.Minute,
+			expectedNeedsRemediation: false,
+		},
+		{
+			testCase:                   "accelerated timeout disabled",
+			node:                       newNode(true, corev1.ConditionFalse),
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: "Ready=False",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			target := target{
+				Machine: *maotesting.NewMachine("machine", tc.node.Name),
+				Node:    tc.node,
+				MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+			}
+			needsRemediation, unhealthyCondition, _, err := target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: defaultNodeStartupTimeout, cordonedNotReadyTimeout: tc.cordonedNotReadyTimeout})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if needsRemediation != tc.expectedNeedsRemediation {
+				t.Errorf("Expected needs remediation: %t, got: %t", tc.expectedNeedsRemediation, needsRemediation)
+			}
+			if unhealthyCondition != tc.expectedUnhealthyCondition {
+				t.Errorf("Expected unhealthy condition %q, got %q", tc.expectedUnhealthyCondition, unhealthyCondition)
+			}
+		})
+	}
+
+	// within both timeouts, the accelerated timeout triggers the next check first
+	node := newNode(true, corev1.ConditionUnknown)
+	node.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Minute))
+	target := target{
+		Machine: *maotesting.NewMachine("machine", node.Name),
+		Node:    node,
+		MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+	}
+	needsRemediation, _, nextCheck, err := target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: defaultNodeStartupTimeout, cordonedNotReadyTimeout: 2 * time.Minute})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if needsRemediation {
+		t.Errorf("Expected no remediation within timeouts")
+	}
+	if nextCheck <= 0 || nextCheck > time.Minute+time.Second {
+		t.Errorf("Expected next check within the accelerated timeout, got %v", nextCheck)
+	}
+}
+
+func TestNeedsRemediationUnhealthyCondition(t *testing.T) {
+	nodeNotReady := maotesting.NewNode("nodeNotReady", false)
+
+	machineFailed := maotesting.NewMachine("machineFailed", "node")
+	machineFailed.Status.Phase = pointer.StringPtr(machinePhaseFailed)
+
+	machineNoNode := maotesting.NewMachine("machineNoNode", "")
+	machineNoNode.Status.LastUpdated = &maotesting.KnownDate
+
+	testCases := []struct {
+		testCase                   string
+		target                     target
+		expectedUnhealthyCondition string
+	}{
+		{
+			testCase: "ready condition tripped",
+			target: target{
+				Machine: *maotesting.NewMachine("machine", nodeNotReady.Name),
+				Node:    nodeNotReady,
+			},
+			expectedUnhealthyCondition: "Ready=Unknown",
+		},
+		{
+			testCase: "machine phase failed",
+			target: target{
+				Machine: *machineFailed,
+				Node:    maotesting.NewNode("node", true),
+			},
+			expectedUnhealthyCondition: "MachinePhaseFailed",
+		},
+		{
+			testCase: "node startup timeout",
+			target: target{
+				Machine: *machineNoNode,
+			},
+			expectedUnhealthyCondition: unhealthyConditionNodeStartupTimeout,
+		},
+		{
+			testCase: "node not found",
+			target: target{
+				Machine: *maotesting.NewMachine("machine", "node"),
+				Node:    &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}},
+			},
+			expectedUnhealthyCondition: unhealthyConditionNodeNotFound,
+		},
+		{
+			testCase: "healthy",
+			target: target{
+				Machine: *maotesting.NewMachine("machine", "node"),
+				Node:    maotesting.NewNode("node", true),
+			},
+			expectedUnhealthyCondition: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			tc.target.MHC = *maotesting.NewMachineHealthCheck("mhc")
+			_, unhealthyCondition, _, err := tc.target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: defaultNodeStartupTimeout})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if unhealthyCondition != tc.expectedUnhealthyCondition {
+				t.Errorf("Expected unhealthy condition %q, got %q", tc.expectedUnhealthyCondition, unhealthyCondition)
+			}
+		})
+	}
+}
+
+func TestReconcileReportsUnhealthyMachines(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("unhealthyMachines")
+
+	nodeNotReady := maotesting.NewNode("nodeNotReady", false)
+	machineNotReady := maotesting.NewMachine("machineNotReady", nodeNotReady.Name)
+
+	nodeReady := maotesting.NewNode("nodeReady", true)
+	machineFailed := maotesting.NewMachine("machineFailed", nodeReady.Name)
+	machineFailed.Status.Phase = pointer.StringPtr(machinePhaseFailed)
+
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), mhc, nodeNotReady, machineNotReady, nodeReady, machineFailed)
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	unhealthyMachines := func() map[string]string {
+		// the series of other tests are collected as well, do not block on a full buffer
+		ch := make(chan prometheus.Metric)
+		go func() {
+			metrics.MachineUnhealthy.Collect(ch)
+			close(ch)
+		}()
+
+		got := map[string]string{}
+		for metric := range ch {
+			m := &dto.Metric{}
+			if err := metric.Write(m); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			labels := map[string]string{}
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["machinehealthcheck"] == mhc.Name {
+				got[labels["name"]] = labels["condition"]
+			}
+		}
+		return got
+	}
+
+	expected := map[string]string{
+		machineNotReady.Name: "Ready=Unknown",
+		machineFailed.Name:   "MachinePhaseFailed",
+	}
+	if got := unhealthyMachines(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected unhealthy machines %v, got %v", expected, got)
+	}
+
+	// Series are removed once the MHC is deleted
+	if err := r.client.Delete(ctx, mhc); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := unhealthyMachines(); len(got) != 0 {
+		t.Errorf("Expected no unhealthy machines, got %v", got)
+	}
+}
+
+func TestReconcileReportsNextCheck(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("nextCheck")
+
+	// The node went NotReady 100s ago, the MHC timeout is 300s
+	node := maotesting.NewNode("node", false)
+	node.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-100 * time.Second))
+	machine := maotesting.NewMachine("fakeMachine", node.Name)
+
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), mhc, node, machine)
+	result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.RequeueAfter < 200*time.Second || result.RequeueAfter > 201*time.Second {
+		t.Errorf("Expected a requeue after about 201s, got %v", result.RequeueAfter)
+	}
+
+	gauge, err := metrics.MachineHealthCheckNextCheckSeconds.GetMetricWithLabelValues(mhc.Name, mhc.Namespace)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	m := &dto.Metric{}
+	if err := gauge.(prometheus.Metric).Write(m); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := m.GetGauge().GetValue(); got != result.RequeueAfter.Seconds() {
+		t.Errorf("Expected next check of %vs, got %vs", result.RequeueAfter.Seconds(), got)
+	}
+}
+
+func TestReconcileReportsTargetsEvaluated(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("targetsEvaluated")
+	objects := []runtime.Object{mhc}
+	for _, name := range []string{"machine1", "machine2"} {
+		node := maotesting.NewNode(name+"-node", true)
+		machine := maotesting.NewMachine(name, node.Name)
+		node.Annotations[machineAnnotationKey] = fmt.Sprintf("%s/%s", machine.Namespace, machine.Name)
+		objects = append(objects, machine, node)
+	}
+
+	observer, err := metrics.MachineHealthCheckEvaluationDurationSeconds.GetMetricWithLabelValues(mhc.Name, mhc.Namespace)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	evaluations := func() uint64 {
+		m := &dto.Metric{}
+		if err := observer.(prometheus.Metric).Write(m); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return m.GetHistogram().GetSampleCount()
+	}
+	before := evaluations()
+
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), objects...)
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	gauge, err := metrics.MachineHealthCheckTargetsEvaluated.GetMetricWithLabelValues(mhc.Name, mhc.Namespace)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	m := &dto.Metric{}
+	if err := gauge.(prometheus.Metric).Write(m); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := m.GetGauge().GetValue(); got != 2 {
+		t.Errorf("Expected 2 targets evaluated, got %v", got)
+	}
+	if got := evaluations() - before; got != 1 {
+		t.Errorf("Expected 1 evaluation duration observation, got %d", got)
+	}
+}
+
+func TestReconcileReportsUnremediatableMachines(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("unremediatable")
+
+	ownedNode := maotesting.NewNode("owned-node", true)
+	owned := maotesting.NewMachine("owned", ownedNode.Name)
+
+	noOwnerNode := maotesting.NewNode("no-owner-node", true)
+	noOwner := maotesting.NewMachine("no-owner", noOwnerNode.Name)
+	noOwner.OwnerReferences = nil
+
+	masterNode := maotesting.NewNode("master-node", true)
+	master := maotesting.NewMachine("master", masterNode.Name)
+	master.Labels[machineRoleLabel] = machineMasterRole
+
+	protectedNode := maotesting.NewNode("protected-node", true)
+	protected := maotesting.NewMachine("protected", protectedNode.Name)
+	protected.Labels[machineRoleLabel] = "infra"
+
+	objects := []runtime.Object{mhc}
+	for _, pair := range []struct {
+		machine *mapiv1beta1.Machine
+		node    *corev1.Node
+	}{{owned, ownedNode}, {noOwner, noOwnerNode}, {master, masterNode}, {protected, protectedNode}} {
+		pair.node.Annotations[machineAnnotationKey] = fmt.Sprintf("%s/%s", pair.machine.Namespace, pair.machine.Name)
+		objects = append(objects, pair.machine, pair.node)
+	}
+
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), objects...)
+	r.protectedRoles = []string{"infra"}
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, reason := range []string{metrics.UnremediatableReasonNoOwner, metrics.UnremediatableReasonMaster, metrics.UnremediatableReasonProtected} {
+		gauge, err := metrics.MachinesUnremediatableCount.GetMetricWithLabelValues(mhc.Name, mhc.Namespace, reason)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		m := &dto.Metric{}
+		if err := gauge.(prometheus.Metric).Write(m); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := m.GetGauge().GetValue(); got != 1 {
+			t.Errorf("Expected 1 unremediatable machine with reason %s, got %v", reason, got)
+		}
+	}
+}
+
+func TestReconcileReportsConfig(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("config")
+	maxUnhealthy := intstr.FromString("40%")
+	mhc.Spec.MaxUnhealthy = &maxUnhealthy
+	mhc.Spec.NodeStartupTimeout = metav1.Duration{Duration: 15 * time.Minute}
+
+	// configSeries returns the labels of the config series reported for the MHC
+	configSeries := func() []map[string]string {
+		ch := make(chan prometheus.Metric, 100)
+		metrics.MachineHealthCheckConfigInfo.Collect(ch)
+		close(ch)
+
+		var series []map[string]string
+		for metric := range ch {
+			m := &dto.Metric{}
+			if err := metric.Write(m); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			labels := map[string]string{}
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["name"] != mhc.Name || labels["namespace"] != mhc.Namespace {
+				continue
+			}
+			if got := m.GetGauge().GetValue(); got != 1 {
+				t.Errorf("Expected config info value 1, got %v", got)
+			}
+			series = append(series, labels)
+		}
+		return series
+	}
+
+	r := newFakeReconciler(mhc)
+	reconcileMHC := func() {
+		if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	reconcileMHC()
+	expected := []map[string]string{{
+		"name":                 mhc.Name,
+		"namespace":            mhc.Namespace,
+		"max_unhealthy":        "40%",
+		"unhealthy_conditions": "2",
+		"node_startup_timeout": "15m0s",
+	}}
+	if got := configSeries(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected config series %v, got %v", expected, got)
+	}
+
+	// the series of the previous configuration is replaced
+	got := &mapiv1beta1.MachineHealthCheck{}
+	if err := r.client.Get(ctx, namespacedName(mhc), got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got.Spec.MaxUnhealthy = nil
+	if err := r.client.Update(ctx, got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reconcileMHC()
+	expected[0]["max_unhealthy"] = "100%"
+	if got := configSeries(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected config series %v, got %v", expected, got)
+	}
+
+	// the series is removed with the MHC
+	if err := r.client.Delete(ctx, got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reconcileMHC()
+	if got := configSeries(); len(got) != 0 {
+		t.Errorf("Expected no config series, got %v", got)
+	}
+}
+
 func TestMinDuration(t *testing.T) {
 	testCases := []struct {
 		testCase  string
@@ -1998,9 +3827,13 @@ func TestRemediate(t *testing.T) {
 					TypeMeta: metav1.TypeMeta{
 						Kind: "Node",
 					},
-					Status: corev1.NodeStatus{},
+					Status: corev1.NodeStatus{},
+				},
+				MHC: mapiv1beta1.MachineHealthCheck{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{allowControlPlaneDeletionAnnotation: ""},
+					},
 				},
-				MHC: mapiv1beta1.MachineHealthCheck{},
 			},
 			deletion:       true,
 			expectedError:  false,
@@ -2032,7 +3865,11 @@ func TestRemediate(t *testing.T) {
 					Status: mapiv1beta1.MachineStatus{},
 				},
 				Node: &corev1.Node{},
-				MHC:  mapiv1beta1.MachineHealthCheck{},
+				MHC: mapiv1beta1.MachineHealthCheck{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{allowControlPlaneDeletionAnnotation: ""},
+					},
+				},
 			},
 			deletion:       true,
 			expectedError:  false,
@@ -2070,6 +3907,468 @@ func TestRemediate(t *testing.T) {
 	}
 }
 
+func TestRemediatePreTerminateHook(t *testing.T) {
+	hook := preTerminateHookAnnotationPrefix + "/backup"
+	testCases := []struct {
+		testCase       string
+		annotations    map[string]string
+		expectedHeld   bool
+		expectedEvents []string
+	}{
+		{
+			testCase:       "no hook",
+			annotations:    map[string]string{},
+			expectedHeld:   false,
+			expectedEvents: []string{EventMachineDeleted},
+		},
+		{
+			testCase:       "hook without expiry",
+			annotations:    map[string]string{hook: ""},
+			expectedHeld:   true,
+			expectedEvents: []string{EventRemediationHeld},
+		},
+		{
+			testCase:       "unexpired hook",
+			annotations:    map[string]string{hook: time.Now().Add(time.Hour).Format(time.RFC3339)},
+			expectedHeld:   true,
+			expectedEvents: []string{EventRemediationHeld},
+		},
+		{
+			testCase:       "expired hook",
+			annotations:    map[string]string{hook: time.Now().Add(-time.Hour).Format(time.RFC3339)},
+			expectedHeld:   false,
+			expectedEvents: []string{EventMachineDeleted},
+		},
+		{
+			testCase:       "pre-drain hook",
+			annotations:    map[string]string{"pre-drain.delete.hook.machine.cluster.x-k8s.io/backup": ""},
+			expectedHeld:   false,
+			expectedEvents: []string{EventMachineDeleted},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			machine := maotesting.NewMachine("machine", "node")
+			machine.Annotations = tc.annotations
+			target := target{
+				Machine: *machine,
+				Node:    maotesting.NewNode("node", false),
+				MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+			}
+
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder, machine)
+			err := target.remediate(r)
+
+			var heldErr *remediationHeldError
+			if held := errors.As(err, &heldErr); held != tc.expectedHeld {
+				t.Errorf("Expected held: %t, got error: %v", tc.expectedHeld, err)
+			}
+			if !tc.expectedHeld && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+
+			err = r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{})
+			if tc.expectedHeld && err != nil {
+				t.Errorf("Expected held machine to exist, got: %v", err)
+			}
+			if !tc.expectedHeld && !apierrors.IsNotFound(err) {
+				t.Errorf("Expected machine to be deleted, got: %v", err)
+			}
+		})
+	}
+}
+
+// deleteRecordingClient records the options of all Delete calls
+type deleteRecordingClient struct {
+	client.Client
+	deleteOptions []*client.DeleteOptions
+}
+
+func (c *deleteRecordingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.deleteOptions = append(c.deleteOptions, (&client.DeleteOptions{}).ApplyOptions(opts))
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func TestRemediateDeletePropagationPolicy(t *testing.T) {
+	foreground := metav1.DeletePropagationForeground
+	testCases := []struct {
+		testCase       string
+		policy         *metav1.DeletionPropagation
+		expectedPolicy *metav1.DeletionPropagation
+	}{
+		{
+			testCase:       "default policy",
+			policy:         nil,
+			expectedPolicy: nil,
+		},
+		{
+			testCase:       "foreground policy",
+			policy:         &foreground,
+			expectedPolicy: &foreground,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			machine := maotesting.NewMachine("machine", "node")
+			target := target{
+				Machine: *machine,
+				Node:    maotesting.NewNode("node", false),
+				MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+			}
+
+			r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(2), machine)
+			recordingClient := &deleteRecordingClient{Client: r.client}
+			r.client = recordingClient
+			r.deletePropagationPolicy = tc.policy
+			if err := target.remediate(r); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(recordingClient.deleteOptions) != 1 {
+				t.Fatalf("Expected 1 deletion, got %d", len(recordingClient.deleteOptions))
+			}
+			if got := recordingClient.deleteOptions[0].PropagationPolicy; !reflect.DeepEqual(got, tc.expectedPolicy) {
+				t.Errorf("Expected propagation policy %v, got %v", tc.expectedPolicy, got)
+			}
+		})
+	}
+}
+
+func TestParseDeletePropagationPolicy(t *testing.T) {
+	background := metav1.DeletePropagationBackground
+	testCases := []struct {
+		policy        string
+		expected      *metav1.DeletionPropagation
+		expectedError bool
+	}{
+		{policy: "", expected: nil},
+		{policy: "Background", expected: &background},
+		{policy: "background", expectedError: true},
+		{policy: "Invalid", expectedError: true},
+	}
+	for _, tc := range testCases {
+		got, err := parseDeletePropagationPolicy(tc.policy)
+		if (err != nil) != tc.expectedError {
+			t.Errorf("Policy %q: expected error: %t, got: %v", tc.policy, tc.expectedError, err)
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Policy %q: expected %v, got %v", tc.policy, tc.expected, got)
+		}
+	}
+}
+
+func TestParseMasterLabels(t *testing.T) {
+	testCases := []struct {
+		masterLabels  []string
+		expected      []string
+		expectedError bool
+	}{
+		{masterLabels: nil, expected: nil},
+		{masterLabels: []string{"node-role.kubernetes.io/control"}, expected: []string{"node-role.kubernetes.io/control"}},
+		{masterLabels: []string{"role=control,tier=core", "!worker"}, expected: []string{"role=control,tier=core", "!worker"}},
+		{masterLabels: []string{"role in ("}, expectedError: true},
+	}
+	for _, tc := range testCases {
+		got, err := parseMasterLabels(tc.masterLabels)
+		if (err != nil) != tc.expectedError {
+			t.Errorf("Master labels %q: expected error: %t, got: %v", tc.masterLabels, tc.expectedError, err)
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Master labels %q: expected %v, got %v", tc.masterLabels, tc.expected, got)
+		}
+	}
+
+	// configured master labels replace the default ones
+	r := newFakeReconciler()
+	r.masterLabels = []string{"node-role.kubernetes.io/control"}
+	target := target{Machine: *maotesting.NewMachine("machine", "node"), Node: maotesting.NewNode("node", true)}
+	target.Node.Labels[nodeMasterLabel] = ""
+	if target.isMaster(r.getMasterLabels()) {
+		t.Errorf("Expected the default master labels not to apply")
+	}
+	target.Node.Labels["node-role.kubernetes.io/control"] = ""
+	if !target.isMaster(r.getMasterLabels()) {
+		t.Errorf("Expected the configured master labels to apply")
+	}
+}
+
+func TestRemediateProtectedRole(t *testing.T) {
+	testCases := []struct {
+		testCase        string
+		role            string
+		protectedRoles  []string
+		expectedDeleted bool
+		expectedEvents  []string
+	}{
+		{
+			testCase:        "infra role protected",
+			role:            "infra",
+			protectedRoles:  []string{"infra"},
+			expectedDeleted: false,
+			expectedEvents:  []string{EventSkippedProtectedRole},
+		},
+		{
+			testCase:        "worker role not protected",
+			role:            "worker",
+			protectedRoles:  []string{"infra"},
+			expectedDeleted: true,
+			expectedEvents:  []string{EventMachineDeleted},
+		},
+		{
+			testCase:        "no protected roles",
+			role:            "infra",
+			protectedRoles:  nil,
+			expectedDeleted: true,
+			expectedEvents:  []string{EventMachineDeleted},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			machine := maotesting.NewMachine("machine", "node")
+			machine.Labels[machineRoleLabel] = tc.role
+			target := target{
+				Machine: *machine,
+				Node:    maotesting.NewNode("node", false),
+				MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+			}
+
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder, machine)
+			r.protectedRoles = tc.protectedRoles
+			target.SkipReason = r.skipReason(&target)
+			if err := target.remediate(r); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+
+			err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{})
+			if deleted := apierrors.IsNotFound(err); deleted != tc.expectedDeleted {
+				t.Errorf("Expected deleted: %t, got: %v", tc.expectedDeleted, err)
+			}
+		})
+	}
+}
+
+func TestReconcileRequeuesHeldRemediation(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("mhc")
+	node := maotesting.NewNode("node", false)
+	machine := maotesting.NewMachine("machine", node.Name)
+	machine.Annotations[preTerminateHookAnnotationPrefix+"/backup"] = ""
+
+	recorder := record.NewFakeRecorder(2)
+	r := newFakeReconcilerWithCustomRecorder(recorder, mhc, node, machine)
+	result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.RequeueAfter != remediationHeldRequeue {
+		t.Errorf("Expected requeue after %v, got %v", remediationHeldRequeue, result.RequeueAfter)
+	}
+	assertEvents(t, "held remediation", []string{EventRemediationHeld}, recorder.Events)
+}
+
+func TestObserveTimeToRemediate(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("timeToRemediate")
+	node := maotesting.NewNode("node", false)
+	// The node condition exceeded its 5m timeout 30 seconds ago
+	node.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-(5*time.Minute + 30*time.Second)))
+	machine := maotesting.NewMachine("machine", node.Name)
+	target := target{
+		Machine: *machine,
+		Node:    node,
+		MHC:     *mhc,
+	}
+
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(2), machine)
+	if err := target.remediate(r); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	observer, err := metrics.MachineHealthCheckTimeToRemediateSeconds.GetMetricWithLabelValues(mhc.Name, mhc.Namespace)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	metric := &dto.Metric{}
+	if err := observer.(prometheus.Metric).Write(metric); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	histogram := metric.GetHistogram()
+	if histogram.GetSampleCount() != 1 {
+		t.Fatalf("Expected 1 observation, got %d", histogram.GetSampleCount())
+	}
+	if sum := histogram.GetSampleSum(); sum < 30 || sum > 40 {
+		t.Errorf("Expected an observation of roughly 30 seconds, got %v", sum)
+	}
+}
+
+func TestObserveUnhealthyDurationAtRemediation(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("unhealthyDurationAtRemediation")
+	node := maotesting.NewNode("node", false)
+	// The node has been unhealthy for 2 hours, way beyond its 5m timeout
+	node.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	machine := maotesting.NewMachine("machine", node.Name)
+	target := target{
+		Machine: *machine,
+		Node:    node,
+		MHC:     *mhc,
+	}
+
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(2), machine)
+	if err := target.remediate(r); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	observer, err := metrics.MachineHealthCheckUnhealthyDurationAtRemediationSeconds.GetMetricWithLabelValues(mhc.Name, mhc.Namespace)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	metric := &dto.Metric{}
+	if err := observer.(prometheus.Metric).Write(metric); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	histogram := metric.GetHistogram()
+	if histogram.GetSampleCount() != 1 {
+		t.Fatalf("Expected 1 observation, got %d", histogram.GetSampleCount())
+	}
+	if sum := histogram.GetSampleSum(); sum < 7200 || sum > 7210 {
+		t.Errorf("Expected an observation of roughly 2 hours, got %v", sum)
+	}
+}
+
+func TestReconcileRemediationBlocked(t *testing.T) {
+	maxUnhealthy := intstr.FromInt(0)
+	testCases := []struct {
+		testCase       string
+		mhcName        string
+		maxUnhealthy   *intstr.IntOrString
+		window         *mapiv1beta1.RemediationWindow
+		expectedReason string
+	}{
+		{
+			testCase:       "short circuited by maxUnhealthy",
+			mhcName:        "blockedMaxUnhealthy",
+			maxUnhealthy:   &maxUnhealthy,
+			expectedReason: metrics.RemediationBlockedReasonMaxUnhealthy,
+		},
+		{
+			testCase:       "outside of the remediation window",
+			mhcName:        "blockedWindow",
+			window:         &mapiv1beta1.RemediationWindow{Start: "01:00", End: "02:00"},
+			expectedReason: metrics.RemediationBlockedReasonWindow,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck(tc.mhcName)
+			mhc.Spec.MaxUnhealthy = tc.maxUnhealthy
+			mhc.Spec.RemediationWindow = tc.window
+			nodeHealthy := maotesting.NewNode("healthy", true)
+			nodeUnhealthy := maotesting.NewNode("unhealthy", false)
+			machineHealthy := maotesting.NewMachine("healthy", nodeHealthy.Name)
+			machineUnhealthy := maotesting.NewMachine("unhealthy", nodeUnhealthy.Name)
+
+			r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), mhc, nodeHealthy, nodeUnhealthy, machineHealthy, machineUnhealthy)
+			r.clock = clock.NewFakeClock(time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC))
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if err := r.client.Get(ctx, namespacedName(machineUnhealthy), &mapiv1beta1.Machine{}); err != nil {
+				t.Errorf("Expected the unhealthy machine not to be remediated: %v", err)
+			}
+			for _, reason := range []string{
+				metrics.RemediationBlockedReasonMaxUnhealthy,
+				metrics.RemediationBlockedReasonRateLimit,
+				metrics.RemediationBlockedReasonMachineSetBudget,
+				metrics.RemediationBlockedReasonZoneBudget,
+				metrics.RemediationBlockedReasonWindow,
+				metrics.RemediationBlockedReasonControlPlaneDeletion,
+			} {
+				counter, err := metrics.MachineHealthCheckRemediationBlockedTotal.GetMetricWithLabelValues(mhc.Name, mhc.Namespace, reason)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				metric := &dto.Metric{}
+				if err := counter.(prometheus.Metric).Write(metric); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				expected := 0.0
+				if reason == tc.expectedReason {
+					expected = 1
+				}
+				if got := metric.GetCounter().GetValue(); got != expected {
+					t.Errorf("Expected %v remediations blocked with reason %q, got %v", expected, reason, got)
+				}
+			}
+		})
+	}
+}
+
+func TestUnhealthySince(t *testing.T) {
+	now := time.Now()
+	mhc := maotesting.NewMachineHealthCheck("mhc")
+
+	nodeTimedOut := maotesting.NewNode("nodeTimedOut", false)
+	nodeTimedOut.Status.Conditions[0].LastTransitionTime = metav1.NewTime(now.Add(-10 * time.Minute))
+
+	nodeNotTimedOut := maotesting.NewNode("nodeNotTimedOut", false)
+	nodeNotTimedOut.Status.Conditions[0].LastTransitionTime = metav1.NewTime(now.Add(-time.Minute))
+
+	testCases := []struct {
+		testCase      string
+		node          *corev1.Node
+		expectedOK    bool
+		expectedSince time.Time
+	}{
+		{
+			testCase:      "condition exceeded timeout",
+			node:          nodeTimedOut,
+			expectedOK:    true,
+			expectedSince: now.Add(-5 * time.Minute),
+		},
+		{
+			testCase:   "condition within timeout",
+			node:       nodeNotTimedOut,
+			expectedOK: false,
+		},
+		{
+			testCase:   "healthy node",
+			node:       maotesting.NewNode("healthy", true),
+			expectedOK: false,
+		},
+		{
+			testCase:   "no node",
+			node:       nil,
+			expectedOK: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			target := target{
+				Machine: *maotesting.NewMachine("machine", "node"),
+				Node:    tc.node,
+				MHC:     *mhc,
+			}
+			since, ok := target.unhealthySince()
+			if ok != tc.expectedOK {
+				t.Fatalf("Expected ok: %t, got: %t", tc.expectedOK, ok)
+			}
+			if ok && !since.Equal(tc.expectedSince) {
+				t.Errorf("Expected since %v, got %v", tc.expectedSince, since)
+			}
+		})
+	}
+}
+
 func TestReconcileStatus(t *testing.T) {
 	testCases := []struct {
 		testCase            string
@@ -2144,12 +4443,12 @@ func TestReconcileStatus(t *testing.T) {
 			objects = append(objects, runtime.Object(tc.mhc))
 			r := newFakeReconciler(objects...)
 
-			mergeBase := client.MergeFrom(tc.mhc.DeepCopy())
+			base := tc.mhc.DeepCopy()
 
 			tc.mhc.Status.ExpectedMachines = &tc.totalTargets
 			tc.mhc.Status.CurrentHealthy = &tc.currentHealthy
 
-			if err := r.reconcileStatus(mergeBase, tc.mhc); err != nil {
+			if _, err := r.reconcileStatus(base, tc.mhc); err != nil {
 				t.Fatalf("Unexpected error: %v", err)
 			}
 			mhc := &mapiv1beta1.MachineHealthCheck{}
@@ -2384,6 +4683,7 @@ func TestHealthCheckTargets(t *testing.T) {
 						},
 						Status: mapiv1beta1.MachineHealthCheckStatus{},
 					},
+					UnhealthyCondition: "Ready=False",
 				},
 			},
 			nextCheckTimesLen: 0,
@@ -2515,9 +4815,9 @@ func TestHealthCheckTargets(t *testing.T) {
 		recorder := record.NewFakeRecorder(2)
 		r := newFakeReconcilerWithCustomRecorder(recorder)
 		t.Run(tc.testCase, func(t *testing.T) {
-			currentHealhty, needRemediationTargets, nextCheckTimes, errList := r.healthCheckTargets(tc.targets, tc.timeoutForMachineToHaveNode)
-			if currentHealhty != tc.currentHealthy {
-				t.Errorf("Case: %v. Got: %v, expected: %v", tc.testCase, currentHealhty, tc.currentHealthy)
+			healthyTargets, needRemediationTargets, nextCheckTimes, errList := r.healthCheckTargets(tc.targets, tc.timeoutForMachineToHaveNode)
+			if len(healthyTargets) != tc.currentHealthy {
+				t.Errorf("Case: %v. Got: %v, expected: %v", tc.testCase, len(healthyTargets), tc.currentHealthy)
 			}
 			if !equality.Semantic.DeepEqual(needRemediationTargets, tc.needRemediationTargets) {
 				t.Errorf("Case: %v. Got: %v, expected: %v", tc.testCase, needRemediationTargets, tc.needRemediationTargets)
@@ -2814,6 +5114,83 @@ func TestGetMaxUnhealthy(t *testing.T) {
 	}
 }
 
+func TestRemainingRemediationBudget(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		maxUnhealthy            *intstr.IntOrString
+		expectedMachines        int
+		currentHealthy          int
+		expectedRemainingBudget int
+		expectedErr             bool
+	}{
+		{
+			name:                    "budget untouched",
+			maxUnhealthy:            &intstr.IntOrString{Type: intstr.Int, IntVal: 3},
+			expectedMachines:        5,
+			currentHealthy:          5,
+			expectedRemainingBudget: 3,
+		},
+		{
+			name:                    "budget partially consumed",
+			maxUnhealthy:            &intstr.IntOrString{Type: intstr.String, StrVal: "60%"},
+			expectedMachines:        5,
+			currentHealthy:          4,
+			expectedRemainingBudget: 2,
+		},
+		{
+			name:                    "budget exhausted",
+			maxUnhealthy:            &intstr.IntOrString{Type: intstr.Int, IntVal: 2},
+			expectedMachines:        5,
+			currentHealthy:          3,
+			expectedRemainingBudget: 0,
+		},
+		{
+			name:                    "budget exceeded",
+			maxUnhealthy:            &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+			expectedMachines:        5,
+			currentHealthy:          2,
+			expectedRemainingBudget: 0,
+		},
+		{
+			name:                    "maxUnhealthy unset",
+			expectedMachines:        5,
+			currentHealthy:          3,
+			expectedRemainingBudget: 3,
+		},
+		{
+			name:             "invalid maxUnhealthy",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "abcdef"},
+			expectedMachines: 5,
+			currentHealthy:   5,
+			expectedErr:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mhc := &mapiv1beta1.MachineHealthCheck{
+				Spec: mapiv1beta1.MachineHealthCheckSpec{
+					MaxUnhealthy: tc.maxUnhealthy,
+				},
+				Status: mapiv1beta1.MachineHealthCheckStatus{
+					ExpectedMachines: &tc.expectedMachines,
+					CurrentHealthy:   &tc.currentHealthy,
+				},
+			}
+
+			remainingBudget, err := remainingRemediationBudget(mhc)
+			if tc.expectedErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+			g.Expect(remainingBudget).To(Equal(tc.expectedRemainingBudget))
+		})
+	}
+}
+
 func TestGetIntOrPercentValue(t *testing.T) {
 	int10 := intstr.FromInt(10)
 	percent20 := intstr.FromString("20%")
@@ -2893,3 +5270,62 @@ func TestGetIntOrPercentValue(t *testing.T) {
 func IntPtr(i int) *int {
 	return &i
 }
+
+func TestReconcileInconsistentTarget(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("inconsistent")
+	// the machine the node is annotated with is not matched by the MHC
+	other := maotesting.NewMachine("other", "")
+	other.Labels = map[string]string{"no": "match"}
+	node := maotesting.NewNode("node", false)
+	node.Annotations[machineAnnotationKey] = namespacedName(other).String()
+	machine := maotesting.NewMachine("machine", node.Name)
+
+	recorder := record.NewFakeRecorder(2)
+	r := newFakeReconcilerWithCustomRecorder(recorder, mhc, node, machine, other)
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertEvents(t, "inconsistent target", []string{}, recorder.Events)
+
+	if err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{}); err != nil {
+		t.Errorf("Expected the inconsistent target not to be remediated, got: %v", err)
+	}
+	updatedMHC := &mapiv1beta1.MachineHealthCheck{}
+	if err := r.client.Get(ctx, namespacedName(mhc), updatedMHC); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := updatedMHC.Status.ExpectedMachines; got == nil || *got != 1 {
+		t.Errorf("Expected the inconsistent target to be counted, got %v expected machines", got)
+	}
+}
+
+func TestHasConsistentNodeReferenceBadAnnotation(t *testing.T) {
+	node := maotesting.NewNode("badAnnotation", true)
+	node.Annotations[machineAnnotationKey] = "too/many/parts"
+	machine := maotesting.NewMachine("machine", node.Name)
+
+	badAnnotations := func() float64 {
+		counter, err := metrics.MachineHealthCheckBadMachineAnnotationTotal.GetMetricWithLabelValues(node.Name)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		metric := &dto.Metric{}
+		if err := counter.(prometheus.Metric).Write(metric); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return metric.GetCounter().GetValue()
+	}
+	before := badAnnotations()
+
+	r := newFakeReconciler(node, machine)
+	consistent, err := r.hasConsistentNodeReference(*machine, node)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if consistent {
+		t.Errorf("Expected a node with a bad machine annotation to be inconsistent")
+	}
+	if got := badAnnotations() - before; got != 1 {
+		t.Errorf("Expected the bad machine annotation counter to increment by 1, got %v", got)
+	}
+}
@@ -0,0 +1,110 @@
+package machinehealthcheck
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// machinesCache caches the machines matching a MachineHealthCheck selector, which saves
+// listing and copying the machines from the informer on every reconcile.
+// Entries are keyed by the MHC namespace and selector string and are dropped
+// whenever a machine event is observed for a machine matching the selector.
+type machinesCache struct {
+	lock    sync.Mutex
+	entries map[string]*machinesCacheEntry
+	// generation is incremented by every invalidation. Machines listed before an
+	// invalidation may miss the change which triggered it, so they are not stored.
+	generation uint64
+}
+
+type machinesCacheEntry struct {
+	selector labels.Selector
+	// resourceVersion is the collective resource version of the cached machines
+	resourceVersion string
+	machines        []mapiv1.Machine
+}
+
+func newMachinesCache() *machinesCache {
+	return &machinesCache{
+		entries: map[string]*machinesCacheEntry{},
+	}
+}
+
+func machinesCacheKey(namespace string, selector labels.Selector) string {
+	return namespace + "/" + selector.String()
+}
+
+// get returns a copy of the cached machines for the given namespace and selector. On a miss
+// it returns the current generation of the cache, which must be passed to set along with the
+// machines listed afterwards.
+func (c *machinesCache) get(namespace string, selector labels.Selector) ([]mapiv1.Machine, uint64, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[machinesCacheKey(namespace, selector)]
+	if !ok {
+		return nil, c.generation, false
+	}
+	klog.V(4).Infof("Using cached machines for selector %q in namespace %q at resource version %q", selector.String(), namespace, entry.resourceVersion)
+	return copyMachines(entry.machines), c.generation, true
+}
+
+// set stores a copy of the machines for the given namespace and selector, unless the cache
+// was invalidated since the given generation was returned by get
+func (c *machinesCache) set(namespace string, selector labels.Selector, machines []mapiv1.Machine, generation uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if generation != c.generation {
+		klog.V(4).Infof("Not caching machines for selector %q in namespace %q, invalidated while listing", selector.String(), namespace)
+		return
+	}
+	c.entries[machinesCacheKey(namespace, selector)] = &machinesCacheEntry{
+		selector:        selector,
+		resourceVersion: collectiveResourceVersion(machines),
+		machines:        copyMachines(machines),
+	}
+}
+
+// invalidate drops all entries whose selector matches the given machine labels
+func (c *machinesCache) invalidate(namespace string, machineLabels map[string]string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.generation++
+	for key, entry := range c.entries {
+		if !strings.HasPrefix(key, namespace+"/") {
+			continue
+		}
+		if entry.selector.Empty() || entry.selector.Matches(labels.Set(machineLabels)) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// collectiveResourceVersion combines the resource versions of all machines
+// into a single string which changes whenever any of the machines changes
+func collectiveResourceVersion(machines []mapiv1.Machine) string {
+	versions := make([]string, 0, len(machines))
+	for _, machine := range machines {
+		versions = append(versions, machine.Name+"="+machine.ResourceVersion)
+	}
+	sort.Strings(versions)
+	return strings.Join(versions, ",")
+}
+
+func copyMachines(machines []mapiv1.Machine) []mapiv1.Machine {
+	if machines == nil {
+		return nil
+	}
+	out := make([]mapiv1.Machine, len(machines))
+	for i := range machines {
+		machines[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
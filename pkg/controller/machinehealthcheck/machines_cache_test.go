@@ -0,0 +1,158 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"testing"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// listCountingClient counts the number of List calls made to the underlying client
+type listCountingClient struct {
+	client.Client
+	listCalls int
+}
+
+func (c *listCountingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.listCalls++
+	return c.Client.List(ctx, list, opts...)
+}
+
+func newFakeReconcilerWithMachinesCache(initObjects ...runtime.Object) (*ReconcileMachineHealthCheck, *listCountingClient) {
+	r := newFakeReconciler(initObjects...)
+	countingClient := &listCountingClient{Client: r.client}
+	r.client = countingClient
+	r.machinesCache = newMachinesCache()
+	return r, countingClient
+}
+
+func TestGetMachinesFromMHCCache(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("mhc")
+	machine1 := maotesting.NewMachine("machine1", "node1")
+	r, countingClient := newFakeReconcilerWithMachinesCache(mhc, machine1)
+
+	machines, err := r.getMachinesFromMHC(*mhc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(machines) != 1 {
+		t.Fatalf("Expected 1 machine, got %d", len(machines))
+	}
+
+	// A second call is served from the cache
+	if _, err := r.getMachinesFromMHC(*mhc); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if countingClient.listCalls != 1 {
+		t.Errorf("Expected 1 list call, got %d", countingClient.listCalls)
+	}
+
+	// Modifying the returned machines does not modify the cache
+	machines[0].Labels["foo"] = "baz"
+	machines, err = r.getMachinesFromMHC(*mhc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if machines[0].Labels["foo"] != "bar" {
+		t.Errorf("Expected cached machine to be unmodified")
+	}
+
+	// Creating a machine invalidates the cache through the machine event handler
+	machine2 := maotesting.NewMachine("machine2", "node2")
+	if err := r.client.Create(ctx, machine2); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	r.mhcRequestsFromMachine(machine2)
+
+	machines, err = r.getMachinesFromMHC(*mhc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(machines) != 2 {
+		t.Errorf("Expected 2 machines after invalidation, got %d", len(machines))
+	}
+}
+
+func TestMachinesCacheInvalidate(t *testing.T) {
+	matchingMHC := maotesting.NewMachineHealthCheck("matching")
+	otherMHC := maotesting.NewMachineHealthCheck("other")
+	otherMHC.Spec.Selector = *maotesting.NewSelector(map[string]string{"no": "match"})
+
+	cache := newMachinesCache()
+	for _, mhc := range []*mapiv1beta1.MachineHealthCheck{matchingMHC, otherMHC} {
+		r := newFakeReconciler()
+		machines, err := r.getMachinesFromMHC(*mhc)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&mhc.Spec.Selector)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		_, generation, _ := cache.get(mhc.Namespace, selector)
+		cache.set(mhc.Namespace, selector, machines, generation)
+	}
+
+	cache.invalidate(maotesting.Namespace, maotesting.FooBar())
+
+	matchingSelector, _ := metav1.LabelSelectorAsSelector(&matchingMHC.Spec.Selector)
+	if _, _, ok := cache.get(maotesting.Namespace, matchingSelector); ok {
+		t.Errorf("Expected entry for matching selector to be invalidated")
+	}
+	otherSelector, _ := metav1.LabelSelectorAsSelector(&otherMHC.Spec.Selector)
+	if _, _, ok := cache.get(maotesting.Namespace, otherSelector); !ok {
+		t.Errorf("Expected entry for non matching selector to be kept")
+	}
+}
+
+func TestMachinesCacheInvalidatedWhileListing(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("mhc")
+	selector, err := metav1.LabelSelectorAsSelector(&mhc.Spec.Selector)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cache := newMachinesCache()
+
+	// a machine event invalidates the cache between the miss and the list being stored
+	_, generation, _ := cache.get(mhc.Namespace, selector)
+	staleMachines := []mapiv1beta1.Machine{*maotesting.NewMachine("machine1", "node1")}
+	cache.invalidate(maotesting.Namespace, maotesting.FooBar())
+	cache.set(mhc.Namespace, selector, staleMachines, generation)
+
+	if _, _, ok := cache.get(mhc.Namespace, selector); ok {
+		t.Errorf("Expected machines listed before an invalidation not to be cached")
+	}
+}
+
+func BenchmarkGetMachinesFromMHC(b *testing.B) {
+	mhc := maotesting.NewMachineHealthCheck("mhc")
+	objects := []runtime.Object{mhc}
+	for _, name := range []string{"machine1", "machine2", "machine3"} {
+		objects = append(objects, maotesting.NewMachine(name, name))
+	}
+
+	b.Run("uncached", func(b *testing.B) {
+		r, countingClient := newFakeReconcilerWithMachinesCache(objects...)
+		r.machinesCache = nil
+		for i := 0; i < b.N; i++ {
+			if _, err := r.getMachinesFromMHC(*mhc); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(countingClient.listCalls)/float64(b.N), "lists/op")
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		r, countingClient := newFakeReconcilerWithMachinesCache(objects...)
+		for i := 0; i < b.N; i++ {
+			if _, err := r.getMachinesFromMHC(*mhc); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(countingClient.listCalls)/float64(b.N), "lists/op")
+	})
+}
@@ -0,0 +1,101 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"sort"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+)
+
+const machineSetKind = "MachineSet"
+
+// parseMachineSetMaxUnhealthy validates the given number or percentage of unhealthy
+// machines allowed per MachineSet, an empty value disables per MachineSet budgets
+func parseMachineSetMaxUnhealthy(maxUnhealthy string) (*intstr.IntOrString, error) {
+	if maxUnhealthy == "" {
+		return nil, nil
+	}
+	value := intstr.Parse(maxUnhealthy)
+	if _, _, err := getIntOrPercentValue(&value); err != nil {
+		return nil, fmt.Errorf("invalid machineset max unhealthy %q: %v", maxUnhealthy, err)
+	}
+	return &value, nil
+}
+
+// getMachineSetFromMachine returns the name of the MachineSet controlling the machine,
+// or an empty string if the machine is not controlled by a MachineSet
+func getMachineSetFromMachine(machine mapiv1.Machine) string {
+	owner := metav1.GetControllerOf(&machine)
+	if owner == nil || owner.Kind != machineSetKind {
+		return ""
+	}
+	return owner.Name
+}
+
+// filterByMachineSetBudget drops the targets needing remediation whose MachineSet
+// has more unhealthy machines than its budget allows, so that no single MachineSet
+// is drained by remediation. Machines not controlled by a MachineSet are not restricted.
+func (r *ReconcileMachineHealthCheck) filterByMachineSetBudget(mhc *mapiv1.MachineHealthCheck, targets []target, needRemediationTargets []target) []target {
+	if r.machineSetMaxUnhealthy == nil {
+		return needRemediationTargets
+	}
+
+	// machines being deleted count neither toward the total nor toward the unhealthy machines
+	total := map[string]int{}
+	for _, t := range targets {
+		if !t.isDeleting() {
+			total[getMachineSetFromMachine(t.Machine)]++
+		}
+	}
+	unhealthy := map[string]int{}
+	for _, t := range needRemediationTargets {
+		if !t.isDeleting() {
+			unhealthy[getMachineSetFromMachine(t.Machine)]++
+		}
+	}
+
+	restricted := map[string]bool{}
+	var machineSets []string
+	for machineSet := range unhealthy {
+		if machineSet != "" {
+			machineSets = append(machineSets, machineSet)
+		}
+	}
+	sort.Strings(machineSets)
+	for _, machineSet := range machineSets {
+		maxUnhealthy, err := getValueFromIntOrPercent(r.machineSetMaxUnhealthy, total[machineSet], false)
+		if err != nil {
+			// validated when building the reconciler
+			klog.Errorf("%s: error decoding machineset max unhealthy: %v", namespacedName(mhc), err)
+			continue
+		}
+		if unhealthy[machineSet] <= maxUnhealthy {
+			continue
+		}
+		klog.Warningf("%s: MachineSet %q has %d unhealthy machines out of %d, exceeding its budget of %d. Restricting remediation",
+			namespacedName(mhc), machineSet, unhealthy[machineSet], total[machineSet], maxUnhealthy)
+		r.recorder.Eventf(
+			mhc,
+			corev1.EventTypeWarning,
+			EventMachineSetRemediationRestricted,
+			"Remediation of MachineSet %s restricted due to exceeded number of unhealthy machines (total: %v, unhealthy: %v, maxUnhealthy: %v)",
+			machineSet,
+			total[machineSet],
+			unhealthy[machineSet],
+			r.machineSetMaxUnhealthy.String(),
+		)
+		restricted[machineSet] = true
+	}
+
+	var allowed []target
+	for _, t := range needRemediationTargets {
+		if t.isDeleting() || !restricted[getMachineSetFromMachine(t.Machine)] {
+			allowed = append(allowed, t)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,114 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestParseMachineSetMaxUnhealthy(t *testing.T) {
+	testCases := []struct {
+		maxUnhealthy  string
+		expected      *intstr.IntOrString
+		expectedError bool
+	}{
+		{maxUnhealthy: "", expected: nil},
+		{maxUnhealthy: "1", expected: &intstr.IntOrString{Type: intstr.Int, IntVal: 1}},
+		{maxUnhealthy: "40%", expected: &intstr.IntOrString{Type: intstr.String, StrVal: "40%"}},
+		{maxUnhealthy: "some", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.maxUnhealthy, func(t *testing.T) {
+			maxUnhealthy, err := parseMachineSetMaxUnhealthy(tc.maxUnhealthy)
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("Expected error: %t, got: %v", tc.expectedError, err)
+			}
+			if !reflect.DeepEqual(maxUnhealthy, tc.expected) {
+				t.Errorf("Expected: %v, got: %v", tc.expected, maxUnhealthy)
+			}
+		})
+	}
+}
+
+// newMachineSetMachine returns a machine controlled by the given MachineSet
+// together with its node
+func newMachineSetMachine(machineSet string, index int, ready bool) (*mapiv1beta1.Machine, *corev1.Node) {
+	node := maotesting.NewNode(fmt.Sprintf("%s-node-%d", machineSet, index), ready)
+	machine := maotesting.NewMachine(fmt.Sprintf("%s-%d", machineSet, index), node.Name)
+	machine.OwnerReferences[0].Name = machineSet
+	node.Annotations[machineAnnotationKey] = fmt.Sprintf("%s/%s", machine.Namespace, machine.Name)
+	return machine, node
+}
+
+func TestReconcileMachineSetBudget(t *testing.T) {
+	testCases := []struct {
+		testCase               string
+		machineSetMaxUnhealthy string
+		expectedDeleted        map[string]bool
+		expectedEvents         []string
+	}{
+		{
+			testCase:               "per machineset budgets disabled",
+			machineSetMaxUnhealthy: "",
+			expectedDeleted:        map[string]bool{"drained-0": true, "drained-1": true, "degraded-0": true},
+			expectedEvents:         []string{EventMachineDeleted, EventMachineDeleted, EventMachineDeleted},
+		},
+		{
+			testCase:               "machineset budget blocks remediation allowed by the mhc",
+			machineSetMaxUnhealthy: "50%",
+			expectedDeleted:        map[string]bool{"drained-0": false, "drained-1": false, "degraded-0": true},
+			expectedEvents:         []string{EventMachineSetRemediationRestricted, EventMachineDeleted},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			// maxUnhealthy of the MHC defaults to 100%, allowing all remediations
+			mhc := maotesting.NewMachineHealthCheck("budget")
+			objects := []runtime.Object{mhc}
+			for _, m := range []struct {
+				machineSet string
+				index      int
+				ready      bool
+			}{
+				{machineSet: "drained", index: 0, ready: false},
+				{machineSet: "drained", index: 1, ready: false},
+				{machineSet: "degraded", index: 0, ready: false},
+				{machineSet: "degraded", index: 1, ready: true},
+			} {
+				machine, node := newMachineSetMachine(m.machineSet, m.index, m.ready)
+				objects = append(objects, machine, node)
+			}
+
+			recorder := record.NewFakeRecorder(10)
+			r := newFakeReconcilerWithCustomRecorder(recorder, objects...)
+			maxUnhealthy, err := parseMachineSetMaxUnhealthy(tc.machineSetMaxUnhealthy)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			r.machineSetMaxUnhealthy = maxUnhealthy
+
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+
+			for name, expectedDeleted := range tc.expectedDeleted {
+				err := r.client.Get(ctx, namespacedName(maotesting.NewMachine(name, "")), &mapiv1beta1.Machine{})
+				if deleted := apierrors.IsNotFound(err); deleted != expectedDeleted {
+					t.Errorf("%s: expected deleted: %t, got: %v", name, expectedDeleted, err)
+				}
+			}
+		})
+	}
+}
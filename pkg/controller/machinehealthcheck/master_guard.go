@@ -0,0 +1,106 @@
+package machinehealthcheck
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// masterRemediationRequeue is the delay before retrying master remediations
+// deferred by the master guard when no cool-down is configured
+const masterRemediationRequeue = time.Minute
+
+// masterRemediationGuard records the last remediation of a master across all
+// MachineHealthChecks to enforce a cool-down between master remediations
+type masterRemediationGuard struct {
+	lock sync.Mutex
+	// cooldown is the duration after a master remediation during which no other
+	// master is remediated
+	cooldown time.Duration
+	last     time.Time
+}
+
+// record records a remediation of a master
+func (g *masterRemediationGuard) record(now time.Time) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.last = now
+}
+
+// remaining returns the remaining cool-down after the last master remediation
+func (g *masterRemediationGuard) remaining(now time.Time) time.Duration {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.last.IsZero() {
+		return 0
+	}
+	if remaining := g.cooldown - now.Sub(g.last); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// filterByMasterGuard limits the masters remediated in a single pass to one, independently of
+// any other budget, so that a misconfigured MachineHealthCheck cannot delete several masters at
+// once. A master already being deleted takes precedence, so no other master is remediated until
+// it is gone. No master is remediated until the cool-down after the previous master remediation
+// expires. The returned duration is the delay before deferred masters should be retried, zero
+// when none was deferred.
+func (r *ReconcileMachineHealthCheck) filterByMasterGuard(mhc *mapiv1.MachineHealthCheck, needRemediationTargets []target) ([]target, time.Duration) {
+	var allowed, masters []target
+	masterLabels := r.getMasterLabels()
+	for _, t := range needRemediationTargets {
+		if t.isMaster(masterLabels) {
+			masters = append(masters, t)
+			continue
+		}
+		allowed = append(allowed, t)
+	}
+	if len(masters) == 0 {
+		return allowed, 0
+	}
+
+	// pick the master in a stable order so the same masters are deferred on every pass
+	sort.SliceStable(masters, func(i, j int) bool {
+		iDeleting, jDeleting := masters[i].Machine.DeletionTimestamp != nil, masters[j].Machine.DeletionTimestamp != nil
+		if iDeleting != jDeleting {
+			return iDeleting
+		}
+		return masters[i].Machine.Name < masters[j].Machine.Name
+	})
+
+	cooldown := r.masterGuard.remaining(r.now())
+	for i, t := range masters {
+		if i == 0 && (t.Machine.DeletionTimestamp != nil || cooldown == 0) {
+			allowed = append(allowed, t)
+			continue
+		}
+		r.recorder.Eventf(
+			&t.Machine,
+			corev1.EventTypeNormal,
+			EventMasterRemediationDeferred,
+			"Machine %v is a master, deferring remediation to remediate at most one master at a time",
+			t.string(),
+		)
+		klog.Infof("%s: another master is being remediated or cooling down, deferring remediation", t.string())
+	}
+	if len(allowed) == len(needRemediationTargets) {
+		return allowed, 0
+	}
+	klog.Warningf("%s: deferred remediation of %d masters", namespacedName(mhc), len(needRemediationTargets)-len(allowed))
+
+	switch {
+	case cooldown > 0:
+		return allowed, cooldown
+	case r.masterGuard.cooldown > 0:
+		return allowed, r.masterGuard.cooldown
+	default:
+		return allowed, masterRemediationRequeue
+	}
+}
@@ -0,0 +1,121 @@
+package machinehealthcheck
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileMasterGuard(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("masters")
+	mhc.Annotations = map[string]string{allowControlPlaneDeletionAnnotation: ""}
+	objects := []runtime.Object{mhc}
+	for _, name := range []string{"master-0", "master-1"} {
+		node := maotesting.NewNode(name+"-node", false)
+		node.Labels[nodeMasterLabel] = ""
+		machine := maotesting.NewMachine(name, node.Name)
+		node.Annotations[machineAnnotationKey] = namespacedName(machine).String()
+		objects = append(objects, machine, node)
+	}
+
+	fakeClock := clock.NewFakeClock(time.Now())
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), objects...)
+	r.clock = fakeClock
+	r.masterGuard.cooldown = 10 * time.Minute
+
+	assertDeleted := func(pass string, expectedDeleted map[string]bool) {
+		for name, expected := range expectedDeleted {
+			err := r.client.Get(ctx, namespacedName(maotesting.NewMachine(name, "")), &mapiv1beta1.Machine{})
+			if deleted := apierrors.IsNotFound(err); deleted != expected {
+				t.Errorf("%s: %s: expected deleted: %t, got: %v", pass, name, expected, err)
+			}
+		}
+	}
+	reconcileMHC := func(pass string, expectedResult reconcile.Result) {
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", pass, err)
+		}
+		if result != expectedResult {
+			t.Errorf("%s: expected result %+v, got %+v", pass, expectedResult, result)
+		}
+	}
+
+	// only one of the two unhealthy masters is remediated in a pass
+	reconcileMHC("first pass", reconcile.Result{RequeueAfter: 10 * time.Minute})
+	assertDeleted("first pass", map[string]bool{"master-0": true, "master-1": false})
+
+	// the remaining master is deferred until the cool-down expires
+	fakeClock.Step(5 * time.Minute)
+	reconcileMHC("within cool-down", reconcile.Result{RequeueAfter: 5 * time.Minute})
+	assertDeleted("within cool-down", map[string]bool{"master-1": false})
+
+	fakeClock.Step(5 * time.Minute)
+	reconcileMHC("after cool-down", reconcile.Result{})
+	assertDeleted("after cool-down", map[string]bool{"master-1": true})
+}
+
+func TestFilterByMasterGuard(t *testing.T) {
+	deletionTimestamp := metav1.Now()
+	newTarget := func(name string, master, deleting bool) target {
+		machine := maotesting.NewMachine(name, "")
+		if master {
+			machine.Labels = map[string]string{machineRoleLabel: machineMasterRole}
+		}
+		if deleting {
+			machine.DeletionTimestamp = &deletionTimestamp
+		}
+		return target{Machine: *machine, MHC: *maotesting.NewMachineHealthCheck("mhc")}
+	}
+
+	testCases := []struct {
+		testCase        string
+		targets         []target
+		expectedAllowed []string
+		expectedRequeue time.Duration
+	}{
+		{
+			testCase:        "workers are not restricted",
+			targets:         []target{newTarget("worker-0", false, false), newTarget("worker-1", false, false)},
+			expectedAllowed: []string{"worker-0", "worker-1"},
+		},
+		{
+			testCase:        "one master per pass",
+			targets:         []target{newTarget("master-1", true, false), newTarget("worker-0", false, false), newTarget("master-0", true, false)},
+			expectedAllowed: []string{"worker-0", "master-0"},
+			expectedRequeue: masterRemediationRequeue,
+		},
+		{
+			testCase:        "deleting master takes precedence",
+			targets:         []target{newTarget("master-0", true, false), newTarget("master-1", true, true)},
+			expectedAllowed: []string{"master-1"},
+			expectedRequeue: masterRemediationRequeue,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10))
+			allowed, requeue := r.filterByMasterGuard(maotesting.NewMachineHealthCheck("mhc"), tc.targets)
+			var names []string
+			for _, t := range allowed {
+				names = append(names, t.Machine.Name)
+			}
+			if !reflect.DeepEqual(names, tc.expectedAllowed) {
+				t.Errorf("Expected allowed targets %v, got %v", tc.expectedAllowed, names)
+			}
+			if requeue != tc.expectedRequeue {
+				t.Errorf("Expected requeue %v, got %v", tc.expectedRequeue, requeue)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// firstUnhealthyAnnotation records the time a machine was first found with an unhealthy node
+	// condition whose timeout was not exceeded yet. It survives the node flipping back to healthy
+	// in between checks, so that a node oscillating just under the condition timeouts is eventually
+	// remediated, and it is only removed once the node stayed healthy for the maximum deferral.
+	firstUnhealthyAnnotation = "machine.openshift.io/first-unhealthy-at"
+
+	// unhealthyConditionMaxDeferralExceeded is reported for targets remediated because they
+	// were deferred for longer than the maximum deferral
+	unhealthyConditionMaxDeferralExceeded = "MaxDeferralExceeded"
+)
+
+// hasUnhealthyCondition returns true if a node condition of the target is in one of the
+// states the MHC considers unhealthy, regardless of the condition timeout
+func (t *target) hasUnhealthyCondition() bool {
+	if t.Node == nil || t.Node.UID == "" {
+		return false
+	}
+	for _, c := range t.unhealthyConditions() {
+		nodeCondition := conditions.GetNodeCondition(t.Node, c.Type)
+		if nodeCondition != nil && nodeCondition.Status == c.Status {
+			return true
+		}
+	}
+	return false
+}
+
+// stableHealthySince returns the time of the last transition of the node conditions
+// evaluated by the MHC, the node is stably healthy since then
+func (t *target) stableHealthySince() time.Time {
+	var since time.Time
+	for _, c := range t.unhealthyConditions() {
+		nodeCondition := conditions.GetNodeCondition(t.Node, c.Type)
+		if nodeCondition != nil && nodeCondition.LastTransitionTime.After(since) {
+			since = nodeCondition.LastTransitionTime.Time
+		}
+	}
+	return since
+}
+
+// checkMaxDeferral returns whether the target, which is unhealthy but within its condition timeouts,
+// has been deferred for longer than the maximum deferral, or the duration after which it will be.
+// The first time the target is deferred is recorded on its machine.
+func (t *target) checkMaxDeferral(r *ReconcileMachineHealthCheck) (bool, time.Duration, error) {
+	now := r.now()
+	firstUnhealthy := now
+	if value, ok := t.Machine.Annotations[firstUnhealthyAnnotation]; ok {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err == nil {
+			firstUnhealthy = parsed
+		} else {
+			klog.Warningf("%s: resetting invalid %s annotation %q: %v", t.string(), firstUnhealthyAnnotation, value, err)
+		}
+	}
+	if !firstUnhealthy.Before(now) {
+		if err := t.setFirstUnhealthy(r, now.UTC().Format(time.RFC3339)); err != nil {
+			return false, 0, err
+		}
+	}
+
+	deferred := elapsedSince(firstUnhealthy, now)
+	if deferred > r.maxUnhealthyDeferral {
+		klog.V(3).Infof("%s: unhealthy: deferred for %v, longer than %v", t.string(), deferred, r.maxUnhealthyDeferral)
+		return true, 0, nil
+	}
+	return false, r.maxUnhealthyDeferral - deferred + time.Second, nil
+}
+
+// forgetMaxDeferral removes the first unhealthy time from the machine of a healthy target,
+// once its node stayed healthy for the maximum deferral
+func (t *target) forgetMaxDeferral(r *ReconcileMachineHealthCheck) error {
+	if _, ok := t.Machine.Annotations[firstUnhealthyAnnotation]; !ok {
+		return nil
+	}
+	if t.Node != nil && elapsedSince(t.stableHealthySince(), r.now()) < r.maxUnhealthyDeferral {
+		return nil
+	}
+	return t.setFirstUnhealthy(r, "")
+}
+
+// setFirstUnhealthy sets the first unhealthy annotation of the machine of the target,
+// the annotation is removed when value is empty
+func (t *target) setFirstUnhealthy(r *ReconcileMachineHealthCheck, value string) error {
+	mergeBase := client.MergeFrom(t.Machine.DeepCopy())
+	if value == "" {
+		delete(t.Machine.Annotations, firstUnhealthyAnnotation)
+	} else {
+		if t.Machine.Annotations == nil {
+			t.Machine.Annotations = map[string]string{}
+		}
+		t.Machine.Annotations[firstUnhealthyAnnotation] = value
+	}
+	if err := r.client.Patch(context.TODO(), &t.Machine, mergeBase); err != nil {
+		return fmt.Errorf("%s: failed to record first unhealthy time: %v", t.string(), err)
+	}
+	return nil
+}
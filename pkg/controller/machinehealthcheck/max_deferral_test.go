@@ -0,0 +1,115 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileMaxUnhealthyDeferral(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("deferral")
+	node := maotesting.NewNode("node", false)
+	machine := maotesting.NewMachine("machine", node.Name)
+	node.Annotations[machineAnnotationKey] = namespacedName(machine).String()
+
+	fakeClock := clock.NewFakeClock(time.Now())
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), mhc, machine, node)
+	r.clock = fakeClock
+	r.maxUnhealthyDeferral = 15 * time.Minute
+
+	// the node keeps going healthy and unhealthy again just under the 5m timeout
+	oscillate := func() {
+		n := &corev1.Node{}
+		if err := r.client.Get(ctx, namespacedName(node), n); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		n.Status.Conditions[0].LastTransitionTime = metav1.NewTime(fakeClock.Now().Add(-4 * time.Minute))
+		if err := r.client.Update(ctx, n); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		oscillate()
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.RequeueAfter <= 0 {
+			t.Errorf("Pass %d: expected requeue, got %+v", i, result)
+		}
+		m := &mapiv1beta1.Machine{}
+		if err := r.client.Get(ctx, namespacedName(machine), m); err != nil {
+			t.Fatalf("Pass %d: expected machine not to be remediated yet: %v", i, err)
+		}
+		if _, ok := m.Annotations[firstUnhealthyAnnotation]; !ok {
+			t.Errorf("Pass %d: expected machine to have %s annotation", i, firstUnhealthyAnnotation)
+		}
+		fakeClock.Step(6 * time.Minute)
+	}
+
+	// deferred for 18m, beyond the maximum deferral of 15m
+	oscillate()
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected machine to be remediated after the maximum deferral, got: %v", err)
+	}
+}
+
+func TestForgetMaxDeferral(t *testing.T) {
+	testCases := []struct {
+		testCase           string
+		healthySince       time.Duration
+		expectedAnnotation bool
+	}{
+		{
+			testCase:           "recently healthy",
+			healthySince:       time.Minute,
+			expectedAnnotation: true,
+		},
+		{
+			testCase:           "healthy for longer than the maximum deferral",
+			healthySince:       time.Hour,
+			expectedAnnotation: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			node := maotesting.NewNode("node", true)
+			node.UID = "uid"
+			node.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-tc.healthySince))
+			machine := maotesting.NewMachine("machine", node.Name)
+			machine.Annotations[firstUnhealthyAnnotation] = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+			target := target{
+				Machine: *machine,
+				Node:    node,
+				MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+			}
+
+			r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(1), machine)
+			r.maxUnhealthyDeferral = 15 * time.Minute
+			if err := target.forgetMaxDeferral(r); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			m := &mapiv1beta1.Machine{}
+			if err := r.client.Get(ctx, namespacedName(machine), m); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, ok := m.Annotations[firstUnhealthyAnnotation]; ok != tc.expectedAnnotation {
+				t.Errorf("Expected %s annotation: %t, got: %t", firstUnhealthyAnnotation, tc.expectedAnnotation, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// maxUnhealthyPreview describes how a MachineHealthCheck would treat its current targets
+// with a hypothetical maxUnhealthy value
+type maxUnhealthyPreview struct {
+	// expectedMachines and currentHealthy are the counts reported in the MHC status
+	expectedMachines int
+	currentHealthy   int
+	// maxUnhealthy is the number of unhealthy machines allowed by the hypothetical value
+	maxUnhealthy int
+	// remediated is the number of targets which would be remediated right now
+	remediated int
+	// blocked is the number of targets needing remediation which would be short-circuited
+	blocked int
+}
+
+// previewMaxUnhealthy evaluates the current targets of the MHC against a hypothetical maxUnhealthy
+// value, without acting on them: no target is remediated, no event is emitted and neither the
+// machines nor the MHC are updated. Only maxUnhealthy is previewed, the other remediation budgets
+// and the remediation window are not taken into account.
+func (r *ReconcileMachineHealthCheck) previewMaxUnhealthy(mhc *mapiv1.MachineHealthCheck, maxUnhealthy intstr.IntOrString) (maxUnhealthyPreview, error) {
+	targets, err := r.getTargetsFromMHC(*mhc)
+	if err != nil {
+		return maxUnhealthyPreview{}, err
+	}
+
+	preview := maxUnhealthyPreview{expectedMachines: expectedTargetCount(targets)}
+	var needRemediation int
+	for _, t := range targets {
+		needsRemediation, _, nextCheck, err := t.needsRemediation(r.healthCheckTimeouts(mhc.Spec.NodeStartupTimeout.Duration))
+		if err != nil {
+			return maxUnhealthyPreview{}, fmt.Errorf("%s: error health checking: %v", t.string(), err)
+		}
+		switch {
+		case needsRemediation:
+			// machines being deleted are not remediated again
+			if !t.isDeleting() {
+				needRemediation++
+			}
+		case nextCheck == 0 && !t.isRemediating():
+			preview.currentHealthy++
+		}
+	}
+
+	hypothetical := mhc.DeepCopy()
+	hypothetical.Spec.MaxUnhealthy = &maxUnhealthy
+	hypothetical.Status.ExpectedMachines = &preview.expectedMachines
+	hypothetical.Status.CurrentHealthy = &preview.currentHealthy
+	if preview.maxUnhealthy, err = getMaxUnhealthy(hypothetical); err != nil {
+		return maxUnhealthyPreview{}, fmt.Errorf("failed to get value for maxUnhealthy: %v", err)
+	}
+	if isAllowedRemediation(hypothetical) {
+		preview.remediated = needRemediation
+	} else {
+		preview.blocked = needRemediation
+	}
+	return preview, nil
+}
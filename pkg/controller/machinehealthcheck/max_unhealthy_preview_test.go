@@ -0,0 +1,90 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"testing"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestPreviewMaxUnhealthy(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("preview")
+	objects := []runtime.Object{mhc}
+	var machines []*mapiv1beta1.Machine
+	// 2 healthy and 3 unhealthy targets
+	for i, ready := range []bool{true, true, false, false, false} {
+		node := maotesting.NewNode(fmt.Sprintf("node%d", i), ready)
+		machine := maotesting.NewMachine(fmt.Sprintf("machine%d", i), node.Name)
+		node.Annotations[machineAnnotationKey] = namespacedName(machine).String()
+		machines = append(machines, machine)
+		objects = append(objects, node, machine)
+	}
+
+	testCases := []struct {
+		maxUnhealthy         intstr.IntOrString
+		expectedMaxUnhealthy int
+		expectedRemediated   int
+		expectedBlocked      int
+	}{
+		{
+			maxUnhealthy:         intstr.FromInt(0),
+			expectedMaxUnhealthy: 0,
+			expectedBlocked:      3,
+		},
+		{
+			maxUnhealthy:         intstr.FromInt(2),
+			expectedMaxUnhealthy: 2,
+			expectedBlocked:      3,
+		},
+		{
+			maxUnhealthy:         intstr.FromInt(3),
+			expectedMaxUnhealthy: 3,
+			expectedRemediated:   3,
+		},
+		{
+			maxUnhealthy:         intstr.FromString("40%"),
+			expectedMaxUnhealthy: 2,
+			expectedBlocked:      3,
+		},
+		{
+			maxUnhealthy:         intstr.FromString("60%"),
+			expectedMaxUnhealthy: 3,
+			expectedRemediated:   3,
+		},
+		{
+			maxUnhealthy:         intstr.FromString("100%"),
+			expectedMaxUnhealthy: 5,
+			expectedRemediated:   3,
+		},
+	}
+
+	r := newFakeReconciler(objects...)
+	for _, tc := range testCases {
+		t.Run(tc.maxUnhealthy.String(), func(t *testing.T) {
+			preview, err := r.previewMaxUnhealthy(mhc, tc.maxUnhealthy)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expected := maxUnhealthyPreview{
+				expectedMachines: 5,
+				currentHealthy:   2,
+				maxUnhealthy:     tc.expectedMaxUnhealthy,
+				remediated:       tc.expectedRemediated,
+				blocked:          tc.expectedBlocked,
+			}
+			if preview != expected {
+				t.Errorf("Expected preview %+v, got %+v", expected, preview)
+			}
+		})
+	}
+
+	// the preview does not act on the targets
+	for _, machine := range machines {
+		if err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{}); err != nil {
+			t.Errorf("Expected machine %s not to be remediated: %v", machine.Name, err)
+		}
+	}
+}
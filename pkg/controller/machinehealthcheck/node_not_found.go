@@ -0,0 +1,39 @@
+package machinehealthcheck
+
+import (
+	"sync"
+	"time"
+)
+
+// missingNodeTracker records when the node referenced by a machine was first found missing.
+// The deletion time of a node is not known once it is gone, so the node not found grace
+// period is measured from the first reconcile which observed it missing.
+type missingNodeTracker struct {
+	lock sync.Mutex
+	// since maps targets to the time their node was first found missing
+	since map[string]time.Time
+}
+
+// observe records the node of the given target as missing and returns the time it was first found missing
+func (m *missingNodeTracker) observe(key string, now time.Time) time.Time {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.since == nil {
+		m.since = map[string]time.Time{}
+	}
+	if since, ok := m.since[key]; ok {
+		return since
+	}
+	m.since[key] = now
+	return now
+}
+
+// forget removes the record of the node of the given target, e.g. when the node was found again
+// or the machine was remediated
+func (m *missingNodeTracker) forget(key string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.since, key)
+}
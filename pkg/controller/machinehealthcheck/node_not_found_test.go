@@ -0,0 +1,111 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileNodeNotFoundGracePeriod(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("node-not-found")
+	// the node referenced by the machine was deleted out of band
+	machine := maotesting.NewMachine("machine", "deleted-node")
+
+	recorder := record.NewFakeRecorder(10)
+	r := newFakeReconcilerWithCustomRecorder(recorder, mhc, machine)
+	r.nodeNotFoundGracePeriod = time.Minute
+
+	reconcileMHC := func() reconcile.Result {
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return result
+	}
+	machineDeleted := func() bool {
+		err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{})
+		return apierrors.IsNotFound(err)
+	}
+
+	// within the grace period, the target is checked again when it expires
+	result := reconcileMHC()
+	if machineDeleted() {
+		t.Fatalf("Expected machine not to be remediated within the grace period")
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > r.nodeNotFoundGracePeriod+time.Second {
+		t.Errorf("Expected requeue within the grace period, got %v", result.RequeueAfter)
+	}
+	assertEvents(t, "within grace period", []string{EventDetectedUnhealthy}, recorder.Events)
+
+	// the grace period is measured from the first reconcile which found the node missing
+	missing := target{Machine: *machine, Node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: machine.Status.NodeRef.Name}}, MHC: *mhc}
+	r.missingNodes.since[missing.string()] = r.now().Add(-2 * time.Minute)
+
+	reconcileMHC()
+	if !machineDeleted() {
+		t.Errorf("Expected machine to be remediated after the grace period")
+	}
+	if _, ok := r.missingNodes.since[missing.string()]; ok {
+		t.Errorf("Expected missing node to be forgotten after remediation")
+	}
+}
+
+func TestNeedsRemediationNodeNotFoundGracePeriod(t *testing.T) {
+	machine := maotesting.NewMachine("machine", "deleted-node")
+	testCases := []struct {
+		testCase                 string
+		gracePeriod              time.Duration
+		missingFor               time.Duration
+		expectedNeedsRemediation bool
+	}{
+		{
+			testCase:                 "grace period disabled",
+			missingFor:               time.Second,
+			expectedNeedsRemediation: true,
+		},
+		{
+			testCase:                 "within grace period",
+			gracePeriod:              time.Minute,
+			missingFor:               time.Second,
+			expectedNeedsRemediation: false,
+		},
+		{
+			testCase:                 "after grace period",
+			gracePeriod:              time.Minute,
+			missingFor:               2 * time.Minute,
+			expectedNeedsRemediation: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			since := metav1.NewTime(time.Now().Add(-tc.missingFor))
+			target := target{
+				Machine:          *machine,
+				Node:             &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: machine.Status.NodeRef.Name}},
+				MHC:              *maotesting.NewMachineHealthCheck("mhc"),
+				NodeMissingSince: &since,
+			}
+			needsRemediation, unhealthyCondition, nextCheck, err := target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: defaultNodeStartupTimeout, nodeNotFoundGracePeriod: tc.gracePeriod})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if needsRemediation != tc.expectedNeedsRemediation {
+				t.Errorf("Expected needs remediation: %t, got: %t", tc.expectedNeedsRemediation, needsRemediation)
+			}
+			if needsRemediation && unhealthyCondition != unhealthyConditionNodeNotFound {
+				t.Errorf("Expected unhealthy condition %q, got %q", unhealthyConditionNodeNotFound, unhealthyCondition)
+			}
+			if !needsRemediation && nextCheck <= 0 {
+				t.Errorf("Expected a next check within the grace period, got %v", nextCheck)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// confirmNodeUnreachableAnnotation opts a MachineHealthCheck into confirming that the
+	// node of an unhealthy machine is unreachable before remediating the machine
+	confirmNodeUnreachableAnnotation = "machine.openshift.io/confirm-node-unreachable"
+	// nodeLeaseNamespace holds the leases renewed by the kubelet of every node
+	nodeLeaseNamespace = "kube-node-lease"
+	// defaultNodeLeaseDuration is the lease duration used by the kubelet by default,
+	// it is used for leases which do not specify their duration
+	defaultNodeLeaseDuration = 40 * time.Second
+	// nodeReachableRequeue is the delay before checking again a machine whose
+	// remediation was vetoed because its node is still reachable
+	nodeReachableRequeue = time.Minute
+
+	// EventRemediationVetoed is emitted when the remediation of an unhealthy machine
+	// is vetoed because its node is still reachable
+	EventRemediationVetoed string = "RemediationVetoed"
+)
+
+// nodeReachabilityCheck returns whether the node is still reachable along with a
+// description of the evidence
+type nodeReachabilityCheck func(r *ReconcileMachineHealthCheck, node *corev1.Node) (bool, string, error)
+
+// nodeReachableError is returned when the remediation of a machine is vetoed
+// because its node is still reachable
+type nodeReachableError struct {
+	target string
+	reason string
+}
+
+func (e *nodeReachableError) Error() string {
+	return fmt.Sprintf("%s: node is still reachable (%s), remediation vetoed", e.target, e.reason)
+}
+
+// nodeLeaseRenewed is the default nodeReachabilityCheck. A node is reachable as long as its
+// kubelet keeps renewing the node lease, which it does independently of the node status, so
+// a kubelet failing to report conditions but still running is not mistaken for a dead host.
+func nodeLeaseRenewed(r *ReconcileMachineHealthCheck, node *corev1.Node) (bool, string, error) {
+	lease, err := r.getNodeLease(node)
+	if err != nil {
+		return false, "", err
+	}
+	if lease == nil {
+		return false, "node lease not found", nil
+	}
+	if lease.Spec.RenewTime == nil {
+		return false, "node lease never renewed", nil
+	}
+
+	duration := defaultNodeLeaseDuration
+	if lease.Spec.LeaseDurationSeconds != nil {
+		duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	sinceRenewal := elapsedSince(lease.Spec.RenewTime.Time, r.now())
+	if sinceRenewal > duration {
+		return false, fmt.Sprintf("node lease not renewed for %v", sinceRenewal), nil
+	}
+	return true, fmt.Sprintf("node lease renewed %v ago", sinceRenewal), nil
+}
+
+// getNodeLease returns the lease renewed by the kubelet of the node, or nil if the node has no lease.
+// The lease is read from the apiserver, as the cache of the manager is restricted to the namespace
+// of the operator and never holds node leases.
+func (r *ReconcileMachineHealthCheck) getNodeLease(node *corev1.Node) (*coordinationv1.Lease, error) {
+	lease := &coordinationv1.Lease{}
+	key := client.ObjectKey{Namespace: nodeLeaseNamespace, Name: node.Name}
+	if err := r.apiReader.Get(context.TODO(), key, lease); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get node lease %s: %v", key, err)
+	}
+	return lease, nil
+}
+
+// getNodeLeaseRenewTime returns the last renewal of the lease of the node, or nil
+// if the node has no lease or the lease was never renewed
+func (r *ReconcileMachineHealthCheck) getNodeLeaseRenewTime(node *corev1.Node) (*metav1.Time, error) {
+	lease, err := r.getNodeLease(node)
+	if err != nil || lease == nil || lease.Spec.RenewTime == nil {
+		return nil, err
+	}
+	renewTime := metav1.NewTime(lease.Spec.RenewTime.Time)
+	return &renewTime, nil
+}
+
+// confirmNodeUnreachable returns a nodeReachableError if the MHC of the target requires the
+// node to be confirmed unreachable before remediation and the secondary check finds it still
+// reachable. Targets without a node have nothing to probe and are not vetoed.
+func (t *target) confirmNodeUnreachable(r *ReconcileMachineHealthCheck) error {
+	if _, ok := t.MHC.Annotations[confirmNodeUnreachableAnnotation]; !ok {
+		return nil
+	}
+	if t.Node == nil || t.Node.UID == "" {
+		return nil
+	}
+
+	check := r.nodeReachable
+	if check == nil {
+		check = nodeLeaseRenewed
+	}
+	reachable, reason, err := check(r, t.Node)
+	if err != nil {
+		return fmt.Errorf("%s: failed to confirm node is unreachable: %v", t.string(), err)
+	}
+	if !reachable {
+		klog.V(3).Infof("%s: confirmed node is unreachable: %s", t.string(), reason)
+		return nil
+	}
+
+	r.recorder.Eventf(
+		&t.Machine,
+		corev1.EventTypeNormal,
+		EventRemediationVetoed,
+		"Machine %v remediation vetoed: node is still reachable, %s",
+		t.string(),
+		reason,
+	)
+	return &nodeReachableError{target: t.string(), reason: reason}
+}
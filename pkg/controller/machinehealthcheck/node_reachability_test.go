@@ -0,0 +1,262 @@
+package machinehealthcheck
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRemediateConfirmNodeUnreachable(t *testing.T) {
+	testCases := []struct {
+		testCase        string
+		optIn           bool
+		reachable       bool
+		checkErr        error
+		expectedChecked bool
+		expectedDeleted bool
+		expectedVetoed  bool
+		expectedError   bool
+		expectedEvents  []string
+	}{
+		{
+			testCase:        "not opted in",
+			reachable:       true,
+			expectedDeleted: true,
+			expectedEvents:  []string{EventMachineDeleted},
+		},
+		{
+			testCase:        "secondary check confirms node is unreachable",
+			optIn:           true,
+			expectedChecked: true,
+			expectedDeleted: true,
+			expectedEvents:  []string{EventMachineDeleted},
+		},
+		{
+			testCase:        "secondary check vetoes remediation",
+			optIn:           true,
+			reachable:       true,
+			expectedChecked: true,
+			expectedVetoed:  true,
+			expectedError:   true,
+			expectedEvents:  []string{EventRemediationVetoed},
+		},
+		{
+			testCase:        "secondary check fails",
+			optIn:           true,
+			checkErr:        errors.New("boom"),
+			expectedChecked: true,
+			expectedError:   true,
+			expectedEvents:  []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			if tc.optIn {
+				mhc.Annotations = map[string]string{confirmNodeUnreachableAnnotation: ""}
+			}
+			machine := maotesting.NewMachine("machine", "node")
+			node := maotesting.NewNode("node", false)
+			node.UID = "uid"
+			target := target{
+				Machine: *machine,
+				Node:    node,
+				MHC:     *mhc,
+			}
+
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder, machine)
+			var checked bool
+			r.nodeReachable = func(_ *ReconcileMachineHealthCheck, _ *corev1.Node) (bool, string, error) {
+				checked = true
+				return tc.reachable, "stubbed", tc.checkErr
+			}
+
+			err := target.remediate(r)
+			if (err != nil) != tc.expectedError {
+				t.Errorf("Expected error: %t, got: %v", tc.expectedError, err)
+			}
+			var reachableErr *nodeReachableError
+			if vetoed := errors.As(err, &reachableErr); vetoed != tc.expectedVetoed {
+				t.Errorf("Expected vetoed: %t, got: %v", tc.expectedVetoed, err)
+			}
+			if checked != tc.expectedChecked {
+				t.Errorf("Expected secondary check called: %t, got: %t", tc.expectedChecked, checked)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+
+			err = r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{})
+			if deleted := apierrors.IsNotFound(err); deleted != tc.expectedDeleted {
+				t.Errorf("Expected machine deleted: %t, got: %v", tc.expectedDeleted, err)
+			}
+		})
+	}
+}
+
+func TestNodeLeaseRenewed(t *testing.T) {
+	now := time.Now()
+	newLease := func(renewTime *time.Time, durationSeconds *int32) *coordinationv1.Lease {
+		lease := &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "node",
+				Namespace: nodeLeaseNamespace,
+			},
+			Spec: coordinationv1.LeaseSpec{
+				LeaseDurationSeconds: durationSeconds,
+			},
+		}
+		if renewTime != nil {
+			lease.Spec.RenewTime = &metav1.MicroTime{Time: *renewTime}
+		}
+		return lease
+	}
+	recent := now.Add(-10 * time.Second)
+	stale := now.Add(-time.Minute)
+
+	testCases := []struct {
+		testCase          string
+		lease             *coordinationv1.Lease
+		expectedReachable bool
+	}{
+		{
+			testCase:          "recently renewed",
+			lease:             newLease(&recent, nil),
+			expectedReachable: true,
+		},
+		{
+			testCase:          "not renewed within default duration",
+			lease:             newLease(&stale, nil),
+			expectedReachable: false,
+		},
+		{
+			testCase:          "renewed within lease duration",
+			lease:             newLease(&stale, pointer.Int32Ptr(120)),
+			expectedReachable: true,
+		},
+		{
+			testCase:          "never renewed",
+			lease:             newLease(nil, nil),
+			expectedReachable: false,
+		},
+		{
+			testCase:          "no lease",
+			expectedReachable: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			var objects []runtime.Object
+			if tc.lease != nil {
+				objects = append(objects, tc.lease)
+			}
+			r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(1))
+			// node leases are outside of the namespace of the cache, so they are only
+			// found by reading from the apiserver
+			r.apiReader = fake.NewFakeClient(objects...)
+			r.clock = clock.NewFakeClock(now)
+
+			reachable, reason, err := nodeLeaseRenewed(r, maotesting.NewNode("node", false))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if reachable != tc.expectedReachable {
+				t.Errorf("Expected reachable: %t, got: %t (%s)", tc.expectedReachable, reachable, reason)
+			}
+		})
+	}
+}
+
+func TestNeedsRemediationNodeLeaseStale(t *testing.T) {
+	testCases := []struct {
+		testCase                   string
+		renewedAgo                 time.Duration
+		expectedNeedsRemediation   bool
+		expectedUnhealthyCondition string
+	}{
+		{
+			testCase:                   "stale lease",
+			renewedAgo:                 2 * time.Minute,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: unhealthyConditionNodeLeaseStale,
+		},
+		{
+			testCase:                 "fresh lease",
+			renewedAgo:               10 * time.Second,
+			expectedNeedsRemediation: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			// the node still reports ready, only the lease tells the kubelet is dead
+			node := maotesting.NewNode("node", true)
+			node.UID = "uid"
+			renewTime := metav1.NewTime(time.Now().Add(-tc.renewedAgo))
+			target := target{
+				Machine:            *maotesting.NewMachine("machine", node.Name),
+				Node:               node,
+				MHC:                *maotesting.NewMachineHealthCheck("mhc"),
+				NodeLeaseRenewTime: &renewTime,
+			}
+
+			needsRemediation, unhealthyCondition, nextCheck, err := target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: defaultNodeStartupTimeout, nodeLeaseStaleTimeout: time.Minute})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if needsRemediation != tc.expectedNeedsRemediation {
+				t.Errorf("Expected needsRemediation: %t, got: %t", tc.expectedNeedsRemediation, needsRemediation)
+			}
+			if unhealthyCondition != tc.expectedUnhealthyCondition {
+				t.Errorf("Expected unhealthy condition %q, got %q", tc.expectedUnhealthyCondition, unhealthyCondition)
+			}
+			if !needsRemediation && (nextCheck <= 0 || nextCheck > time.Minute) {
+				t.Errorf("Expected next check within the lease stale timeout, got %v", nextCheck)
+			}
+		})
+	}
+}
+
+func TestGetTargetsFromMHCNodeLease(t *testing.T) {
+	renewTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+	mhc := maotesting.NewMachineHealthCheck("mhc")
+	node := maotesting.NewNode("node", true)
+	node.UID = "uid"
+	machine := maotesting.NewMachine("machine", node.Name)
+	node.Annotations[machineAnnotationKey] = namespacedName(machine).String()
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      node.Name,
+			Namespace: nodeLeaseNamespace,
+		},
+		Spec: coordinationv1.LeaseSpec{
+			RenewTime: &metav1.MicroTime{Time: renewTime},
+		},
+	}
+
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(1), mhc, machine, node, lease)
+	r.nodeLeaseStaleTimeout = time.Minute
+	targets, err := r.getTargetsFromMHC(*mhc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(targets) != 1 {
+		t.Fatalf("Expected 1 target, got %d", len(targets))
+	}
+	if got := targets[0].NodeLeaseRenewTime; got == nil || !got.Time.Equal(renewTime) {
+		t.Errorf("Expected node lease renew time %v, got %v", renewTime, got)
+	}
+}
@@ -0,0 +1,50 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// lastRemediationAnnotation records the last remediation of a node on the node itself, so that
+// it shows up in `kubectl describe node`. It complements the records kept on the machine, and is
+// most useful for strategies which keep the node, such as rebooting its host.
+const lastRemediationAnnotation = "healthchecking.openshift.io/last-remediation"
+
+// nodeRemediationRecord is the JSON value of the last remediation annotation of a node
+type nodeRemediationRecord struct {
+	Time     time.Time `json:"time"`
+	Strategy string    `json:"strategy"`
+}
+
+// annotateNodeRemediation stamps the node of the target with the last remediation annotation,
+// nodes which do not exist are left alone
+func (t *target) annotateNodeRemediation(r *ReconcileMachineHealthCheck, strategy string) error {
+	if t.Node == nil || t.Node.UID == "" {
+		return nil
+	}
+
+	value, err := json.Marshal(nodeRemediationRecord{
+		Time:     r.now().UTC().Truncate(time.Second),
+		Strategy: strategy,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: failed to marshal remediation record: %v", t.string(), err)
+	}
+
+	node := t.Node.DeepCopy()
+	mergeBase := client.MergeFrom(node.DeepCopy())
+	if node.Annotations == nil {
+		node.Annotations = map[string]string{}
+	}
+	node.Annotations[lastRemediationAnnotation] = string(value)
+	if err := r.client.Patch(context.TODO(), node, mergeBase); err != nil {
+		return fmt.Errorf("%s: failed to annotate node with remediation: %v", t.string(), err)
+	}
+	klog.V(3).Infof("%s: recorded %s remediation on node", t.string(), strategy)
+	return nil
+}
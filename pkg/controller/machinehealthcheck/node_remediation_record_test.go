@@ -0,0 +1,73 @@
+package machinehealthcheck
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRemediateRecordsRemediationOnNode(t *testing.T) {
+	now := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	host := &unstructured.Unstructured{}
+	host.SetGroupVersionKind(baremetalHostGVK)
+	host.SetNamespace(namespace)
+	host.SetName("host")
+
+	mhc := maotesting.NewMachineHealthCheck("mhc")
+	mhc.Annotations = map[string]string{remediationStrategyAnnotation: string(remediationStrategyReboot)}
+	node := maotesting.NewNode("node", false)
+	node.UID = "uid"
+	machine := maotesting.NewMachine("machine", node.Name)
+	machine.Annotations[baremetalHostAnnotation] = namespace + "/host"
+	target := target{
+		Machine: *machine,
+		Node:    node,
+		MHC:     *mhc,
+	}
+
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(2), machine, node, host)
+	r.clock = clock.NewFakeClock(now)
+	if err := target.remediate(r); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	updated := &corev1.Node{}
+	if err := r.client.Get(ctx, namespacedName(node), updated); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	value, ok := updated.Annotations[lastRemediationAnnotation]
+	if !ok {
+		t.Fatalf("Expected node to have %s annotation", lastRemediationAnnotation)
+	}
+	remediation := nodeRemediationRecord{}
+	if err := json.Unmarshal([]byte(value), &remediation); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if remediation.Strategy != string(remediationStrategyReboot) {
+		t.Errorf("Expected strategy %q, got %q", remediationStrategyReboot, remediation.Strategy)
+	}
+	if !remediation.Time.Equal(now) {
+		t.Errorf("Expected time %v, got %v", now, remediation.Time)
+	}
+}
+
+func TestAnnotateNodeRemediationMissingNode(t *testing.T) {
+	// a node with only a name represents a node which does not exist
+	target := target{
+		Machine: *maotesting.NewMachine("machine", "node"),
+		Node:    &corev1.Node{},
+		MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+	}
+	target.Node.Name = "node"
+
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(1))
+	if err := target.annotateNodeRemediation(r, string(remediationStrategyDelete)); err != nil {
+		t.Errorf("Expected missing node to be skipped, got: %v", err)
+	}
+}
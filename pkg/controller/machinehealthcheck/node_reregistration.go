@@ -0,0 +1,60 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// replacementNode returns the name of the node which replaced the given node if the node is
+// annotated with a machine whose nodeRef now refers to another node, ie the host of the machine
+// re-registered under a new name and the node is a stale leftover. An empty string is returned
+// otherwise. Targets are always evaluated against the current nodeRef of their machine, so stale
+// nodes must not be mapped to MHCs nor retried.
+func (r *ReconcileMachineHealthCheck) replacementNode(node *corev1.Node) (string, error) {
+	annotation, ok := node.Annotations[machineAnnotationKey]
+	if !ok {
+		return "", nil
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(annotation)
+	if err != nil {
+		return "", nil
+	}
+
+	machine := &mapiv1.Machine{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: name}, machine); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get machine %s of node %q: %v", annotation, node.Name, err)
+	}
+	if machine.Status.NodeRef == nil || machine.Status.NodeRef.Name == node.Name {
+		return "", nil
+	}
+	return machine.Status.NodeRef.Name, nil
+}
+
+// machineKey returns the prefix shared by the keys of the target for every node of its machine
+func (t *target) machineKey() string {
+	return strings.TrimSuffix(t.string(), t.nodeName())
+}
+
+// forgetPreviousNodes removes the records of the nodes previously referenced by the machine of
+// the given target, the record of the current node of the target is kept
+func (m *missingNodeTracker) forgetPreviousNodes(t *target) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	prefix := t.machineKey()
+	for key := range m.since {
+		if key != t.string() && strings.HasPrefix(key, prefix) {
+			delete(m.since, key)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+)
+
+func TestNodeReregistration(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("mhc")
+	machine := maotesting.NewMachine("machine", "node-new")
+
+	// the old node lingers unhealthy after the host re-registered as node-new
+	nodeOld := maotesting.NewNode("node-old", false)
+	nodeOld.UID = "old"
+	nodeOld.Annotations[machineAnnotationKey] = namespacedName(machine).String()
+	nodeNew := maotesting.NewNode("node-new", true)
+	nodeNew.UID = "new"
+	nodeNew.Annotations[machineAnnotationKey] = namespacedName(machine).String()
+
+	r := newFakeReconciler(mhc, machine, nodeOld, nodeNew)
+	r.client = &machineNodeIndexClient{Client: r.client}
+	r.nodeRetrier = newNodeRetrier(0, nodeRetryAttempts)
+
+	// a record of the old node found missing before the machine moved
+	oldTarget := target{Machine: *machine, Node: nodeOld, MHC: *mhc}
+	r.missingNodes.observe(oldTarget.string(), time.Now())
+
+	targets, err := r.getTargetsFromMHC(*mhc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(targets) != 1 {
+		t.Fatalf("Expected 1 target, got %d", len(targets))
+	}
+	if name := targets[0].nodeName(); name != nodeNew.Name {
+		t.Fatalf("Expected target evaluated against node %q, got %q", nodeNew.Name, name)
+	}
+	healthy, unhealthy, _, errList := r.healthCheckTargets(targets, defaultNodeStartupTimeout)
+	if len(errList) > 0 {
+		t.Fatalf("Unexpected errors: %v", errList)
+	}
+	if len(healthy) != 1 || len(unhealthy) != 0 {
+		t.Errorf("Expected the target healthy as node %q, got %d healthy and %d unhealthy", nodeNew.Name, len(healthy), len(unhealthy))
+	}
+	if _, ok := r.missingNodes.since[oldTarget.string()]; ok {
+		t.Errorf("Expected the record of node %q to be cleaned up", nodeOld.Name)
+	}
+
+	// events of the stale node are ignored rather than retried
+	if requests := r.mhcRequestsFromNode(nodeOld); requests != nil {
+		t.Errorf("Expected no requests for the stale node, got: %v", requests)
+	}
+	if _, ok := receiveNodeRetry(t, r.nodeRetrier.events); ok {
+		t.Errorf("Expected the stale node not to be retried")
+	}
+	if requests := r.mhcRequestsFromNode(nodeNew); len(requests) != 1 || requests[0].NamespacedName != namespacedName(mhc) {
+		t.Errorf("Expected a request for MHC %v from node %q, got: %v", namespacedName(mhc), nodeNew.Name, requests)
+	}
+}
+
+func TestReplacementNode(t *testing.T) {
+	machine := maotesting.NewMachine("machine", "node-new")
+
+	testCases := []struct {
+		testCase            string
+		nodeName            string
+		annotation          string
+		expectedReplacement string
+	}{
+		{
+			testCase:            "machine moved to another node",
+			nodeName:            "node-old",
+			annotation:          namespacedName(machine).String(),
+			expectedReplacement: "node-new",
+		},
+		{
+			testCase:   "current node of the machine",
+			nodeName:   "node-new",
+			annotation: namespacedName(machine).String(),
+		},
+		{
+			testCase:   "machine does not exist",
+			nodeName:   "node-old",
+			annotation: namespace + "/gone",
+		},
+		{
+			testCase: "no machine annotation",
+			nodeName: "node-old",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			node := maotesting.NewNode(tc.nodeName, true)
+			if tc.annotation != "" {
+				node.Annotations[machineAnnotationKey] = tc.annotation
+			}
+
+			r := newFakeReconciler(machine, node)
+			replacement, err := r.replacementNode(node)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if replacement != tc.expectedReplacement {
+				t.Errorf("Expected replacement %q, got %q", tc.expectedReplacement, replacement)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package machinehealthcheck
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const (
+	// nodeRetryDelay is the delay before mapping a node to MHCs again when its machine
+	// could not be resolved
+	nodeRetryDelay = 10 * time.Second
+	// nodeRetryAttempts is the number of times a node is mapped again before giving up,
+	// covering the time the nodelink controller usually takes to set the nodeRef of a machine
+	nodeRetryAttempts = 6
+)
+
+// nodeRetrier re-triggers the mapping of nodes to MHCs when their machine cannot be resolved yet,
+// e.g. a node which registered before the nodeRef of its machine was set. Without it, such a node
+// would only be evaluated on the next event of its machine or on resync.
+type nodeRetrier struct {
+	lock        sync.Mutex
+	delay       time.Duration
+	maxAttempts int
+	// attempts contains the number of retries scheduled for each node since its machine was last resolved
+	attempts map[string]int
+	// events receives the nodes to map again, it is watched as a source of the controller
+	events chan event.GenericEvent
+}
+
+func newNodeRetrier(delay time.Duration, maxAttempts int) *nodeRetrier {
+	return &nodeRetrier{
+		delay:       delay,
+		maxAttempts: maxAttempts,
+		attempts:    map[string]int{},
+		events:      make(chan event.GenericEvent, 1024),
+	}
+}
+
+// retry schedules the node to be mapped again after the retry delay,
+// unless the retries of the node are exhausted
+func (nr *nodeRetrier) retry(node *corev1.Node) {
+	nr.lock.Lock()
+	defer nr.lock.Unlock()
+
+	if nr.attempts[node.Name] >= nr.maxAttempts {
+		klog.V(4).Infof("Not retrying node %q, %d retries exhausted", node.Name, nr.maxAttempts)
+		return
+	}
+	nr.attempts[node.Name]++
+	klog.V(3).Infof("Retrying node %q in %v, attempt %d of %d", node.Name, nr.delay, nr.attempts[node.Name], nr.maxAttempts)
+
+	obj := node.DeepCopy()
+	time.AfterFunc(nr.delay, func() {
+		nr.events <- event.GenericEvent{Object: obj}
+	})
+}
+
+// forget resets the retries of the node
+func (nr *nodeRetrier) forget(nodeName string) {
+	nr.lock.Lock()
+	defer nr.lock.Unlock()
+
+	delete(nr.attempts, nodeName)
+}
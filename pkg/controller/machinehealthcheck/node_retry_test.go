@@ -0,0 +1,116 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// machineNodeIndexClient filters machine lists by the machine node name index,
+// which the fake client does not support
+type machineNodeIndexClient struct {
+	client.Client
+}
+
+func (c *machineNodeIndexClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if err := c.Client.List(ctx, list, opts...); err != nil {
+		return err
+	}
+	machineList, ok := list.(*mapiv1beta1.MachineList)
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if !ok || listOpts.FieldSelector == nil {
+		return nil
+	}
+	nodeName, ok := listOpts.FieldSelector.RequiresExactMatch(machineNodeNameIndex)
+	if !ok {
+		return nil
+	}
+	var machines []mapiv1beta1.Machine
+	for i := range machineList.Items {
+		for _, indexed := range indexMachineByNodeName(&machineList.Items[i]) {
+			if indexed == nodeName {
+				machines = append(machines, machineList.Items[i])
+			}
+		}
+	}
+	machineList.Items = machines
+	return nil
+}
+
+func receiveNodeRetry(t *testing.T, events <-chan event.GenericEvent) (event.GenericEvent, bool) {
+	t.Helper()
+	select {
+	case evt := <-events:
+		return evt, true
+	case <-time.After(time.Second):
+		return event.GenericEvent{}, false
+	}
+}
+
+func TestMHCRequestsFromNodeRetry(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("mhc")
+	node := maotesting.NewNode("node", true)
+	// the node joined before the nodeRef of its machine was set
+	machine := maotesting.NewMachine("machine", node.Name)
+	machine.Status.NodeRef = nil
+
+	r := newFakeReconciler(mhc, node, machine)
+	r.client = &machineNodeIndexClient{Client: r.client}
+	r.nodeRetrier = newNodeRetrier(0, nodeRetryAttempts)
+
+	if requests := r.mhcRequestsFromNode(node); requests != nil {
+		t.Fatalf("Expected no requests before the nodeRef is set, got: %v", requests)
+	}
+	evt, ok := receiveNodeRetry(t, r.nodeRetrier.events)
+	if !ok {
+		t.Fatalf("Expected the node to be retried")
+	}
+
+	// the nodeRef is set before the second pass
+	if err := r.client.Get(ctx, namespacedName(machine), machine); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	machine.Status.NodeRef = maotesting.NewMachine("machine", node.Name).Status.NodeRef
+	if err := r.client.Update(ctx, machine); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []reconcile.Request{{NamespacedName: namespacedName(mhc)}}
+	if requests := r.mhcRequestsFromNode(evt.Object); !reflect.DeepEqual(requests, expected) {
+		t.Errorf("Expected requests %v on retry, got: %v", expected, requests)
+	}
+	if attempts, ok := r.nodeRetrier.attempts[node.Name]; ok {
+		t.Errorf("Expected the retries of the node to be reset, got %d attempts", attempts)
+	}
+}
+
+func TestNodeRetrierExhausted(t *testing.T) {
+	nr := newNodeRetrier(0, 1)
+	node := maotesting.NewNode("node", true)
+
+	nr.retry(node)
+	nr.retry(node)
+	if _, ok := receiveNodeRetry(t, nr.events); !ok {
+		t.Fatalf("Expected the node to be retried")
+	}
+	select {
+	case evt := <-nr.events:
+		t.Errorf("Expected a single retry, got another one for %q", evt.Object.GetName())
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// resolving the machine of the node resets its retries
+	nr.forget(node.Name)
+	nr.retry(node)
+	if _, ok := receiveNodeRetry(t, nr.events); !ok {
+		t.Errorf("Expected the node to be retried after its retries were reset")
+	}
+}
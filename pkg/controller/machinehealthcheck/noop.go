@@ -0,0 +1,38 @@
+package machinehealthcheck
+
+import (
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// remediationStrategyNoop evaluates the health of the targets and reports it in the MHC
+	// status and metrics, but never acts on them. It validates that the selector and conditions
+	// of an MHC identify the intended machines before another strategy is selected. Unlike the
+	// other strategies it applies to failed machines and machines being deleted as well.
+	remediationStrategyNoop = mapiv1.RemediationStrategyType("noop")
+
+	// EventRemediationSkippedNoop is emitted when a machine needing remediation
+	// is left alone by the noop strategy
+	EventRemediationSkippedNoop string = "RemediationSkippedNoop"
+)
+
+// isNoop returns whether the MHC of the target selected the noop remediation strategy
+func (t *target) isNoop() bool {
+	return t.MHC.Annotations[remediationStrategyAnnotation] == string(remediationStrategyNoop)
+}
+
+// remediationStrategyNoop reports that the target would have been remediated
+func (t *target) remediationStrategyNoop(r *ReconcileMachineHealthCheck) error {
+	klog.Infof("%s: noop remediation strategy, skipping remediation", t.string())
+	r.recorder.Eventf(
+		&t.Machine,
+		corev1.EventTypeNormal,
+		EventRemediationSkippedNoop,
+		"Machine %v needs remediation, skipped by the %s remediation strategy",
+		t.string(),
+		remediationStrategyNoop,
+	)
+	return nil
+}
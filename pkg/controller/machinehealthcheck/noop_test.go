@@ -0,0 +1,67 @@
+package machinehealthcheck
+
+import (
+	"strings"
+	"testing"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileNoopRemediationStrategy(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("noop")
+	mhc.Annotations = map[string]string{remediationStrategyAnnotation: string(remediationStrategyNoop)}
+
+	healthyNode := maotesting.NewNode("healthy", true)
+	healthy := maotesting.NewMachine("healthy", healthyNode.Name)
+	healthyNode.Annotations[machineAnnotationKey] = namespacedName(healthy).String()
+	unhealthyNode := maotesting.NewNode("unhealthy", false)
+	unhealthy := maotesting.NewMachine("unhealthy", unhealthyNode.Name)
+	unhealthyNode.Annotations[machineAnnotationKey] = namespacedName(unhealthy).String()
+	failedNode := maotesting.NewNode("failed", true)
+	failed := maotesting.NewMachine("failed", failedNode.Name)
+	failed.Status.Phase = pointer.StringPtr(machinePhaseFailed)
+	failedNode.Annotations[machineAnnotationKey] = namespacedName(failed).String()
+
+	recorder := record.NewFakeRecorder(10)
+	r := newFakeReconcilerWithCustomRecorder(recorder, mhc, healthyNode, healthy, unhealthyNode, unhealthy, failedNode, failed)
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// the health of the targets is reported as usual
+	got := &mapiv1beta1.MachineHealthCheck{}
+	if err := r.client.Get(ctx, namespacedName(mhc), got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := derefInt(got.Status.ExpectedMachines); expected != 3 {
+		t.Errorf("Expected 3 expected machines, got %d", expected)
+	}
+	if healthy := derefInt(got.Status.CurrentHealthy); healthy != 1 {
+		t.Errorf("Expected 1 healthy machine, got %d", healthy)
+	}
+
+	// but no machine is remediated, not even the failed one
+	for _, machine := range []*mapiv1beta1.Machine{healthy, unhealthy, failed} {
+		got := &mapiv1beta1.Machine{}
+		if err := r.client.Get(ctx, namespacedName(machine), got); apierrors.IsNotFound(err) {
+			t.Errorf("Expected machine %s not to be deleted", machine.Name)
+		} else if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	skipped := 0
+	for len(recorder.Events) > 0 {
+		if event := <-recorder.Events; strings.Contains(event, " "+EventRemediationSkippedNoop+" ") {
+			skipped++
+		}
+	}
+	if skipped != 2 {
+		t.Errorf("Expected 2 %s events, got %d", EventRemediationSkippedNoop, skipped)
+	}
+}
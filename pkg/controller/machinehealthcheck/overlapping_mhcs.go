@@ -0,0 +1,89 @@
+package machinehealthcheck
+
+import (
+	"context"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EventOverlappingMHC is emitted in case the selector of a MachineHealthCheck matches
+// a machine also matched by the selector of another MachineHealthCheck
+const EventOverlappingMHC string = "OverlappingMachineHealthCheck"
+
+// mhcOverlap is a pair of MachineHealthChecks whose selectors match a common machine
+type mhcOverlap struct {
+	first  types.NamespacedName
+	second types.NamespacedName
+	// machine is a machine matched by both MachineHealthChecks
+	machine types.NamespacedName
+}
+
+// overlappingMHCs returns the pairs of MHCs whose selectors both match at least one of the machines.
+// Each pair is reported once, in the order of the MHCs. MHCs only match machines of their namespace.
+func overlappingMHCs(mhcs []mapiv1.MachineHealthCheck, machines []mapiv1.Machine) []mhcOverlap {
+	var overlaps []mhcOverlap
+	for i := range mhcs {
+		for j := i + 1; j < len(mhcs); j++ {
+			if mhcs[i].Namespace != mhcs[j].Namespace {
+				continue
+			}
+			for k := range machines {
+				if machines[k].Namespace != mhcs[i].Namespace {
+					continue
+				}
+				if hasMatchingLabels(&mhcs[i], &machines[k]) && hasMatchingLabels(&mhcs[j], &machines[k]) {
+					overlaps = append(overlaps, mhcOverlap{
+						first:   namespacedName(&mhcs[i]),
+						second:  namespacedName(&mhcs[j]),
+						machine: namespacedName(&machines[k]),
+					})
+					break
+				}
+			}
+		}
+	}
+	return overlaps
+}
+
+// warnOverlappingMHCs reports the other MHCs of the namespace whose selectors match a machine
+// also matched by the MHC, as overlapping MHCs may remediate a machine twice or with conflicting
+// strategies. The MHC is still reconciled, failing to list MHCs or machines is only logged.
+func (r *ReconcileMachineHealthCheck) warnOverlappingMHCs(mhc *mapiv1.MachineHealthCheck) {
+	mhcList := &mapiv1.MachineHealthCheckList{}
+	if err := r.client.List(context.TODO(), mhcList, client.InNamespace(mhc.Namespace)); err != nil {
+		klog.Errorf("%s/%s: failed to list MachineHealthChecks for overlap detection: %v", mhc.Namespace, mhc.Name, err)
+		return
+	}
+	machineList := &mapiv1.MachineList{}
+	if err := r.client.List(context.TODO(), machineList, client.InNamespace(mhc.Namespace)); err != nil {
+		klog.Errorf("%s/%s: failed to list machines for overlap detection: %v", mhc.Namespace, mhc.Name, err)
+		return
+	}
+
+	key := namespacedName(mhc)
+	count := 0
+	for _, overlap := range overlappingMHCs(mhcList.Items, machineList.Items) {
+		other := overlap.second
+		if overlap.second == key {
+			other = overlap.first
+		} else if overlap.first != key {
+			continue
+		}
+		count++
+		klog.Warningf("%s: selector overlaps with MachineHealthCheck %s on machine %s", key, other, overlap.machine)
+		r.recorder.Eventf(
+			mhc,
+			corev1.EventTypeWarning,
+			EventOverlappingMHC,
+			"Selector overlaps with MachineHealthCheck %v, both match machine %v and may remediate it twice",
+			other,
+			overlap.machine,
+		)
+	}
+	metrics.ObserveMachineHealthCheckOverlapping(mhc.Name, mhc.Namespace, count)
+}
@@ -0,0 +1,84 @@
+package machinehealthcheck
+
+import (
+	"reflect"
+	"testing"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestOverlappingMHCs(t *testing.T) {
+	workers := maotesting.NewMachineHealthCheck("workers")
+	workers.Spec.Selector = metav1.LabelSelector{MatchLabels: map[string]string{"role": "worker"}}
+	zoneA := maotesting.NewMachineHealthCheck("zone-a")
+	zoneA.Spec.Selector = metav1.LabelSelector{MatchLabels: map[string]string{"zone": "a"}}
+	infra := maotesting.NewMachineHealthCheck("infra")
+	infra.Spec.Selector = metav1.LabelSelector{MatchLabels: map[string]string{"role": "infra"}}
+
+	worker := maotesting.NewMachine("worker-a", "")
+	worker.Labels = map[string]string{"role": "worker", "zone": "a"}
+	infraMachine := maotesting.NewMachine("infra-b", "")
+	infraMachine.Labels = map[string]string{"role": "infra", "zone": "b"}
+
+	mhcs := []mapiv1beta1.MachineHealthCheck{*workers, *zoneA, *infra}
+	machines := []mapiv1beta1.Machine{*worker, *infraMachine}
+	expected := []mhcOverlap{
+		{
+			first:   namespacedName(workers),
+			second:  namespacedName(zoneA),
+			machine: namespacedName(worker),
+		},
+	}
+	if got := overlappingMHCs(mhcs, machines); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected overlaps %v, got %v", expected, got)
+	}
+
+	testCases := []struct {
+		mhc                 *mapiv1beta1.MachineHealthCheck
+		expectedEvents      []string
+		expectedOverlapping float64
+	}{
+		{
+			mhc:                 workers,
+			expectedEvents:      []string{EventOverlappingMHC},
+			expectedOverlapping: 1,
+		},
+		{
+			mhc:                 zoneA,
+			expectedEvents:      []string{EventOverlappingMHC},
+			expectedOverlapping: 1,
+		},
+		{
+			mhc:                 infra,
+			expectedEvents:      []string{},
+			expectedOverlapping: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.mhc.Name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder, workers, zoneA, infra, worker, infraMachine)
+			r.warnOverlappingMHCs(tc.mhc)
+			assertEvents(t, tc.mhc.Name, tc.expectedEvents, recorder.Events)
+
+			gauge, err := metrics.MachineHealthCheckOverlappingTotal.GetMetricWithLabelValues(tc.mhc.Name, tc.mhc.Namespace)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			metric := &dto.Metric{}
+			if err := gauge.(prometheus.Metric).Write(metric); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := metric.GetGauge().GetValue(); got != tc.expectedOverlapping {
+				t.Errorf("Expected %v overlapping MachineHealthChecks, got %v", tc.expectedOverlapping, got)
+			}
+		})
+	}
+}
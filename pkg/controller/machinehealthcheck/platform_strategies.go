@@ -0,0 +1,129 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apimachineryerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// globalInfrastructureName is the name of the cluster wide Infrastructure object
+	globalInfrastructureName = "cluster"
+
+	// allowDeleteFallbackAnnotation opts a MachineHealthCheck into remediating its targets by deletion
+	// when its remediation strategy is not supported on the platform of the cluster. Without it, the
+	// MachineHealthCheck does not remediate at all, as the strategy may have been chosen precisely to
+	// avoid deleting machines.
+	allowDeleteFallbackAnnotation = "machine.openshift.io/allow-delete-fallback"
+
+	// EventStrategyUnsupportedOnPlatform is emitted when the remediation strategy of a
+	// MachineHealthCheck is not supported on the platform of the cluster
+	EventStrategyUnsupportedOnPlatform string = "StrategyUnsupportedOnPlatform"
+)
+
+// strategyPlatforms lists the platforms remediation strategies are restricted to,
+// strategies not listed here are supported on every platform
+var strategyPlatforms = map[mapiv1.RemediationStrategyType][]configv1.PlatformType{
+	remediationStrategyReboot:     {configv1.BareMetalPlatformType},
+	remediationStrategyPowerCycle: {configv1.BareMetalPlatformType},
+}
+
+// getPlatform returns the platform of the cluster as reported by the Infrastructure object.
+// An empty platform is returned when the cluster has no Infrastructure object.
+func (r *ReconcileMachineHealthCheck) getPlatform() (configv1.PlatformType, error) {
+	infra := &configv1.Infrastructure{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Name: globalInfrastructureName}, infra); err != nil {
+		if apimachineryerrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get infrastructure %q: %v", globalInfrastructureName, err)
+	}
+	if infra.Status.PlatformStatus != nil && infra.Status.PlatformStatus.Type != "" {
+		return infra.Status.PlatformStatus.Type, nil
+	}
+	return infra.Status.Platform, nil
+}
+
+// strategySupportedOnPlatform returns whether the strategy may be used on the platform,
+// strategies are not restricted when the platform is unknown
+func strategySupportedOnPlatform(strategy mapiv1.RemediationStrategyType, platform configv1.PlatformType) bool {
+	platforms, ok := strategyPlatforms[strategy]
+	if !ok || platform == "" {
+		return true
+	}
+	for _, p := range platforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// unsupportedStrategyError returns the permanent error refusing to remediate with a strategy
+// which is not supported on the platform of the cluster
+func unsupportedStrategyError(strategy mapiv1.RemediationStrategyType, platform configv1.PlatformType) error {
+	return &permanentError{err: fmt.Errorf("remediation strategy %q is not supported on platform %q, set the %s annotation to remediate by deletion instead",
+		strategy,
+		platform,
+		allowDeleteFallbackAnnotation,
+	)}
+}
+
+// validateRemediationStrategy returns a permanent error if the remediation strategy of the MHC is not
+// supported on the platform of the cluster and the MHC does not allow falling back to deletion
+func (r *ReconcileMachineHealthCheck) validateRemediationStrategy(mhc *mapiv1.MachineHealthCheck) error {
+	strategy := mapiv1.RemediationStrategyType(mhc.Annotations[remediationStrategyAnnotation])
+	if _, ok := strategyPlatforms[strategy]; !ok {
+		return nil
+	}
+	if _, ok := mhc.Annotations[allowDeleteFallbackAnnotation]; ok {
+		return nil
+	}
+	platform, err := r.getPlatform()
+	if err != nil {
+		return err
+	}
+	if strategySupportedOnPlatform(strategy, platform) {
+		return nil
+	}
+	return unsupportedStrategyError(strategy, platform)
+}
+
+// platformSupportsStrategy returns whether the strategy of the target is supported on the
+// platform of the cluster. When it is not, the target may only be remediated by deletion if
+// its MHC allows falling back to deletion, in which case an event is emitted on the target's
+// machine, otherwise the remediation is refused with a permanent error.
+func (t *target) platformSupportsStrategy(r *ReconcileMachineHealthCheck, strategy mapiv1.RemediationStrategyType) (bool, error) {
+	if _, ok := strategyPlatforms[strategy]; !ok {
+		return true, nil
+	}
+	platform, err := r.getPlatform()
+	if err != nil {
+		return false, fmt.Errorf("%s: %v", t.string(), err)
+	}
+	if strategySupportedOnPlatform(strategy, platform) {
+		return true, nil
+	}
+	if _, ok := t.MHC.Annotations[allowDeleteFallbackAnnotation]; !ok {
+		return false, unsupportedStrategyError(strategy, platform)
+	}
+
+	r.recorder.Eventf(
+		&t.Machine,
+		corev1.EventTypeWarning,
+		EventStrategyUnsupportedOnPlatform,
+		"Remediation strategy %q of machine %v is not supported on platform %q, falling back to %s",
+		strategy,
+		t.string(),
+		platform,
+		remediationStrategyDelete,
+	)
+	klog.Warningf("%s: remediation strategy %q not supported on platform %q, falling back to %s", t.string(), strategy, platform, remediationStrategyDelete)
+	return false, nil
+}
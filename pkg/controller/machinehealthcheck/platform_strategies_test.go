@@ -0,0 +1,147 @@
+package machinehealthcheck
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestStrategySupportedOnPlatform(t *testing.T) {
+	testCases := []struct {
+		strategy mapiv1beta1.RemediationStrategyType
+		platform configv1.PlatformType
+		expected bool
+	}{
+		{strategy: remediationStrategyPowerCycle, platform: configv1.BareMetalPlatformType, expected: true},
+		{strategy: remediationStrategyReboot, platform: configv1.BareMetalPlatformType, expected: true},
+		{strategy: remediationStrategyPowerCycle, platform: configv1.AWSPlatformType, expected: false},
+		{strategy: remediationStrategyReboot, platform: configv1.VSpherePlatformType, expected: false},
+		{strategy: remediationStrategyPowerCycle, platform: "", expected: true},
+		{strategy: remediationStrategyDelete, platform: configv1.AWSPlatformType, expected: true},
+	}
+
+	for _, tc := range testCases {
+		if got := strategySupportedOnPlatform(tc.strategy, tc.platform); got != tc.expected {
+			t.Errorf("Strategy %q on platform %q: expected %t, got %t", tc.strategy, tc.platform, tc.expected, got)
+		}
+	}
+}
+
+func TestRemediatePlatformStrategy(t *testing.T) {
+	newInfra := func(platform configv1.PlatformType) *configv1.Infrastructure {
+		return &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: globalInfrastructureName},
+			Status: configv1.InfrastructureStatus{
+				PlatformStatus: &configv1.PlatformStatus{Type: platform},
+			},
+		}
+	}
+
+	testCases := []struct {
+		testCase              string
+		platform              configv1.PlatformType
+		annotations           map[string]string
+		expectedError         bool
+		expectedEvents        []string
+		expectedHostRebooted  bool
+		expectedMachineExists bool
+	}{
+		{
+			testCase:              "power cycle on baremetal",
+			platform:              configv1.BareMetalPlatformType,
+			expectedEvents:        []string{EventPowerCycleRequested},
+			expectedHostRebooted:  true,
+			expectedMachineExists: true,
+		},
+		{
+			testCase:              "power cycle on aws is refused",
+			platform:              configv1.AWSPlatformType,
+			expectedError:         true,
+			expectedEvents:        []string{},
+			expectedHostRebooted:  false,
+			expectedMachineExists: true,
+		},
+		{
+			testCase:              "power cycle on aws falls back to delete when allowed",
+			platform:              configv1.AWSPlatformType,
+			annotations:           map[string]string{allowDeleteFallbackAnnotation: ""},
+			expectedEvents:        []string{EventStrategyUnsupportedOnPlatform, EventMachineDeleted},
+			expectedHostRebooted:  false,
+			expectedMachineExists: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			mhc.Annotations = map[string]string{remediationStrategyAnnotation: string(remediationStrategyPowerCycle)}
+			for key, value := range tc.annotations {
+				mhc.Annotations[key] = value
+			}
+			machine := maotesting.NewMachine("machine", "node")
+			machine.Annotations[baremetalHostAnnotation] = namespace + "/host"
+			host := &unstructured.Unstructured{}
+			host.SetGroupVersionKind(baremetalHostGVK)
+			host.SetNamespace(namespace)
+			host.SetName("host")
+			target := target{
+				Machine: *machine,
+				Node:    maotesting.NewNode("node", false),
+				MHC:     *mhc,
+			}
+
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder, machine, host, newInfra(tc.platform))
+			if err := target.remediate(r); (err != nil) != tc.expectedError {
+				t.Fatalf("Expected error: %t, got: %v", tc.expectedError, err)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+
+			err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{})
+			if exists := !apierrors.IsNotFound(err); exists != tc.expectedMachineExists {
+				t.Errorf("Expected machine to exist: %t, got: %t", tc.expectedMachineExists, exists)
+			}
+			if err := r.client.Get(ctx, namespacedName(host), host); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, rebooted := host.GetAnnotations()[baremetalHostRebootAnnotation]; rebooted != tc.expectedHostRebooted {
+				t.Errorf("Expected host to be rebooted: %t, got: %t", tc.expectedHostRebooted, rebooted)
+			}
+		})
+	}
+}
+
+func TestReconcileUnsupportedPlatformStrategy(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("unsupportedStrategy")
+	mhc.Annotations = map[string]string{remediationStrategyAnnotation: string(remediationStrategyReboot)}
+	node := maotesting.NewNode("node", false)
+	machine := maotesting.NewMachine("machine", node.Name)
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: globalInfrastructureName},
+		Status: configv1.InfrastructureStatus{
+			PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType},
+		},
+	}
+
+	recorder := record.NewFakeRecorder(2)
+	r := newFakeReconcilerWithCustomRecorder(recorder, mhc, node, machine, infra)
+	result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+	if err != nil {
+		t.Errorf("Expected no requeue on unsupported strategy, got error: %v", err)
+	}
+	if result != (reconcile.Result{}) {
+		t.Errorf("Expected empty result, got %+v", result)
+	}
+	assertEvents(t, "unsupported strategy", []string{EventStrategyUnsupportedOnPlatform}, recorder.Events)
+
+	if err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{}); err != nil {
+		t.Errorf("Expected the machine not to be deleted: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// remediationStrategyPowerCycle remediates baremetal machines by power cycling their host
+	// through its BMC, instead of deleting the machine which would not reboot the hardware
+	remediationStrategyPowerCycle = mapiv1.RemediationStrategyType("power-cycle")
+	// remediationStrategyReboot remediates baremetal machines by rebooting their host,
+	// giving the operating system a chance to shut down cleanly
+	remediationStrategyReboot = mapiv1.RemediationStrategyType("reboot")
+	// baremetalHostAnnotation is set on baremetal machines by the baremetal actuator,
+	// it references the BareMetalHost of the machine as "namespace/name"
+	baremetalHostAnnotation = "metal3.io/BareMetalHost"
+	// baremetalHostRebootAnnotation requests the baremetal operator to reboot the host,
+	// it is removed by the baremetal operator once the host is rebooted
+	baremetalHostRebootAnnotation = "reboot.metal3.io"
+	// baremetalHostRebootHard requests a hard power cycle rather than a soft reboot,
+	// as the host of an unhealthy machine is unlikely to shut down cleanly
+	baremetalHostRebootHard = `{"mode":"hard"}`
+	// baremetalHostRebootSoft requests a soft reboot, the baremetal operator falls
+	// back to a hard power cycle if the host does not shut down in time
+	baremetalHostRebootSoft = `{"mode":"soft"}`
+
+	// EventPowerCycleRequested is emitted when the power cycle of the host of a
+	// machine was successfully requested
+	EventPowerCycleRequested string = "PowerCycleRequested"
+	// EventPowerCycleFailed is emitted in case requesting the power cycle of the
+	// host of a machine failed
+	EventPowerCycleFailed string = "PowerCycleFailed"
+	// EventRebootRequested is emitted when the reboot of the host of a
+	// machine was successfully requested
+	EventRebootRequested string = "RebootRequested"
+	// EventRebootFailed is emitted in case requesting the reboot of the
+	// host of a machine failed
+	EventRebootFailed string = "RebootFailed"
+)
+
+// hostReboot describes how a remediation strategy reboots the BareMetalHost of a machine
+type hostReboot struct {
+	strategy mapiv1.RemediationStrategyType
+	// mode is the value of the reboot annotation set on the host
+	mode           string
+	description    string
+	requestedEvent string
+	failedEvent    string
+}
+
+var (
+	powerCycleReboot = hostReboot{
+		strategy:       remediationStrategyPowerCycle,
+		mode:           baremetalHostRebootHard,
+		description:    "power cycle",
+		requestedEvent: EventPowerCycleRequested,
+		failedEvent:    EventPowerCycleFailed,
+	}
+	softReboot = hostReboot{
+		strategy:       remediationStrategyReboot,
+		mode:           baremetalHostRebootSoft,
+		description:    "reboot",
+		requestedEvent: EventRebootRequested,
+		failedEvent:    EventRebootFailed,
+	}
+)
+
+// baremetalHostGVK is the kind of the metal3 BareMetalHost objects managed by the operator.
+// The metal3 types are not vendored, hosts are handled as unstructured objects.
+var baremetalHostGVK = schema.GroupVersionKind{
+	Group:   "metal3.io",
+	Version: "v1alpha1",
+	Kind:    "BareMetalHost",
+}
+
+// baremetalHostKey returns the key of the BareMetalHost of the machine
+func baremetalHostKey(machine *mapiv1.Machine) (client.ObjectKey, error) {
+	value, ok := machine.Annotations[baremetalHostAnnotation]
+	if !ok {
+		return client.ObjectKey{}, fmt.Errorf("machine has no %s annotation", baremetalHostAnnotation)
+	}
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return client.ObjectKey{}, fmt.Errorf("invalid %s annotation %q, expected namespace/name", baremetalHostAnnotation, value)
+	}
+	return client.ObjectKey{Namespace: parts[0], Name: parts[1]}, nil
+}
+
+func (t *target) remediationStrategyPowerCycle(r *ReconcileMachineHealthCheck) error {
+	return t.remediateHostReboot(r, powerCycleReboot)
+}
+
+func (t *target) remediationStrategyReboot(r *ReconcileMachineHealthCheck) error {
+	return t.remediateHostReboot(r, softReboot)
+}
+
+func (t *target) remediateHostReboot(r *ReconcileMachineHealthCheck, reboot hostReboot) error {
+	if err := t.requestHostReboot(r, reboot); err != nil {
+		t.audit(r, string(reboot.strategy), err)
+		r.recorder.Eventf(
+			&t.Machine,
+			corev1.EventTypeWarning,
+			reboot.failedEvent,
+			"Requesting %s of host associated with machine %v failed: %v",
+			reboot.description,
+			t.string(),
+			err,
+		)
+		return err
+	}
+	return nil
+}
+
+// requestHostReboot sets the reboot annotation on the BareMetalHost of the target machine,
+// unless a reboot of the host is already pending
+func (t *target) requestHostReboot(r *ReconcileMachineHealthCheck, reboot hostReboot) error {
+	key, err := baremetalHostKey(&t.Machine)
+	if err != nil {
+		return fmt.Errorf("%s: failed to resolve host: %v", t.string(), err)
+	}
+
+	host := &unstructured.Unstructured{}
+	host.SetGroupVersionKind(baremetalHostGVK)
+	if err := r.client.Get(context.TODO(), key, host); err != nil {
+		return fmt.Errorf("%s: failed to get host %s: %v", t.string(), key, err)
+	}
+
+	annotations := host.GetAnnotations()
+	if _, ok := annotations[baremetalHostRebootAnnotation]; ok {
+		klog.V(3).Infof("%s: %s of host %s already requested", t.string(), reboot.description, key)
+		return nil
+	}
+
+	mergeBase := client.MergeFrom(host.DeepCopy())
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[baremetalHostRebootAnnotation] = reboot.mode
+	host.SetAnnotations(annotations)
+	if err := r.client.Patch(context.TODO(), host, mergeBase); err != nil {
+		return fmt.Errorf("%s: failed to annotate host %s: %v", t.string(), key, err)
+	}
+
+	klog.Infof("%s: requested %s of host %s", t.string(), reboot.description, key)
+	r.recorder.Eventf(
+		&t.Machine,
+		corev1.EventTypeNormal,
+		reboot.requestedEvent,
+		"Requesting %s of host %v associated with machine %v%s",
+		reboot.description,
+		key,
+		t.string(),
+		t.instanceDescription(),
+	)
+	t.observeTimeToRemediate()
+	t.recordRemediation(r, string(reboot.strategy))
+	t.audit(r, string(reboot.strategy), nil)
+	return nil
+}
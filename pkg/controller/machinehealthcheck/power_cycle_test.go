@@ -0,0 +1,114 @@
+package machinehealthcheck
+
+import (
+	"testing"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRemediatePowerCycle(t *testing.T) {
+	newHost := func(annotations map[string]string) *unstructured.Unstructured {
+		host := &unstructured.Unstructured{}
+		host.SetGroupVersionKind(baremetalHostGVK)
+		host.SetNamespace(namespace)
+		host.SetName("host")
+		host.SetAnnotations(annotations)
+		return host
+	}
+
+	testCases := []struct {
+		testCase           string
+		strategy           mapiv1beta1.RemediationStrategyType
+		hostAnnotation     string
+		host               *unstructured.Unstructured
+		expectedError      bool
+		expectedEvents     []string
+		expectedAnnotation string
+	}{
+		{
+			testCase:           "power cycle requested",
+			hostAnnotation:     namespace + "/host",
+			host:               newHost(nil),
+			expectedEvents:     []string{EventPowerCycleRequested},
+			expectedAnnotation: baremetalHostRebootHard,
+		},
+		{
+			testCase:           "reboot requested",
+			strategy:           remediationStrategyReboot,
+			hostAnnotation:     namespace + "/host",
+			host:               newHost(nil),
+			expectedEvents:     []string{EventRebootRequested},
+			expectedAnnotation: baremetalHostRebootSoft,
+		},
+		{
+			testCase:           "power cycle already pending",
+			hostAnnotation:     namespace + "/host",
+			host:               newHost(map[string]string{baremetalHostRebootAnnotation: ""}),
+			expectedEvents:     []string{},
+			expectedAnnotation: "",
+		},
+		{
+			testCase:       "machine without host",
+			host:           newHost(nil),
+			expectedError:  true,
+			expectedEvents: []string{EventPowerCycleFailed},
+		},
+		{
+			testCase:       "host not found",
+			hostAnnotation: namespace + "/missing",
+			host:           newHost(nil),
+			expectedError:  true,
+			expectedEvents: []string{EventPowerCycleFailed},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			strategy := remediationStrategyPowerCycle
+			if tc.strategy != "" {
+				strategy = tc.strategy
+			}
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			mhc.Annotations = map[string]string{remediationStrategyAnnotation: string(strategy)}
+			machine := maotesting.NewMachine("machine", "node")
+			if tc.hostAnnotation != "" {
+				machine.Annotations[baremetalHostAnnotation] = tc.hostAnnotation
+			}
+			target := target{
+				Machine: *machine,
+				Node:    maotesting.NewNode("node", false),
+				MHC:     *mhc,
+			}
+
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder, machine, tc.host)
+			if err := target.remediate(r); (err != nil) != tc.expectedError {
+				t.Errorf("Expected error: %t, got: %v", tc.expectedError, err)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+
+			// the host is rebooted rather than the machine deleted
+			if err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{}); apierrors.IsNotFound(err) {
+				t.Errorf("Expected machine not to be deleted")
+			}
+
+			host := newHost(nil)
+			if err := r.client.Get(ctx, namespacedName(tc.host), host); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tc.expectedError {
+				if _, ok := host.GetAnnotations()[baremetalHostRebootAnnotation]; ok {
+					t.Errorf("Expected host not to be annotated")
+				}
+				return
+			}
+			if got := host.GetAnnotations()[baremetalHostRebootAnnotation]; got != tc.expectedAnnotation {
+				t.Errorf("Expected %s annotation %q, got %q", baremetalHostRebootAnnotation, tc.expectedAnnotation, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+)
+
+// remediationBatchIntervalAnnotation enables batch remediation for a MachineHealthCheck. Its value is
+// the interval between two batches, e.g. "1h". Targets found unhealthy between two interval boundaries
+// are only remediated, within the remediation budgets, by the first reconcile after the next boundary.
+const remediationBatchIntervalAnnotation = "machine.openshift.io/remediation-batch-interval"
+
+// remediationBatchInterval returns the interval between two remediation batches,
+// and false if batch remediation is not enabled for the MachineHealthCheck
+func remediationBatchInterval(mhc *mapiv1.MachineHealthCheck) (time.Duration, bool, error) {
+	value, ok := mhc.Annotations[remediationBatchIntervalAnnotation]
+	if !ok {
+		return 0, false, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, false, &permanentError{err: fmt.Errorf("invalid %s annotation %q: %v", remediationBatchIntervalAnnotation, value, err)}
+	}
+	if interval <= 0 {
+		return 0, false, &permanentError{err: fmt.Errorf("invalid %s annotation %q: must be positive", remediationBatchIntervalAnnotation, value)}
+	}
+	return interval, true, nil
+}
+
+// remediationBatch is the state of the current remediation batch of an MHC
+type remediationBatch struct {
+	// boundary is the interval boundary the MHC was last reconciled after
+	boundary time.Time
+	// pending is true if targets were found unhealthy since the boundary
+	pending bool
+}
+
+// remediationBatchTracker accumulates the verdicts of each MHC between interval boundaries.
+// Boundaries are aligned to multiples of the interval since the zero time, so that they do not
+// depend on when the controller started.
+type remediationBatchTracker struct {
+	lock sync.Mutex
+	// batches maps MHCs to their current remediation batch
+	batches map[string]remediationBatch
+}
+
+// observe records whether targets of the given MHC need remediation and returns true if the MHC
+// crossed an interval boundary since targets were found unhealthy, so that the batch is due.
+// Otherwise the duration until the next boundary is returned. An MHC observed for the first time
+// waits for the next boundary, as its previous verdicts are unknown.
+func (b *remediationBatchTracker) observe(key string, interval time.Duration, now time.Time, unhealthy bool) (bool, time.Duration) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.batches == nil {
+		b.batches = map[string]remediationBatch{}
+	}
+	boundary := now.Truncate(interval)
+	batch, ok := b.batches[key]
+	if ok && boundary.After(batch.boundary) && batch.pending {
+		b.batches[key] = remediationBatch{boundary: boundary}
+		return true, 0
+	}
+	pending := ok && boundary.Equal(batch.boundary) && batch.pending
+	b.batches[key] = remediationBatch{boundary: boundary, pending: pending || unhealthy}
+	return false, boundary.Add(interval).Sub(now)
+}
+
+// forget removes the record of the given MHC, e.g. when it is deleted
+func (b *remediationBatchTracker) forget(key string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delete(b.batches, key)
+}
+
+// remediationBatchDue returns true if the targets of the MHC needing remediation may be remediated
+// now, which is always the case when batch remediation is disabled. Otherwise the duration until
+// the next interval boundary is returned.
+func (r *ReconcileMachineHealthCheck) remediationBatchDue(mhc *mapiv1.MachineHealthCheck, needRemediationTargets []target) (bool, time.Duration, error) {
+	interval, enabled, err := remediationBatchInterval(mhc)
+	if err != nil || !enabled {
+		return !enabled, 0, err
+	}
+	due, untilBoundary := r.remediationBatches.observe(namespacedName(mhc).String(), interval, r.now(), len(needRemediationTargets) > 0)
+	return due, untilBoundary, nil
+}
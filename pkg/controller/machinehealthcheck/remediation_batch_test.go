@@ -0,0 +1,102 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestRemediationBatchInterval(t *testing.T) {
+	testCases := []struct {
+		annotation       string
+		expectedInterval time.Duration
+		expectedEnabled  bool
+		expectedError    bool
+	}{
+		{annotation: "", expectedError: true},
+		{annotation: "1h", expectedInterval: time.Hour, expectedEnabled: true},
+		{annotation: "0s", expectedError: true},
+		{annotation: "hourly", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		mhc := maotesting.NewMachineHealthCheck("batch")
+		mhc.Annotations = map[string]string{remediationBatchIntervalAnnotation: tc.annotation}
+		interval, enabled, err := remediationBatchInterval(mhc)
+		if (err != nil) != tc.expectedError {
+			t.Errorf("Annotation %q: expected error: %t, got: %v", tc.annotation, tc.expectedError, err)
+		}
+		if interval != tc.expectedInterval || enabled != tc.expectedEnabled {
+			t.Errorf("Annotation %q: expected %v, %t, got %v, %t", tc.annotation, tc.expectedInterval, tc.expectedEnabled, interval, enabled)
+		}
+	}
+
+	if _, enabled, err := remediationBatchInterval(maotesting.NewMachineHealthCheck("immediate")); enabled || err != nil {
+		t.Errorf("Expected batch remediation to be disabled without annotation, got: %t, %v", enabled, err)
+	}
+}
+
+func TestReconcileRemediationBatch(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("batch")
+	mhc.Annotations = map[string]string{remediationBatchIntervalAnnotation: "1h"}
+	node := maotesting.NewNode("node", false)
+	machine := maotesting.NewMachine("fakeMachine", node.Name)
+
+	recorder := record.NewFakeRecorder(2)
+	r := newFakeReconcilerWithCustomRecorder(recorder, mhc, node, machine)
+	fakeClock := clock.NewFakeClock(time.Date(2021, time.March, 1, 12, 10, 0, 0, time.UTC))
+	r.clock = fakeClock
+
+	steps := []struct {
+		step            string
+		now             time.Time
+		expectedResult  reconcile.Result
+		expectedDeleted bool
+		expectedEvents  []string
+	}{
+		{
+			step:            "unhealthy target found between boundaries",
+			now:             time.Date(2021, time.March, 1, 12, 10, 0, 0, time.UTC),
+			expectedResult:  reconcile.Result{RequeueAfter: 50 * time.Minute},
+			expectedDeleted: false,
+			expectedEvents:  []string{},
+		},
+		{
+			step:            "unhealthy target still held before the boundary",
+			now:             time.Date(2021, time.March, 1, 12, 40, 0, 0, time.UTC),
+			expectedResult:  reconcile.Result{RequeueAfter: 20 * time.Minute},
+			expectedDeleted: false,
+			expectedEvents:  []string{},
+		},
+		{
+			step:            "unhealthy target remediated at the boundary",
+			now:             time.Date(2021, time.March, 1, 13, 0, 0, 0, time.UTC),
+			expectedResult:  reconcile.Result{},
+			expectedDeleted: true,
+			expectedEvents:  []string{EventMachineDeleted},
+		},
+	}
+
+	for _, step := range steps {
+		fakeClock.SetTime(step.now)
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.step, err)
+		}
+		if result != step.expectedResult {
+			t.Errorf("%s: expected result %+v, got %+v", step.step, step.expectedResult, result)
+		}
+		assertEvents(t, step.step, step.expectedEvents, recorder.Events)
+
+		err = r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{})
+		if deleted := apierrors.IsNotFound(err); deleted != step.expectedDeleted {
+			t.Errorf("%s: expected deleted: %t, got: %v", step.step, step.expectedDeleted, err)
+		}
+	}
+}
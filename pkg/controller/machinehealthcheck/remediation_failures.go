@@ -0,0 +1,85 @@
+package machinehealthcheck
+
+import (
+	"sync"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+)
+
+// remediationFailureThreshold is the number of consecutive reconciles of an MHC failing to remediate
+// its targets after which remediation is reported as failing, e.g. to degrade the ClusterOperator
+const remediationFailureThreshold = 3
+
+// remediationFailureTracker counts the consecutive reconciles of each MHC which failed to remediate
+// some of its targets
+type remediationFailureTracker struct {
+	lock sync.Mutex
+	// failures maps MHCs to their number of consecutive failing reconciles
+	failures map[string]int
+}
+
+// observe records the outcome of the remediations of a reconcile of the given MHC and returns the
+// number of consecutive reconciles which failed to remediate, it is reset by a reconcile without failures
+func (f *remediationFailureTracker) observe(key string, failed bool) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !failed {
+		delete(f.failures, key)
+		return 0
+	}
+	if f.failures == nil {
+		f.failures = map[string]int{}
+	}
+	f.failures[key]++
+	return f.failures[key]
+}
+
+// count returns the number of consecutive reconciles of the given MHC which failed to remediate
+func (f *remediationFailureTracker) count(key string) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.failures[key]
+}
+
+// forget removes the record of the given MHC, e.g. when it is deleted
+func (f *remediationFailureTracker) forget(key string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.failures, key)
+}
+
+// setRemediationSucceedingCondition records the outcome of the remediations of a reconcile and
+// sets the RemediationSucceeding condition of the MHC to False once remediation failed in
+// remediationFailureThreshold consecutive reconciles, or while the deletion of masters is refused.
+// A refused deletion neither fails nor succeeds, it leaves the count of failing reconciles as is.
+// The condition is set back to True after a reconcile without failures or refused deletions, it is
+// not set on MHCs whose remediation never failed repeatedly nor was refused.
+func (r *ReconcileMachineHealthCheck) setRemediationSucceedingCondition(mhc *mapiv1.MachineHealthCheck, failedRemediations, blockedRemediations int) {
+	key := namespacedName(mhc).String()
+	failures := r.remediationFailures.count(key)
+	if failedRemediations > 0 || blockedRemediations == 0 {
+		failures = r.remediationFailures.observe(key, failedRemediations > 0)
+	}
+	if failures >= remediationFailureThreshold {
+		conditions.Set(mhc, conditions.FalseCondition(
+			mapiv1.RemediationSucceedingCondition,
+			mapiv1.RepeatedRemediationFailuresReason,
+			mapiv1.ConditionSeverityError,
+			"remediation failed in %d consecutive reconciles, %d machines failed to be remediated last",
+			failures,
+			failedRemediations,
+		))
+		return
+	}
+	if blockedRemediations > 0 {
+		r.setControlPlaneDeletionBlockedCondition(mhc, blockedRemediations)
+		return
+	}
+	if failures == 0 && conditions.Get(mhc, mapiv1.RemediationSucceedingCondition) != nil {
+		conditions.MarkTrue(mhc, mapiv1.RemediationSucceedingCondition)
+	}
+}
@@ -0,0 +1,66 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// deleteErrorClient fails all Delete calls with the given error
+type deleteErrorClient struct {
+	client.Client
+	err error
+}
+
+func (c *deleteErrorClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return c.err
+}
+
+func TestReconcileRepeatedRemediationFailures(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("failing")
+	node := maotesting.NewNode("node", false)
+	machine := maotesting.NewMachine("machine", node.Name)
+
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(100), mhc, node, machine)
+	fakeClient := r.client
+	r.client = &deleteErrorClient{Client: fakeClient, err: errors.New("delete failed")}
+
+	remediationSucceeding := func() *mapiv1beta1.Condition {
+		got := &mapiv1beta1.MachineHealthCheck{}
+		if err := fakeClient.Get(ctx, namespacedName(mhc), got); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return conditions.Get(got, mapiv1beta1.RemediationSucceedingCondition)
+	}
+
+	for i := 1; i <= remediationFailureThreshold; i++ {
+		if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err == nil {
+			t.Fatalf("Expected reconcile %d to fail remediating", i)
+		}
+		condition := remediationSucceeding()
+		if i < remediationFailureThreshold && condition != nil {
+			t.Errorf("Expected no RemediationSucceeding condition after %d failures, got %+v", i, condition)
+		}
+	}
+	condition := remediationSucceeding()
+	if condition == nil || condition.Status != corev1.ConditionFalse || condition.Reason != mapiv1beta1.RepeatedRemediationFailuresReason {
+		t.Fatalf("Expected RemediationSucceeding to be False with reason %s, got %+v", mapiv1beta1.RepeatedRemediationFailuresReason, condition)
+	}
+
+	// remediation recovers
+	r.client = fakeClient
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if condition := remediationSucceeding(); condition == nil || condition.Status != corev1.ConditionTrue {
+		t.Errorf("Expected RemediationSucceeding to be True, got %+v", condition)
+	}
+}
@@ -0,0 +1,89 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"sync"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+)
+
+// remediationStrategy remediates unhealthy targets. Strategies are selected per
+// MachineHealthCheck with the machine.openshift.io/remediation-strategy annotation.
+type remediationStrategy interface {
+	remediate(t *target, r *ReconcileMachineHealthCheck) error
+}
+
+// remediationStrategyFunc adapts a function to a remediationStrategy, its arguments
+// are in the order of the target methods, e.g. (*target).remediationStrategyDelete
+type remediationStrategyFunc func(t *target, r *ReconcileMachineHealthCheck) error
+
+func (f remediationStrategyFunc) remediate(t *target, r *ReconcileMachineHealthCheck) error {
+	return f(t, r)
+}
+
+// remediationStrategyRegistry holds the remediation strategies available to MachineHealthChecks
+type remediationStrategyRegistry struct {
+	lock       sync.RWMutex
+	strategies map[mapiv1.RemediationStrategyType]remediationStrategy
+}
+
+func newRemediationStrategyRegistry() *remediationStrategyRegistry {
+	return &remediationStrategyRegistry{
+		strategies: map[mapiv1.RemediationStrategyType]remediationStrategy{},
+	}
+}
+
+// defaultRemediationStrategies returns a registry holding the built-in remediation strategies
+func defaultRemediationStrategies() *remediationStrategyRegistry {
+	registry := newRemediationStrategyRegistry()
+	for name, strategy := range map[mapiv1.RemediationStrategyType]remediationStrategyFunc{
+		remediationStrategyDelete:     (*target).remediationStrategyDelete,
+		remediationStrategyReboot:     (*target).remediationStrategyReboot,
+		remediationStrategyPowerCycle: (*target).remediationStrategyPowerCycle,
+		remediationStrategyExternal:   (*target).remediationStrategyExternal,
+		remediationStrategyGitOps:     (*target).remediationStrategyGitOps,
+		remediationStrategyNoop:       (*target).remediationStrategyNoop,
+	} {
+		if err := registry.register(name, strategy); err != nil {
+			panic(err)
+		}
+	}
+	return registry
+}
+
+// register makes the strategy available under the given name,
+// names may only be registered once
+func (sr *remediationStrategyRegistry) register(name mapiv1.RemediationStrategyType, strategy remediationStrategy) error {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	if name == "" {
+		return fmt.Errorf("remediation strategy name must not be empty")
+	}
+	if _, ok := sr.strategies[name]; ok {
+		return fmt.Errorf("remediation strategy %q is already registered", name)
+	}
+	sr.strategies[name] = strategy
+	return nil
+}
+
+// lookup returns the strategy registered under the given name
+func (sr *remediationStrategyRegistry) lookup(name mapiv1.RemediationStrategyType) (remediationStrategy, error) {
+	sr.lock.RLock()
+	defer sr.lock.RUnlock()
+
+	strategy, ok := sr.strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown remediation strategy %q", name)
+	}
+	return strategy, nil
+}
+
+// strategies returns the remediation strategies of the reconciler,
+// the built-in strategies are used when none were configured
+func (r *ReconcileMachineHealthCheck) strategies() *remediationStrategyRegistry {
+	if r.remediationStrategies == nil {
+		return defaultRemediationStrategies()
+	}
+	return r.remediationStrategies
+}
@@ -0,0 +1,113 @@
+package machinehealthcheck
+
+import (
+	"testing"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRemediationStrategyRegistry(t *testing.T) {
+	noop := remediationStrategyFunc(func(*target, *ReconcileMachineHealthCheck) error { return nil })
+
+	registry := defaultRemediationStrategies()
+	for _, name := range []mapiv1beta1.RemediationStrategyType{
+		remediationStrategyDelete,
+		remediationStrategyReboot,
+		remediationStrategyPowerCycle,
+		remediationStrategyExternal,
+	} {
+		if _, err := registry.lookup(name); err != nil {
+			t.Errorf("Expected built-in strategy %q to be registered: %v", name, err)
+		}
+	}
+
+	if err := registry.register("annotate", noop); err != nil {
+		t.Errorf("Unexpected error registering strategy: %v", err)
+	}
+	if _, err := registry.lookup("annotate"); err != nil {
+		t.Errorf("Expected registered strategy to be found: %v", err)
+	}
+	if err := registry.register("annotate", noop); err == nil {
+		t.Errorf("Expected error registering strategy twice")
+	}
+	if err := registry.register(remediationStrategyDelete, noop); err == nil {
+		t.Errorf("Expected error overriding built-in strategy")
+	}
+	if err := registry.register("", noop); err == nil {
+		t.Errorf("Expected error registering strategy without name")
+	}
+	if _, err := registry.lookup("unknown"); err == nil {
+		t.Errorf("Expected error looking up unknown strategy")
+	}
+}
+
+func TestRemediateWithRegisteredStrategy(t *testing.T) {
+	testCases := []struct {
+		testCase        string
+		strategy        string
+		phase           string
+		expectedCalled  bool
+		expectedDeleted bool
+	}{
+		{
+			testCase:        "registered strategy",
+			strategy:        "custom",
+			expectedCalled:  true,
+			expectedDeleted: false,
+		},
+		{
+			testCase:        "unknown strategy falls back to delete",
+			strategy:        "unknown",
+			expectedCalled:  false,
+			expectedDeleted: true,
+		},
+		{
+			testCase:        "failed machine is deleted",
+			strategy:        "custom",
+			phase:           machinePhaseFailed,
+			expectedCalled:  false,
+			expectedDeleted: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			mhc.Annotations = map[string]string{remediationStrategyAnnotation: tc.strategy}
+			machine := maotesting.NewMachine("machine", "node")
+			if tc.phase != "" {
+				machine.Status.Phase = &tc.phase
+			}
+			unhealthy := target{
+				Machine: *machine,
+				Node:    maotesting.NewNode("node", false),
+				MHC:     *mhc,
+			}
+
+			r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(2), machine)
+			r.remediationStrategies = defaultRemediationStrategies()
+			var called bool
+			custom := remediationStrategyFunc(func(*target, *ReconcileMachineHealthCheck) error {
+				called = true
+				return nil
+			})
+			if err := r.remediationStrategies.register("custom", custom); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if err := unhealthy.remediate(r); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if called != tc.expectedCalled {
+				t.Errorf("Expected custom strategy called: %t, got: %t", tc.expectedCalled, called)
+			}
+			err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{})
+			if deleted := apierrors.IsNotFound(err); deleted != tc.expectedDeleted {
+				t.Errorf("Expected machine deleted: %t, got: %v", tc.expectedDeleted, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"time"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+)
+
+const remediationWindowTimeFormat = "15:04"
+
+// parseTimeOfDay parses a "HH:MM" time of day into its offset from midnight
+func parseTimeOfDay(timeOfDay string) (time.Duration, error) {
+	t, err := time.Parse(remediationWindowTimeFormat, timeOfDay)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %v", timeOfDay, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// inRemediationWindow returns true if now is within the remediation window. If it is
+// not, the duration until the window opens is returned as well. A nil window is
+// always open.
+func inRemediationWindow(window *mapiv1.RemediationWindow, now time.Time) (bool, time.Duration, error) {
+	if window == nil {
+		return true, 0, nil
+	}
+	start, err := parseTimeOfDay(window.Start)
+	if err != nil {
+		return false, 0, &permanentError{err: fmt.Errorf("invalid remediation window start: %v", err)}
+	}
+	end, err := parseTimeOfDay(window.End)
+	if err != nil {
+		return false, 0, &permanentError{err: fmt.Errorf("invalid remediation window end: %v", err)}
+	}
+
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	offset := now.Sub(midnight)
+
+	var inWindow bool
+	switch {
+	case start == end:
+		inWindow = true
+	case start < end:
+		inWindow = offset >= start && offset < end
+	default:
+		// the window spans midnight
+		inWindow = offset >= start || offset < end
+	}
+	if inWindow {
+		return true, 0, nil
+	}
+
+	untilStart := start - offset
+	if untilStart < 0 {
+		untilStart += 24 * time.Hour
+	}
+	return false, untilStart, nil
+}
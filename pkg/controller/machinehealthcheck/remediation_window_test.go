@@ -0,0 +1,155 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestInRemediationWindow(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2021, time.March, 1, hour, minute, 0, 0, time.UTC)
+	}
+	businessHours := &mapiv1beta1.RemediationWindow{Start: "09:00", End: "17:00"}
+	overnight := &mapiv1beta1.RemediationWindow{Start: "22:00", End: "06:00"}
+
+	testCases := []struct {
+		testCase            string
+		window              *mapiv1beta1.RemediationWindow
+		now                 time.Time
+		expectedInWindow    bool
+		expectedUntilWindow time.Duration
+		expectedError       bool
+	}{
+		{
+			testCase:         "no window",
+			window:           nil,
+			now:              at(3, 0),
+			expectedInWindow: true,
+		},
+		{
+			testCase:         "within window",
+			window:           businessHours,
+			now:              at(12, 30),
+			expectedInWindow: true,
+		},
+		{
+			testCase:         "at window start",
+			window:           businessHours,
+			now:              at(9, 0),
+			expectedInWindow: true,
+		},
+		{
+			testCase:            "at window end",
+			window:              businessHours,
+			now:                 at(17, 0),
+			expectedInWindow:    false,
+			expectedUntilWindow: 16 * time.Hour,
+		},
+		{
+			testCase:            "before window",
+			window:              businessHours,
+			now:                 at(7, 30),
+			expectedInWindow:    false,
+			expectedUntilWindow: 90 * time.Minute,
+		},
+		{
+			testCase:         "within window spanning midnight",
+			window:           overnight,
+			now:              at(2, 0),
+			expectedInWindow: true,
+		},
+		{
+			testCase:            "outside window spanning midnight",
+			window:              overnight,
+			now:                 at(12, 0),
+			expectedInWindow:    false,
+			expectedUntilWindow: 10 * time.Hour,
+		},
+		{
+			testCase:         "whole day window",
+			window:           &mapiv1beta1.RemediationWindow{Start: "00:00", End: "00:00"},
+			now:              at(12, 0),
+			expectedInWindow: true,
+		},
+		{
+			testCase:      "invalid window",
+			window:        &mapiv1beta1.RemediationWindow{Start: "9am", End: "17:00"},
+			now:           at(12, 0),
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			inWindow, untilWindow, err := inRemediationWindow(tc.window, tc.now)
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("Expected error: %t, got: %v", tc.expectedError, err)
+			}
+			if inWindow != tc.expectedInWindow {
+				t.Errorf("Expected in window: %t, got: %t", tc.expectedInWindow, inWindow)
+			}
+			if untilWindow != tc.expectedUntilWindow {
+				t.Errorf("Expected window to open in %v, got: %v", tc.expectedUntilWindow, untilWindow)
+			}
+		})
+	}
+}
+
+func TestReconcileRemediationWindow(t *testing.T) {
+	testCases := []struct {
+		testCase        string
+		now             time.Time
+		expectedResult  reconcile.Result
+		expectedDeleted bool
+		expectedEvents  []string
+	}{
+		{
+			testCase:        "in window",
+			now:             time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC),
+			expectedResult:  reconcile.Result{},
+			expectedDeleted: true,
+			expectedEvents:  []string{EventMachineDeleted},
+		},
+		{
+			testCase:        "out of window",
+			now:             time.Date(2021, time.March, 1, 8, 30, 0, 0, time.UTC),
+			expectedResult:  reconcile.Result{RequeueAfter: 30 * time.Minute},
+			expectedDeleted: false,
+			expectedEvents:  []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("window")
+			mhc.Spec.RemediationWindow = &mapiv1beta1.RemediationWindow{Start: "09:00", End: "17:00"}
+			node := maotesting.NewNode("node", false)
+			machine := maotesting.NewMachine("fakeMachine", node.Name)
+
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder, mhc, node, machine)
+			r.clock = clock.NewFakePassiveClock(tc.now)
+
+			result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tc.expectedResult {
+				t.Errorf("Expected result %+v, got %+v", tc.expectedResult, result)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+
+			err = r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{})
+			if deleted := apierrors.IsNotFound(err); deleted != tc.expectedDeleted {
+				t.Errorf("Expected deleted: %t, got: %v", tc.expectedDeleted, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// EventShortConditionTimeout is emitted in case unhealthy condition timeouts of a
+// MachineHealthCheck are shorter than the node status update interval allows for
+const EventShortConditionTimeout string = "ShortConditionTimeout"
+
+// shortConditionTimeouts returns the unhealthy conditions of the MHC, including those implied by
+// nodeReadyTimeout, whose timeout is below minTimeout. Such timeouts may expire between two regular
+// node status updates, so that healthy nodes are remediated. No condition is returned when
+// minTimeout is zero.
+func shortConditionTimeouts(mhc *mapiv1.MachineHealthCheck, minTimeout time.Duration) []mapiv1.UnhealthyCondition {
+	var short []mapiv1.UnhealthyCondition
+	for _, c := range unhealthyConditions(mhc) {
+		if c.Timeout.Duration < minTimeout {
+			short = append(short, c)
+		}
+	}
+	return short
+}
+
+// warnShortConditionTimeouts reports the unhealthy conditions of the MHC whose timeout is below
+// the configured minimum. The MHC is still reconciled, as the timeouts may be intended.
+func (r *ReconcileMachineHealthCheck) warnShortConditionTimeouts(mhc *mapiv1.MachineHealthCheck) {
+	short := shortConditionTimeouts(mhc, r.minConditionTimeout)
+	metrics.ObserveMachineHealthCheckShortConditionTimeouts(mhc.Name, mhc.Namespace, len(short))
+	if len(short) == 0 {
+		return
+	}
+
+	descriptions := make([]string, 0, len(short))
+	for _, c := range short {
+		descriptions = append(descriptions, fmt.Sprintf("%s=%s after %v", c.Type, c.Status, c.Timeout.Duration))
+	}
+	message := fmt.Sprintf("unhealthy condition timeouts below %v may expire between node status updates: %s",
+		r.minConditionTimeout, strings.Join(descriptions, ", "))
+	klog.Warningf("%s/%s: %s", mhc.Namespace, mhc.Name, message)
+	r.recorder.Event(mhc, corev1.EventTypeWarning, EventShortConditionTimeout, message)
+}
@@ -0,0 +1,69 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestWarnShortConditionTimeouts(t *testing.T) {
+	testCases := []struct {
+		timeout        string
+		expectedEvents []string
+		expectedShort  float64
+	}{
+		{
+			timeout:        "5s",
+			expectedEvents: []string{EventShortConditionTimeout},
+			expectedShort:  1,
+		},
+		{
+			timeout:        "300s",
+			expectedEvents: []string{},
+			expectedShort:  0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.timeout, func(t *testing.T) {
+			timeout, err := time.ParseDuration(tc.timeout)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			mhc := maotesting.NewMachineHealthCheck("short-" + tc.timeout)
+			mhc.Spec.UnhealthyConditions = []mapiv1beta1.UnhealthyCondition{
+				{
+					Type:    corev1.NodeReady,
+					Status:  corev1.ConditionFalse,
+					Timeout: metav1.Duration{Duration: timeout},
+				},
+			}
+
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder, mhc)
+			r.minConditionTimeout = 40 * time.Second
+			r.warnShortConditionTimeouts(mhc)
+			assertEvents(t, tc.timeout, tc.expectedEvents, recorder.Events)
+
+			gauge, err := metrics.MachineHealthCheckShortConditionTimeouts.GetMetricWithLabelValues(mhc.Name, mhc.Namespace)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			metric := &dto.Metric{}
+			if err := gauge.(prometheus.Metric).Write(metric); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := metric.GetGauge().GetValue(); got != tc.expectedShort {
+				t.Errorf("Expected %v short condition timeouts, got %v", tc.expectedShort, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	corev1 "k8s.io/api/core/v1"
+	apimachineryerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// soleMemberPolicyDelete deletes the sole machine of a MachineSet like any other machine
+	soleMemberPolicyDelete = "Delete"
+	// soleMemberPolicyScaleFirst scales the MachineSet of a sole machine up by one and deletes
+	// the machine once the MachineSet has a ready replacement, then scales the MachineSet back down
+	soleMemberPolicyScaleFirst = "ScaleFirst"
+	// soleMemberPolicyRefuse never deletes the sole machine of a MachineSet
+	soleMemberPolicyRefuse = "Refuse"
+
+	// remediationSurgeAnnotation is set on a MachineSet scaled up to replace its sole machine,
+	// it records the name of the machine being replaced
+	remediationSurgeAnnotation = "machine.openshift.io/remediation-surge-for"
+
+	// soleMemberRequeue is the delay after which the deletion of a sole machine is retried
+	soleMemberRequeue = 30 * time.Second
+
+	// EventMachineSetScaledForRemediation is emitted when the MachineSet of a sole machine
+	// is scaled up so that the machine can be deleted without losing capacity
+	EventMachineSetScaledForRemediation string = "MachineSetScaledForRemediation"
+	// EventSoleMemberRemediationRefused is emitted in case the deletion of the sole machine
+	// of a MachineSet is refused
+	EventSoleMemberRemediationRefused string = "SoleMemberRemediationRefused"
+)
+
+// soleMemberError is returned when the deletion of the sole machine of a
+// MachineSet is deferred and should be retried later
+type soleMemberError struct {
+	target     string
+	machineSet string
+	reason     string
+}
+
+func (e *soleMemberError) Error() string {
+	return fmt.Sprintf("%s: sole machine of MachineSet %q, %s", e.target, e.machineSet, e.reason)
+}
+
+// parseSoleMemberPolicy validates the given policy for the sole machine of a MachineSet,
+// an empty policy deletes the sole machine like any other machine
+func parseSoleMemberPolicy(policy string) (string, error) {
+	switch policy {
+	case "":
+		return soleMemberPolicyDelete, nil
+	case soleMemberPolicyDelete, soleMemberPolicyScaleFirst, soleMemberPolicyRefuse:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid sole MachineSet member policy %q, must be one of %q, %q or %q",
+		policy,
+		soleMemberPolicyDelete,
+		soleMemberPolicyScaleFirst,
+		soleMemberPolicyRefuse,
+	)
+}
+
+// prepareSoleMemberDeletion applies the sole member policy before the machine of the target is
+// deleted. It returns the MachineSet to scale back down once the machine is deleted, if any, or
+// a soleMemberError if the machine must not be deleted yet.
+func (t *target) prepareSoleMemberDeletion(r *ReconcileMachineHealthCheck) (*mapiv1.MachineSet, error) {
+	if r.soleMemberPolicy == "" || r.soleMemberPolicy == soleMemberPolicyDelete {
+		return nil, nil
+	}
+	name := getMachineSetFromMachine(t.Machine)
+	if name == "" {
+		return nil, nil
+	}
+
+	machineSet := &mapiv1.MachineSet{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: t.Machine.Namespace, Name: name}, machineSet); err != nil {
+		return nil, fmt.Errorf("%s: failed to get MachineSet %q: %v", t.string(), name, err)
+	}
+
+	// the MachineSet was already scaled up to replace the machine
+	if machineSet.Annotations[remediationSurgeAnnotation] == t.Machine.Name {
+		ready, err := t.hasReadyReplacement(r, machineSet)
+		if err != nil {
+			return nil, err
+		}
+		if !ready {
+			return nil, &soleMemberError{target: t.string(), machineSet: name, reason: "waiting for its replacement to be ready"}
+		}
+		return machineSet, nil
+	}
+
+	if pointer.Int32PtrDerefOr(machineSet.Spec.Replicas, 1) != 1 {
+		return nil, nil
+	}
+
+	if r.soleMemberPolicy == soleMemberPolicyRefuse {
+		r.recorder.Eventf(
+			&t.Machine,
+			corev1.EventTypeWarning,
+			EventSoleMemberRemediationRefused,
+			"Machine %v is the sole machine of MachineSet %v, refusing to remediate it by deletion",
+			t.string(),
+			name,
+		)
+		return nil, &soleMemberError{target: t.string(), machineSet: name, reason: "deletion refused"}
+	}
+
+	mergeBase := client.MergeFrom(machineSet.DeepCopy())
+	if machineSet.Annotations == nil {
+		machineSet.Annotations = map[string]string{}
+	}
+	machineSet.Annotations[remediationSurgeAnnotation] = t.Machine.Name
+	machineSet.Spec.Replicas = pointer.Int32Ptr(2)
+	if err := r.client.Patch(context.TODO(), machineSet, mergeBase); err != nil {
+		return nil, fmt.Errorf("%s: failed to scale up MachineSet %q: %v", t.string(), name, err)
+	}
+	klog.Infof("%s: scaled up MachineSet %q to replace its sole machine", t.string(), name)
+	r.recorder.Eventf(
+		&t.Machine,
+		corev1.EventTypeNormal,
+		EventMachineSetScaledForRemediation,
+		"Machine %v is the sole machine of MachineSet %v, scaling it up before remediating the machine by deletion",
+		t.string(),
+		name,
+	)
+	return nil, &soleMemberError{target: t.string(), machineSet: name, reason: "waiting for its replacement to be ready"}
+}
+
+// hasReadyReplacement returns true if another machine of the MachineSet, not being deleted, has a
+// ready node. The ready replicas of the MachineSet cannot be relied upon, as the unhealthy machine
+// being replaced usually has a node which is not ready.
+func (t *target) hasReadyReplacement(r *ReconcileMachineHealthCheck, machineSet *mapiv1.MachineSet) (bool, error) {
+	machineList := &mapiv1.MachineList{}
+	if err := r.client.List(context.TODO(), machineList, client.InNamespace(machineSet.Namespace)); err != nil {
+		return false, fmt.Errorf("%s: failed to list machines of MachineSet %q: %v", t.string(), machineSet.Name, err)
+	}
+	for _, machine := range machineList.Items {
+		if machine.Name == t.Machine.Name || !machine.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if getMachineSetFromMachine(machine) != machineSet.Name {
+			continue
+		}
+		node, err := r.getNodeFromMachine(machine)
+		if err != nil {
+			if apimachineryerrors.IsNotFound(err) {
+				continue
+			}
+			return false, fmt.Errorf("%s: failed to get node of machine %q: %v", t.string(), machine.Name, err)
+		}
+		if node == nil {
+			continue
+		}
+		if ready := conditions.GetNodeCondition(node, corev1.NodeReady); ready != nil && ready.Status == corev1.ConditionTrue {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// releaseSoleMemberSurge scales the MachineSet scaled up to replace the deleted machine
+// of the target back down by one
+func (t *target) releaseSoleMemberSurge(r *ReconcileMachineHealthCheck, machineSet *mapiv1.MachineSet) error {
+	mergeBase := client.MergeFrom(machineSet.DeepCopy())
+	delete(machineSet.Annotations, remediationSurgeAnnotation)
+	if replicas := pointer.Int32PtrDerefOr(machineSet.Spec.Replicas, 1); replicas > 1 {
+		machineSet.Spec.Replicas = pointer.Int32Ptr(replicas - 1)
+	}
+	if err := r.client.Patch(context.TODO(), machineSet, mergeBase); err != nil {
+		return fmt.Errorf("%s: failed to scale down MachineSet %q: %v", t.string(), machineSet.Name, err)
+	}
+	klog.Infof("%s: scaled down MachineSet %q after deleting its replaced machine", t.string(), machineSet.Name)
+	return nil
+}
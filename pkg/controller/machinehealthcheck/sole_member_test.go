@@ -0,0 +1,182 @@
+package machinehealthcheck
+
+import (
+	"errors"
+	"testing"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestParseSoleMemberPolicy(t *testing.T) {
+	for policy, expected := range map[string]string{
+		"":                         soleMemberPolicyDelete,
+		soleMemberPolicyDelete:     soleMemberPolicyDelete,
+		soleMemberPolicyScaleFirst: soleMemberPolicyScaleFirst,
+		soleMemberPolicyRefuse:     soleMemberPolicyRefuse,
+	} {
+		got, err := parseSoleMemberPolicy(policy)
+		if err != nil {
+			t.Errorf("Policy %q: unexpected error: %v", policy, err)
+		}
+		if got != expected {
+			t.Errorf("Policy %q: expected %q, got %q", policy, expected, got)
+		}
+	}
+	if _, err := parseSoleMemberPolicy("Drain"); err == nil {
+		t.Errorf("Expected an error for an invalid policy")
+	}
+}
+
+func TestRemediateSoleMember(t *testing.T) {
+	newMachineSet := func(replicas, readyReplicas int32, surgeFor string) *mapiv1beta1.MachineSet {
+		machineSet := &mapiv1beta1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "machineset",
+				Namespace:   namespace,
+				Annotations: map[string]string{},
+			},
+			Spec: mapiv1beta1.MachineSetSpec{
+				Replicas: pointer.Int32Ptr(replicas),
+			},
+			Status: mapiv1beta1.MachineSetStatus{
+				ReadyReplicas: readyReplicas,
+			},
+		}
+		if surgeFor != "" {
+			machineSet.Annotations[remediationSurgeAnnotation] = surgeFor
+		}
+		return machineSet
+	}
+
+	testCases := []struct {
+		testCase              string
+		policy                string
+		machineSet            *mapiv1beta1.MachineSet
+		replacementReady      *bool
+		expectedSoleMemberErr bool
+		expectedEvents        []string
+		expectedMachineExists bool
+		expectedReplicas      int32
+		expectedSurgeFor      string
+	}{
+		{
+			testCase:              "delete policy deletes the sole member",
+			policy:                soleMemberPolicyDelete,
+			machineSet:            newMachineSet(1, 1, ""),
+			expectedEvents:        []string{EventMachineDeleted},
+			expectedMachineExists: false,
+			expectedReplicas:      1,
+		},
+		{
+			testCase:              "scale first policy scales up the MachineSet of the sole member",
+			policy:                soleMemberPolicyScaleFirst,
+			machineSet:            newMachineSet(1, 1, ""),
+			expectedSoleMemberErr: true,
+			expectedEvents:        []string{EventMachineSetScaledForRemediation},
+			expectedMachineExists: true,
+			expectedReplicas:      2,
+			expectedSurgeFor:      "machine",
+		},
+		{
+			testCase:              "scale first policy waits for the replacement to be created",
+			policy:                soleMemberPolicyScaleFirst,
+			machineSet:            newMachineSet(2, 0, "machine"),
+			expectedSoleMemberErr: true,
+			expectedEvents:        []string{},
+			expectedMachineExists: true,
+			expectedReplicas:      2,
+			expectedSurgeFor:      "machine",
+		},
+		{
+			testCase:              "scale first policy waits for the replacement to be ready",
+			policy:                soleMemberPolicyScaleFirst,
+			machineSet:            newMachineSet(2, 0, "machine"),
+			replacementReady:      pointer.BoolPtr(false),
+			expectedSoleMemberErr: true,
+			expectedEvents:        []string{},
+			expectedMachineExists: true,
+			expectedReplicas:      2,
+			expectedSurgeFor:      "machine",
+		},
+		{
+			testCase:              "scale first policy deletes the sole member once replaced",
+			policy:                soleMemberPolicyScaleFirst,
+			machineSet:            newMachineSet(2, 1, "machine"),
+			replacementReady:      pointer.BoolPtr(true),
+			expectedEvents:        []string{EventMachineDeleted},
+			expectedMachineExists: false,
+			expectedReplicas:      1,
+		},
+		{
+			testCase:              "scale first policy does not apply to larger MachineSets",
+			policy:                soleMemberPolicyScaleFirst,
+			machineSet:            newMachineSet(3, 3, ""),
+			expectedEvents:        []string{EventMachineDeleted},
+			expectedMachineExists: false,
+			expectedReplicas:      3,
+		},
+		{
+			testCase:              "refuse policy refuses to delete the sole member",
+			policy:                soleMemberPolicyRefuse,
+			machineSet:            newMachineSet(1, 1, ""),
+			expectedSoleMemberErr: true,
+			expectedEvents:        []string{EventSoleMemberRemediationRefused},
+			expectedMachineExists: true,
+			expectedReplicas:      1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			machine := maotesting.NewMachine("machine", "node")
+			machine.OwnerReferences[0].Name = tc.machineSet.Name
+			// the node of the unhealthy member is not ready, so it never counts as a ready replica
+			node := maotesting.NewNode("node", false)
+			target := target{
+				Machine: *machine,
+				Node:    node,
+				MHC:     *maotesting.NewMachineHealthCheck("mhc"),
+			}
+
+			objects := []runtime.Object{machine, node, tc.machineSet}
+			if tc.replacementReady != nil {
+				replacement, replacementNode := newMachineSetMachine(tc.machineSet.Name, 1, *tc.replacementReady)
+				objects = append(objects, replacement, replacementNode)
+			}
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder, objects...)
+			r.soleMemberPolicy = tc.policy
+			err := target.remediate(r)
+			var soleErr *soleMemberError
+			if gotSoleErr := errors.As(err, &soleErr); gotSoleErr != tc.expectedSoleMemberErr {
+				t.Errorf("Expected sole member error: %t, got: %v", tc.expectedSoleMemberErr, err)
+			}
+			if err != nil && soleErr == nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+
+			err = r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{})
+			if exists := !apierrors.IsNotFound(err); exists != tc.expectedMachineExists {
+				t.Errorf("Expected machine to exist: %t, got: %t", tc.expectedMachineExists, exists)
+			}
+
+			machineSet := &mapiv1beta1.MachineSet{}
+			if err := r.client.Get(ctx, namespacedName(tc.machineSet), machineSet); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := pointer.Int32PtrDerefOr(machineSet.Spec.Replicas, 1); got != tc.expectedReplicas {
+				t.Errorf("Expected %d replicas, got %d", tc.expectedReplicas, got)
+			}
+			if got := machineSet.Annotations[remediationSurgeAnnotation]; got != tc.expectedSurgeFor {
+				t.Errorf("Expected %s annotation %q, got %q", remediationSurgeAnnotation, tc.expectedSurgeFor, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// statusReport is the JSON document describing the evaluation of the targets of a MachineHealthCheck
+type statusReport struct {
+	MachineHealthCheck string               `json:"machineHealthCheck"`
+	Selector           string               `json:"selector"`
+	Targets            []targetStatusReport `json:"targets"`
+}
+
+// targetStatusReport describes the evaluation of a single target
+type targetStatusReport struct {
+	Machine            string `json:"machine"`
+	Node               string `json:"node"`
+	Unhealthy          bool   `json:"unhealthy"`
+	UnhealthyCondition string `json:"unhealthyCondition,omitempty"`
+	NextCheck          string `json:"nextCheck,omitempty"`
+	Error              string `json:"error,omitempty"`
+}
+
+// StatusReport evaluates the targets of the named MachineHealthCheck in the namespace
+// of the reconciler and returns the results as JSON. Unlike Reconcile, it has no side
+// effects: no event is emitted and nothing is remediated.
+func (r *ReconcileMachineHealthCheck) StatusReport(mhcName string) ([]byte, error) {
+	mhc := &mapiv1.MachineHealthCheck{}
+	key := client.ObjectKey{Namespace: r.namespace, Name: mhcName}
+	if err := r.client.Get(context.TODO(), key, mhc); err != nil {
+		return nil, fmt.Errorf("failed to get MHC %s: %w", key, err)
+	}
+
+	selector, err := effectiveSelector(mhc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve selector of MHC %s: %w", key, err)
+	}
+
+	targets, err := r.getTargetsFromMHC(*mhc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get targets of MHC %s: %w", key, err)
+	}
+
+	report := statusReport{
+		MachineHealthCheck: key.String(),
+		Selector:           selector,
+		Targets:            []targetStatusReport{},
+	}
+	for _, t := range targets {
+		targetReport := targetStatusReport{
+			Machine: t.Machine.Name,
+			Node:    t.nodeName(),
+		}
+		needsRemediation, unhealthyCondition, nextCheck, err := t.needsRemediation(r.healthCheckTimeouts(mhc.Spec.NodeStartupTimeout.Duration))
+		switch {
+		case err != nil:
+			targetReport.Error = err.Error()
+		case needsRemediation:
+			targetReport.Unhealthy = true
+			targetReport.UnhealthyCondition = unhealthyCondition
+		case nextCheck > 0:
+			targetReport.NextCheck = nextCheck.String()
+		}
+		report.Targets = append(report.Targets, targetReport)
+	}
+
+	return json.Marshal(report)
+}
@@ -0,0 +1,96 @@
+package machinehealthcheck
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStatusReport(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("report")
+
+	healthyNode := maotesting.NewNode("healthy-node", true)
+	healthyMachine := maotesting.NewMachine("a-healthy", healthyNode.Name)
+
+	unhealthyNode := maotesting.NewNode("unhealthy-node", false)
+	unhealthyMachine := maotesting.NewMachine("b-unhealthy", unhealthyNode.Name)
+
+	// the node went unhealthy very recently, so it is not yet timed out
+	recentlyUnhealthyNode := maotesting.NewNode("recently-unhealthy-node", false)
+	recentlyUnhealthyNode.Status.Conditions[0].LastTransitionTime = metav1.Now()
+	recentlyUnhealthyMachine := maotesting.NewMachine("c-recently-unhealthy", recentlyUnhealthyNode.Name)
+
+	missingNodeMachine := maotesting.NewMachine("d-missing-node", "missing-node")
+
+	r := newFakeReconciler(
+		mhc,
+		healthyNode, healthyMachine,
+		unhealthyNode, unhealthyMachine,
+		recentlyUnhealthyNode, recentlyUnhealthyMachine,
+		missingNodeMachine,
+	)
+
+	data, err := r.StatusReport(mhc.Name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	report := map[string]interface{}{}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	targets, ok := report["targets"].([]interface{})
+	if !ok || len(targets) != 4 {
+		t.Fatalf("Expected 4 targets, got: %s", data)
+	}
+
+	// the next check of the recently unhealthy target depends on the current time
+	recentlyUnhealthy := targets[2].(map[string]interface{})
+	if _, ok := recentlyUnhealthy["nextCheck"].(string); !ok {
+		t.Errorf("Expected a next check for the recently unhealthy target, got: %v", recentlyUnhealthy)
+	}
+	delete(recentlyUnhealthy, "nextCheck")
+
+	expected := map[string]interface{}{
+		"machineHealthCheck": namespace + "/report",
+		"selector":           "foo=bar",
+		"targets": []interface{}{
+			map[string]interface{}{
+				"machine":   "a-healthy",
+				"node":      "healthy-node",
+				"unhealthy": false,
+			},
+			map[string]interface{}{
+				"machine":            "b-unhealthy",
+				"node":               "unhealthy-node",
+				"unhealthy":          true,
+				"unhealthyCondition": string(corev1.NodeReady) + "=" + string(corev1.ConditionUnknown),
+			},
+			map[string]interface{}{
+				"machine":   "c-recently-unhealthy",
+				"node":      "recently-unhealthy-node",
+				"unhealthy": false,
+			},
+			map[string]interface{}{
+				"machine":            "d-missing-node",
+				"node":               "missing-node",
+				"unhealthy":          true,
+				"unhealthyCondition": unhealthyConditionNodeNotFound,
+			},
+		},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("Expected report %v, got: %v", expected, report)
+	}
+}
+
+func TestStatusReportNotFound(t *testing.T) {
+	r := newFakeReconciler()
+	if _, err := r.StatusReport("missing"); err == nil {
+		t.Errorf("Expected an error for a missing MachineHealthCheck")
+	}
+}
@@ -0,0 +1,59 @@
+package machinehealthcheck
+
+import (
+	"sync"
+	"time"
+)
+
+// statusWriteCoalescer limits the status writes of each MachineHealthCheck to one per interval,
+// so that bursts of reconciles, e.g. caused by node churn, result in a single status write.
+// A status change within the interval is not lost: the MHC is requeued to the end of the
+// interval, when its status is evaluated and written again.
+type statusWriteCoalescer struct {
+	lock sync.Mutex
+	// interval is the minimum duration between status writes of a MHC,
+	// coalescing is disabled when zero
+	interval time.Duration
+	// lastWrite maps MHCs to the time of their last status write
+	lastWrite map[string]time.Time
+}
+
+// remaining returns the remaining duration before the status of the given MHC may be written again
+func (c *statusWriteCoalescer) remaining(key string, now time.Time) time.Duration {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.interval <= 0 {
+		return 0
+	}
+	last, ok := c.lastWrite[key]
+	if !ok {
+		return 0
+	}
+	if remaining := c.interval - now.Sub(last); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// record records a status write of the given MHC
+func (c *statusWriteCoalescer) record(key string, now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.interval <= 0 {
+		return
+	}
+	if c.lastWrite == nil {
+		c.lastWrite = map[string]time.Time{}
+	}
+	c.lastWrite[key] = now
+}
+
+// forget removes the record of the given MHC, e.g. once it is deleted
+func (c *statusWriteCoalescer) forget(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.lastWrite, key)
+}
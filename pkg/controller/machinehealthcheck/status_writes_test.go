@@ -0,0 +1,102 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// statusWriteCountingClient counts the status writes performed through it
+type statusWriteCountingClient struct {
+	client.Client
+	writes int
+}
+
+func (c *statusWriteCountingClient) Status() client.StatusWriter {
+	return &countingStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type countingStatusWriter struct {
+	client.StatusWriter
+	client *statusWriteCountingClient
+}
+
+func (w *countingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	w.client.writes++
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func (w *countingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	w.client.writes++
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
+func TestReconcileSkipsNoOpStatusWrites(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("no-op")
+	node := maotesting.NewNode("node", true)
+	machine := maotesting.NewMachine("machine", node.Name)
+	node.Annotations[machineAnnotationKey] = namespacedName(machine).String()
+
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), mhc, machine, node)
+	c := &statusWriteCountingClient{Client: r.client}
+	r.client = c
+
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if c.writes > 1 {
+		t.Errorf("Expected at most 1 status write, got %d", c.writes)
+	}
+}
+
+func TestReconcileCoalescesStatusWrites(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("coalesced")
+	node := maotesting.NewNode("node", true)
+	machine := maotesting.NewMachine("machine", node.Name)
+	node.Annotations[machineAnnotationKey] = namespacedName(machine).String()
+
+	fakeClock := clock.NewFakeClock(time.Now())
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), mhc, machine, node)
+	r.clock = fakeClock
+	r.statusWrites.interval = time.Minute
+	c := &statusWriteCountingClient{Client: r.client}
+	r.client = c
+
+	reconcileMHC := func(pass string, expectedResult reconcile.Result, expectedWrites int) {
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", pass, err)
+		}
+		if result != expectedResult {
+			t.Errorf("%s: expected result %+v, got %+v", pass, expectedResult, result)
+		}
+		if c.writes != expectedWrites {
+			t.Errorf("%s: expected %d status writes, got %d", pass, expectedWrites, c.writes)
+		}
+	}
+
+	reconcileMHC("first pass", reconcile.Result{}, 1)
+
+	// a new machine changes the status within the interval, the write is deferred
+	fakeClock.Step(10 * time.Second)
+	newNode := maotesting.NewNode("new-node", true)
+	newMachine := maotesting.NewMachine("new-machine", newNode.Name)
+	newNode.Annotations[machineAnnotationKey] = namespacedName(newMachine).String()
+	for _, obj := range []client.Object{newMachine, newNode} {
+		if err := c.Create(ctx, obj); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	reconcileMHC("within interval", reconcile.Result{RequeueAfter: 50 * time.Second}, 1)
+
+	fakeClock.Step(50 * time.Second)
+	reconcileMHC("after interval", reconcile.Result{}, 2)
+}
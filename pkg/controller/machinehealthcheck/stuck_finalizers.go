@@ -0,0 +1,106 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// removeStuckFinalizersAfterAnnotation enables the removal of stuck finalizers from the machines
+// of a MachineHealthCheck. Its value is the duration a machine has to be deleting for before its
+// finalizers are considered stuck, e.g. "30m".
+const removeStuckFinalizersAfterAnnotation = "machine.openshift.io/remove-stuck-finalizers-after"
+
+// finalizersPendingError is returned when the finalizers of a deleting machine
+// are not considered stuck yet
+type finalizersPendingError struct {
+	target string
+	after  time.Duration
+}
+
+func (e *finalizersPendingError) Error() string {
+	return fmt.Sprintf("%s: finalizers are considered stuck in %v", e.target, e.after)
+}
+
+// stuckFinalizersGracePeriod returns the duration after which the finalizers of deleting machines
+// are considered stuck, and false if removing stuck finalizers is not enabled for the MachineHealthCheck
+func stuckFinalizersGracePeriod(mhc *mapiv1.MachineHealthCheck) (time.Duration, bool, error) {
+	value, ok := mhc.Annotations[removeStuckFinalizersAfterAnnotation]
+	if !ok {
+		return 0, false, nil
+	}
+	gracePeriod, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid %s annotation %q: %v", removeStuckFinalizersAfterAnnotation, value, err)
+	}
+	if gracePeriod < 0 {
+		return 0, false, fmt.Errorf("invalid %s annotation %q: must not be negative", removeStuckFinalizersAfterAnnotation, value)
+	}
+	return gracePeriod, true, nil
+}
+
+// isStuckFinalizer returns true if the finalizer is allowed to be removed
+func (r *ReconcileMachineHealthCheck) isStuckFinalizer(finalizer string) bool {
+	for _, f := range r.stuckFinalizers {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// removeStuckFinalizers removes the allowed finalizers of a machine which has been deleting
+// for longer than the grace period of the MachineHealthCheck, letting it finalize. It is a
+// no-op unless both the MachineHealthCheck enables it and the controller allows finalizers.
+func (t *target) removeStuckFinalizers(r *ReconcileMachineHealthCheck, machine *mapiv1.Machine) error {
+	if len(r.stuckFinalizers) == 0 {
+		return nil
+	}
+	gracePeriod, enabled, err := stuckFinalizersGracePeriod(&t.MHC)
+	if err != nil {
+		klog.Warningf("%s: not removing stuck finalizers: %v", t.string(), err)
+		return nil
+	}
+	if !enabled {
+		return nil
+	}
+
+	deleting := r.now().Sub(machine.GetDeletionTimestamp().Time)
+	if deleting < gracePeriod {
+		return &finalizersPendingError{target: t.string(), after: gracePeriod - deleting}
+	}
+
+	var remaining, removed []string
+	for _, finalizer := range machine.GetFinalizers() {
+		if r.isStuckFinalizer(finalizer) {
+			removed = append(removed, finalizer)
+			continue
+		}
+		remaining = append(remaining, finalizer)
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	klog.Warningf("%s: deleting for %v, removing stuck finalizers %v", t.string(), deleting, removed)
+	mergeBase := client.MergeFrom(machine.DeepCopy())
+	machine.SetFinalizers(remaining)
+	if err := r.client.Patch(context.TODO(), machine, mergeBase); err != nil {
+		return fmt.Errorf("%s: failed to remove stuck finalizers: %v", t.string(), err)
+	}
+	r.recorder.Eventf(
+		&t.Machine,
+		corev1.EventTypeWarning,
+		EventStuckFinalizersRemoved,
+		"Machine %v has been deleting for %v, removed stuck finalizers %v",
+		t.string(),
+		deleting.Round(time.Second),
+		removed,
+	)
+	return nil
+}
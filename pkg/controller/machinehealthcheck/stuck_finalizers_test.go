@@ -0,0 +1,165 @@
+package machinehealthcheck
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestStuckFinalizersGracePeriod(t *testing.T) {
+	testCases := []struct {
+		testCase            string
+		annotations         map[string]string
+		expectedGracePeriod time.Duration
+		expectedEnabled     bool
+		expectedError       bool
+	}{
+		{
+			testCase:        "no annotation",
+			expectedEnabled: false,
+		},
+		{
+			testCase:            "valid grace period",
+			annotations:         map[string]string{removeStuckFinalizersAfterAnnotation: "30m"},
+			expectedGracePeriod: 30 * time.Minute,
+			expectedEnabled:     true,
+		},
+		{
+			testCase:      "invalid grace period",
+			annotations:   map[string]string{removeStuckFinalizersAfterAnnotation: "30"},
+			expectedError: true,
+		},
+		{
+			testCase:      "negative grace period",
+			annotations:   map[string]string{removeStuckFinalizersAfterAnnotation: "-30m"},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			mhc.Annotations = tc.annotations
+			gracePeriod, enabled, err := stuckFinalizersGracePeriod(mhc)
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("Expected error: %t, got: %v", tc.expectedError, err)
+			}
+			if enabled != tc.expectedEnabled {
+				t.Errorf("Expected enabled: %t, got: %t", tc.expectedEnabled, enabled)
+			}
+			if gracePeriod != tc.expectedGracePeriod {
+				t.Errorf("Expected grace period %v, got %v", tc.expectedGracePeriod, gracePeriod)
+			}
+		})
+	}
+}
+
+func TestRemediateStuckFinalizers(t *testing.T) {
+	now := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	stuckFinalizer := "machine.openshift.io/stuck"
+	otherFinalizer := "example.com/other"
+
+	testCases := []struct {
+		testCase           string
+		stuckFinalizers    []string
+		annotations        map[string]string
+		deleting           time.Duration
+		finalizers         []string
+		expectedFinalizers []string
+		expectedPending    time.Duration
+		expectedEvents     []string
+	}{
+		{
+			testCase:           "not enabled on the mhc",
+			stuckFinalizers:    []string{stuckFinalizer},
+			deleting:           time.Hour,
+			finalizers:         []string{stuckFinalizer},
+			expectedFinalizers: []string{stuckFinalizer},
+			expectedEvents:     []string{},
+		},
+		{
+			testCase:           "no finalizers allowed by the controller",
+			annotations:        map[string]string{removeStuckFinalizersAfterAnnotation: "30m"},
+			deleting:           time.Hour,
+			finalizers:         []string{stuckFinalizer},
+			expectedFinalizers: []string{stuckFinalizer},
+			expectedEvents:     []string{},
+		},
+		{
+			testCase:           "within grace period",
+			stuckFinalizers:    []string{stuckFinalizer},
+			annotations:        map[string]string{removeStuckFinalizersAfterAnnotation: "30m"},
+			deleting:           10 * time.Minute,
+			finalizers:         []string{stuckFinalizer},
+			expectedFinalizers: []string{stuckFinalizer},
+			expectedPending:    20 * time.Minute,
+			expectedEvents:     []string{},
+		},
+		{
+			testCase:           "past grace period removes allowed finalizers only",
+			stuckFinalizers:    []string{stuckFinalizer},
+			annotations:        map[string]string{removeStuckFinalizersAfterAnnotation: "30m"},
+			deleting:           time.Hour,
+			finalizers:         []string{otherFinalizer, stuckFinalizer},
+			expectedFinalizers: []string{otherFinalizer},
+			expectedEvents:     []string{EventStuckFinalizersRemoved},
+		},
+		{
+			testCase:           "past grace period without allowed finalizers",
+			stuckFinalizers:    []string{stuckFinalizer},
+			annotations:        map[string]string{removeStuckFinalizersAfterAnnotation: "30m"},
+			deleting:           time.Hour,
+			finalizers:         []string{otherFinalizer},
+			expectedFinalizers: []string{otherFinalizer},
+			expectedEvents:     []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			machine := maotesting.NewMachine("machine", "node")
+			deletionTimestamp := metav1.NewTime(now.Add(-tc.deleting))
+			machine.DeletionTimestamp = &deletionTimestamp
+			machine.Finalizers = tc.finalizers
+
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			mhc.Annotations = tc.annotations
+			target := target{
+				Machine: *machine,
+				Node:    maotesting.NewNode("node", false),
+				MHC:     *mhc,
+			}
+
+			recorder := record.NewFakeRecorder(2)
+			r := newFakeReconcilerWithCustomRecorder(recorder, machine)
+			r.clock = clock.NewFakePassiveClock(now)
+			r.stuckFinalizers = tc.stuckFinalizers
+
+			err := target.remediate(r)
+			var pendingErr *finalizersPendingError
+			if tc.expectedPending > 0 {
+				if !errors.As(err, &pendingErr) || pendingErr.after != tc.expectedPending {
+					t.Errorf("Expected finalizers to be pending for %v, got: %v", tc.expectedPending, err)
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			assertEvents(t, tc.testCase, tc.expectedEvents, recorder.Events)
+
+			got := &mapiv1beta1.Machine{}
+			if err := r.client.Get(ctx, namespacedName(machine), got); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got.Finalizers, tc.expectedFinalizers) {
+				t.Errorf("Expected finalizers %v, got %v", tc.expectedFinalizers, got.Finalizers)
+			}
+		})
+	}
+}
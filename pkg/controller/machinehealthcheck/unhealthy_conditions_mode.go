@@ -0,0 +1,53 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// allUnhealthyConditionsMet evaluates the unhealthy conditions of the target with AND semantics.
+// The conditions are grouped by type, a type is met when the node condition of that type is in one
+// of the statuses listed for it for longer than the matching timeout. It returns whether every type
+// is met along with the met conditions, or the duration after which they will all be met if every
+// type is already in an unhealthy status. No next check is returned while a type is not in an
+// unhealthy status, as the node changing status triggers a reconcile.
+func (t *target) allUnhealthyConditionsMet(now time.Time) (bool, string, time.Duration) {
+	var types []corev1.NodeConditionType
+	statuses := map[corev1.NodeConditionType]string{}
+	remaining := map[corev1.NodeConditionType]time.Duration{}
+	for _, c := range t.unhealthyConditions() {
+		if _, ok := statuses[c.Type]; !ok {
+			types = append(types, c.Type)
+			statuses[c.Type] = ""
+		}
+		nodeCondition := conditions.GetNodeCondition(t.Node, c.Type)
+		if nodeCondition == nil || nodeCondition.Status != c.Status {
+			continue
+		}
+		statuses[c.Type] = fmt.Sprintf("%s=%s", c.Type, c.Status)
+		remaining[c.Type] = c.Timeout.Duration - elapsedSince(nodeCondition.LastTransitionTime.Time, now)
+	}
+	if len(types) == 0 {
+		return false, "", 0
+	}
+
+	var met []string
+	var nextCheck time.Duration
+	for _, conditionType := range types {
+		if statuses[conditionType] == "" {
+			return false, "", 0
+		}
+		met = append(met, statuses[conditionType])
+		if remaining[conditionType] >= 0 && remaining[conditionType]+time.Second > nextCheck {
+			nextCheck = remaining[conditionType] + time.Second
+		}
+	}
+	if nextCheck > 0 {
+		return false, "", nextCheck
+	}
+	return true, strings.Join(met, ","), 0
+}
@@ -0,0 +1,115 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestNeedsRemediationUnhealthyConditionsMode(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		testCase                   string
+		mode                       mapiv1beta1.UnhealthyConditionsMode
+		readyStatus                corev1.ConditionStatus
+		diskPressureStatus         corev1.ConditionStatus
+		diskPressureSince          time.Duration
+		expectedNeedsRemediation   bool
+		expectedUnhealthyCondition string
+		expectedNextCheck          time.Duration
+	}{
+		{
+			testCase:                   "any: one condition met",
+			mode:                       mapiv1beta1.UnhealthyConditionsModeAny,
+			readyStatus:                corev1.ConditionFalse,
+			diskPressureStatus:         corev1.ConditionFalse,
+			diskPressureSince:          10 * time.Minute,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: "Ready=False",
+		},
+		{
+			testCase:           "all: one condition met",
+			mode:               mapiv1beta1.UnhealthyConditionsModeAll,
+			readyStatus:        corev1.ConditionFalse,
+			diskPressureStatus: corev1.ConditionFalse,
+			diskPressureSince:  10 * time.Minute,
+		},
+		{
+			testCase:                   "all: both conditions met",
+			mode:                       mapiv1beta1.UnhealthyConditionsModeAll,
+			readyStatus:                corev1.ConditionFalse,
+			diskPressureStatus:         corev1.ConditionTrue,
+			diskPressureSince:          10 * time.Minute,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: "Ready=False,DiskPressure=True",
+		},
+		{
+			testCase:                   "all: alternative status of the same type met",
+			mode:                       mapiv1beta1.UnhealthyConditionsModeAll,
+			readyStatus:                corev1.ConditionUnknown,
+			diskPressureStatus:         corev1.ConditionTrue,
+			diskPressureSince:          10 * time.Minute,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: "Ready=Unknown,DiskPressure=True",
+		},
+		{
+			testCase:           "all: both conditions in unhealthy status, one within its timeout",
+			mode:               mapiv1beta1.UnhealthyConditionsModeAll,
+			readyStatus:        corev1.ConditionFalse,
+			diskPressureStatus: corev1.ConditionTrue,
+			diskPressureSince:  time.Minute,
+			expectedNextCheck:  4*time.Minute + time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			mhc.Spec.UnhealthyConditionsMode = tc.mode
+			mhc.Spec.UnhealthyConditions = []mapiv1beta1.UnhealthyCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Timeout: metav1.Duration{Duration: 5 * time.Minute}},
+				{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Timeout: metav1.Duration{Duration: 5 * time.Minute}},
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue, Timeout: metav1.Duration{Duration: 5 * time.Minute}},
+			}
+			node := maotesting.NewNode("node", false)
+			node.Status.Conditions = []corev1.NodeCondition{
+				{
+					Type:               corev1.NodeReady,
+					Status:             tc.readyStatus,
+					LastTransitionTime: metav1.NewTime(now.Add(-10 * time.Minute)),
+				},
+				{
+					Type:               corev1.NodeDiskPressure,
+					Status:             tc.diskPressureStatus,
+					LastTransitionTime: metav1.NewTime(now.Add(-tc.diskPressureSince)),
+				},
+			}
+			target := target{
+				Machine: *maotesting.NewMachine("machine", node.Name),
+				Node:    node,
+				MHC:     *mhc,
+				clock:   clock.NewFakeClock(now),
+			}
+
+			needsRemediation, unhealthyCondition, nextCheck, err := target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: defaultNodeStartupTimeout})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if needsRemediation != tc.expectedNeedsRemediation {
+				t.Errorf("Expected needsRemediation: %t, got: %t", tc.expectedNeedsRemediation, needsRemediation)
+			}
+			if unhealthyCondition != tc.expectedUnhealthyCondition {
+				t.Errorf("Expected unhealthy condition %q, got %q", tc.expectedUnhealthyCondition, unhealthyCondition)
+			}
+			if nextCheck != tc.expectedNextCheck {
+				t.Errorf("Expected next check %v, got %v", tc.expectedNextCheck, nextCheck)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package machinehealthcheck
+
+import (
+	"sort"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+)
+
+// verboseStatusAnnotation opts a MachineHealthCheck into reporting the health of every
+// target in its status, which is too verbose to be reported for every MachineHealthCheck
+const verboseStatusAnnotation = "machine.openshift.io/verbose-status"
+
+// targetStatuses returns the health of every target sorted by machine name, or nil
+// unless the MHC opted into verbose status
+func targetStatuses(mhc *mapiv1.MachineHealthCheck, targets, healthyTargets, needRemediationTargets []target) []mapiv1.MachineHealthCheckTargetStatus {
+	if _, ok := mhc.Annotations[verboseStatusAnnotation]; !ok {
+		return nil
+	}
+
+	statuses := map[string]*mapiv1.MachineHealthCheckTargetStatus{}
+	for _, t := range targets {
+		statuses[t.Machine.Name] = &mapiv1.MachineHealthCheckTargetStatus{
+			Machine: t.Machine.Name,
+			Node:    t.nodeName(),
+			Verdict: mapiv1.TargetPending,
+		}
+	}
+	for _, t := range healthyTargets {
+		statuses[t.Machine.Name].Verdict = mapiv1.TargetHealthy
+	}
+	for _, t := range needRemediationTargets {
+		statuses[t.Machine.Name].Verdict = mapiv1.TargetNeedsRemediation
+		statuses[t.Machine.Name].UnhealthyCondition = t.UnhealthyCondition
+	}
+
+	targetStatuses := make([]mapiv1.MachineHealthCheckTargetStatus, 0, len(statuses))
+	for _, status := range statuses {
+		targetStatuses = append(targetStatuses, *status)
+	}
+	sort.Slice(targetStatuses, func(i, j int) bool {
+		return targetStatuses[i].Machine < targetStatuses[j].Machine
+	})
+	return targetStatuses
+}
@@ -0,0 +1,92 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileVerboseStatus(t *testing.T) {
+	testCases := []struct {
+		testCase string
+		verbose  bool
+	}{
+		{
+			testCase: "aggregate status only",
+		},
+		{
+			testCase: "verbose status",
+			verbose:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			if tc.verbose {
+				mhc.Annotations = map[string]string{verboseStatusAnnotation: ""}
+			}
+			nodeHealthy := maotesting.NewNode("healthy", true)
+			nodeUnhealthy := maotesting.NewNode("unhealthy", false)
+			nodeRecentlyUnhealthy := maotesting.NewNode("recently-unhealthy", false)
+			nodeRecentlyUnhealthy.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now())
+			machineHealthy := maotesting.NewMachine("a-healthy", nodeHealthy.Name)
+			machineUnhealthy := maotesting.NewMachine("b-unhealthy", nodeUnhealthy.Name)
+			machineRecentlyUnhealthy := maotesting.NewMachine("c-recently-unhealthy", nodeRecentlyUnhealthy.Name)
+
+			r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), mhc,
+				nodeHealthy, nodeUnhealthy, nodeRecentlyUnhealthy,
+				machineHealthy, machineUnhealthy, machineRecentlyUnhealthy,
+			)
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			updatedMHC := &mapiv1beta1.MachineHealthCheck{}
+			if err := r.client.Get(ctx, namespacedName(mhc), updatedMHC); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := derefInt(updatedMHC.Status.CurrentHealthy); got != 1 {
+				t.Errorf("Expected 1 healthy machine, got %d", got)
+			}
+			if !tc.verbose {
+				if updatedMHC.Status.Targets != nil {
+					t.Errorf("Expected no per target status, got: %v", updatedMHC.Status.Targets)
+				}
+				return
+			}
+
+			expected := []mapiv1beta1.MachineHealthCheckTargetStatus{
+				{
+					Machine: machineHealthy.Name,
+					Node:    nodeHealthy.Name,
+					Verdict: mapiv1beta1.TargetHealthy,
+				},
+				{
+					Machine:            machineUnhealthy.Name,
+					Node:               nodeUnhealthy.Name,
+					Verdict:            mapiv1beta1.TargetNeedsRemediation,
+					UnhealthyCondition: "Ready=Unknown",
+				},
+				{
+					Machine: machineRecentlyUnhealthy.Name,
+					Node:    nodeRecentlyUnhealthy.Name,
+					Verdict: mapiv1beta1.TargetPending,
+				},
+			}
+			if len(updatedMHC.Status.Targets) != len(expected) {
+				t.Fatalf("Expected %d target statuses, got: %v", len(expected), updatedMHC.Status.Targets)
+			}
+			for i := range expected {
+				if updatedMHC.Status.Targets[i] != expected[i] {
+					t.Errorf("Expected target status %v, got %v", expected[i], updatedMHC.Status.Targets[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package machinehealthcheck
+
+import (
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// vetoed returns true if the node of the target reports the veto condition of the MHC as True.
+// The veto condition is maintained by an external agent vouching for the node, it overrides
+// any unhealthy verdict of the MHC.
+func (t *target) vetoed() bool {
+	if t.MHC.Spec.VetoCondition == "" || t.Node == nil || t.Node.UID == "" {
+		return false
+	}
+	nodeCondition := conditions.GetNodeCondition(t.Node, t.MHC.Spec.VetoCondition)
+	return nodeCondition != nil && nodeCondition.Status == corev1.ConditionTrue
+}
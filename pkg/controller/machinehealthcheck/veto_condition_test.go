@@ -0,0 +1,123 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const clusterHealthyCondition = corev1.NodeConditionType("ClusterHealthy")
+
+func TestNeedsRemediationVetoCondition(t *testing.T) {
+	testCases := []struct {
+		testCase                   string
+		vetoCondition              corev1.NodeConditionType
+		vetoStatus                 corev1.ConditionStatus
+		phase                      string
+		expectedNeedsRemediation   bool
+		expectedUnhealthyCondition string
+	}{
+		{
+			testCase:                 "veto condition true suppresses unhealthy node",
+			vetoCondition:            clusterHealthyCondition,
+			vetoStatus:               corev1.ConditionTrue,
+			expectedNeedsRemediation: false,
+		},
+		{
+			testCase:                 "veto condition true suppresses failed machine",
+			vetoCondition:            clusterHealthyCondition,
+			vetoStatus:               corev1.ConditionTrue,
+			phase:                    machinePhaseFailed,
+			expectedNeedsRemediation: false,
+		},
+		{
+			testCase:                   "veto condition false",
+			vetoCondition:              clusterHealthyCondition,
+			vetoStatus:                 corev1.ConditionFalse,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: "Ready=Unknown",
+		},
+		{
+			testCase:                   "veto condition not reported",
+			vetoCondition:              clusterHealthyCondition,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: "Ready=Unknown",
+		},
+		{
+			testCase:                   "no veto condition configured",
+			vetoStatus:                 corev1.ConditionTrue,
+			expectedNeedsRemediation:   true,
+			expectedUnhealthyCondition: "Ready=Unknown",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("mhc")
+			mhc.Spec.VetoCondition = tc.vetoCondition
+			node := maotesting.NewNode("node", false)
+			if tc.vetoStatus != "" {
+				node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+					Type:   clusterHealthyCondition,
+					Status: tc.vetoStatus,
+				})
+			}
+			machine := maotesting.NewMachine("machine", node.Name)
+			if tc.phase != "" {
+				machine.Status.Phase = pointer.StringPtr(tc.phase)
+			}
+			target := target{
+				Machine: *machine,
+				Node:    node,
+				MHC:     *mhc,
+				clock:   clock.NewFakeClock(time.Now()),
+			}
+
+			needsRemediation, unhealthyCondition, _, err := target.needsRemediation(healthCheckTimeouts{nodeStartupTimeout: defaultNodeStartupTimeout})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if needsRemediation != tc.expectedNeedsRemediation {
+				t.Errorf("Expected needsRemediation: %t, got: %t", tc.expectedNeedsRemediation, needsRemediation)
+			}
+			if unhealthyCondition != tc.expectedUnhealthyCondition {
+				t.Errorf("Expected unhealthy condition %q, got %q", tc.expectedUnhealthyCondition, unhealthyCondition)
+			}
+		})
+	}
+}
+
+func TestReconcileVetoCondition(t *testing.T) {
+	mhc := maotesting.NewMachineHealthCheck("veto")
+	mhc.Spec.VetoCondition = clusterHealthyCondition
+	node := maotesting.NewNode("node", false)
+	node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+		Type:   clusterHealthyCondition,
+		Status: corev1.ConditionTrue,
+	})
+	machine := maotesting.NewMachine("machine", node.Name)
+
+	r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), mhc, node, machine)
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := r.client.Get(ctx, namespacedName(machine), &mapiv1beta1.Machine{}); apierrors.IsNotFound(err) {
+		t.Errorf("Expected the vetoed machine not to be remediated")
+	}
+	got := &mapiv1beta1.MachineHealthCheck{}
+	if err := r.client.Get(ctx, namespacedName(mhc), got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if healthy := derefInt(got.Status.CurrentHealthy); healthy != 1 {
+		t.Errorf("Expected the vetoed machine to be healthy, got %d healthy machines", healthy)
+	}
+}
@@ -0,0 +1,72 @@
+package machinehealthcheck
+
+import (
+	"sort"
+	"time"
+
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// machineZoneLabel is the label the machine controller sets to the availability zone of a machine
+	machineZoneLabel = "machine.openshift.io/zone"
+	// zoneBudgetRequeue is the delay before retrying remediations deferred by the zone budget
+	zoneBudgetRequeue = time.Minute
+)
+
+// zone returns the availability zone of the target, read from the node topology
+// labels when the node is known and from the machine labels otherwise. An empty
+// string is returned when the zone is unknown.
+func (t *target) zone() string {
+	if t.Node != nil {
+		for _, label := range []string{corev1.LabelTopologyZone, corev1.LabelFailureDomainBetaZone} {
+			if zone := t.Node.Labels[label]; zone != "" {
+				return zone
+			}
+		}
+	}
+	return t.Machine.Labels[machineZoneLabel]
+}
+
+// filterByZoneBudget limits the targets remediated in a single pass to maxRemediationsPerZone
+// per availability zone, so that an outage of a whole zone does not trigger mass deletion within
+// it. The targets exceeding the budget are returned separately to be requeued. Targets in an
+// unknown zone are not restricted.
+func (r *ReconcileMachineHealthCheck) filterByZoneBudget(mhc *mapiv1.MachineHealthCheck, needRemediationTargets []target) ([]target, []target) {
+	if r.maxRemediationsPerZone <= 0 {
+		return needRemediationTargets, nil
+	}
+
+	// remediate targets in a stable order so the same targets are deferred on every pass
+	sorted := make([]target, len(needRemediationTargets))
+	copy(sorted, needRemediationTargets)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Machine.Name < sorted[j].Machine.Name
+	})
+
+	var allowed, deferred []target
+	remediations := map[string]int{}
+	for _, t := range sorted {
+		// machines being deleted are not remediated again and do not use the budget
+		if t.isDeleting() {
+			allowed = append(allowed, t)
+			continue
+		}
+		zone := t.zone()
+		if zone == "" || remediations[zone] < r.maxRemediationsPerZone {
+			remediations[zone]++
+			allowed = append(allowed, t)
+			continue
+		}
+		klog.Infof("%s: zone %q reached its budget of %d remediations, deferring remediation",
+			t.string(), zone, r.maxRemediationsPerZone)
+		deferred = append(deferred, t)
+	}
+	if len(deferred) > 0 {
+		klog.Warningf("%s: deferred remediation of %d targets exceeding the budget of their zone",
+			namespacedName(mhc), len(deferred))
+	}
+	return allowed, deferred
+}
@@ -0,0 +1,124 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"testing"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	maotesting "github.com/openshift/machine-api-operator/pkg/util/testing"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestTargetZone(t *testing.T) {
+	testCases := []struct {
+		testCase      string
+		nodeLabels    map[string]string
+		machineLabels map[string]string
+		noNode        bool
+		expectedZone  string
+	}{
+		{
+			testCase:     "topology label",
+			nodeLabels:   map[string]string{corev1.LabelTopologyZone: "a", corev1.LabelFailureDomainBetaZone: "b"},
+			expectedZone: "a",
+		},
+		{
+			testCase:     "deprecated failure domain label",
+			nodeLabels:   map[string]string{corev1.LabelFailureDomainBetaZone: "b"},
+			expectedZone: "b",
+		},
+		{
+			testCase:      "machine label without node",
+			machineLabels: map[string]string{machineZoneLabel: "c"},
+			noNode:        true,
+			expectedZone:  "c",
+		},
+		{
+			testCase:     "unknown zone",
+			expectedZone: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			node := maotesting.NewNode("node", true)
+			node.Labels = tc.nodeLabels
+			machine := maotesting.NewMachine("machine", node.Name)
+			machine.Labels = tc.machineLabels
+			target := target{Machine: *machine, Node: node}
+			if tc.noNode {
+				target.Node = nil
+			}
+
+			if zone := target.zone(); zone != tc.expectedZone {
+				t.Errorf("Expected zone %q, got %q", tc.expectedZone, zone)
+			}
+		})
+	}
+}
+
+func TestReconcileZoneBudget(t *testing.T) {
+	testCases := []struct {
+		testCase               string
+		maxRemediationsPerZone int
+		expectedResult         reconcile.Result
+		expectedDeleted        map[string]bool
+	}{
+		{
+			testCase:               "per zone budgets disabled",
+			maxRemediationsPerZone: 0,
+			expectedResult:         reconcile.Result{},
+			expectedDeleted:        map[string]bool{"a-0": true, "a-1": true, "a-2": true, "b-0": true},
+		},
+		{
+			testCase:               "budget of one per zone",
+			maxRemediationsPerZone: 1,
+			expectedResult:         reconcile.Result{RequeueAfter: zoneBudgetRequeue},
+			expectedDeleted:        map[string]bool{"a-0": true, "a-1": false, "a-2": false, "b-0": true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCase, func(t *testing.T) {
+			mhc := maotesting.NewMachineHealthCheck("zones")
+			objects := []runtime.Object{mhc}
+			for _, z := range []struct {
+				zone     string
+				machines int
+			}{
+				{zone: "a", machines: 3},
+				{zone: "b", machines: 1},
+			} {
+				for i := 0; i < z.machines; i++ {
+					node := maotesting.NewNode(fmt.Sprintf("%s-node-%d", z.zone, i), false)
+					node.Labels[corev1.LabelTopologyZone] = z.zone
+					machine := maotesting.NewMachine(fmt.Sprintf("%s-%d", z.zone, i), node.Name)
+					node.Annotations[machineAnnotationKey] = fmt.Sprintf("%s/%s", machine.Namespace, machine.Name)
+					objects = append(objects, machine, node)
+				}
+			}
+
+			r := newFakeReconcilerWithCustomRecorder(record.NewFakeRecorder(10), objects...)
+			r.maxRemediationsPerZone = tc.maxRemediationsPerZone
+
+			result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName(mhc)})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tc.expectedResult {
+				t.Errorf("Expected result %+v, got %+v", tc.expectedResult, result)
+			}
+
+			for name, expectedDeleted := range tc.expectedDeleted {
+				err := r.client.Get(ctx, namespacedName(maotesting.NewMachine(name, "")), &mapiv1beta1.Machine{})
+				if deleted := apierrors.IsNotFound(err); deleted != expectedDeleted {
+					t.Errorf("%s: expected deleted: %t, got: %v", name, expectedDeleted, err)
+				}
+			}
+		})
+	}
+}
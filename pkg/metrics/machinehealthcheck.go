@@ -16,12 +16,41 @@
 package metrics
 
 import (
+	"strconv"
+	"sync"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 const (
 	DefaultHealthCheckMetricsAddress = ":8083"
+
+	// UnremediatableReasonNoOwner is the reason of machines without a controller owner to replace them
+	UnremediatableReasonNoOwner = "no_owner"
+	// UnremediatableReasonMaster is the reason of master machines
+	UnremediatableReasonMaster = "master"
+	// UnremediatableReasonProtected is the reason of machines with a protected role
+	UnremediatableReasonProtected = "protected"
+	// UnremediatableReasonInconsistent is the reason of machines whose node is annotated with another machine
+	UnremediatableReasonInconsistent = "inconsistent"
+
+	// RemediationBlockedReasonMaxUnhealthy is the reason of remediations blocked by the maxUnhealthy short circuit
+	RemediationBlockedReasonMaxUnhealthy = "max_unhealthy"
+	// RemediationBlockedReasonRateLimit is the reason of remediations blocked by the master remediation cool-down
+	RemediationBlockedReasonRateLimit = "rate_limit"
+	// RemediationBlockedReasonMachineSetBudget is the reason of remediations blocked by a MachineSet budget
+	RemediationBlockedReasonMachineSetBudget = "machineset_budget"
+	// RemediationBlockedReasonZoneBudget is the reason of remediations blocked by a zone budget
+	RemediationBlockedReasonZoneBudget = "zone_budget"
+	// RemediationBlockedReasonWindow is the reason of remediations blocked outside of the remediation window
+	RemediationBlockedReasonWindow = "window"
+	// RemediationBlockedReasonBatch is the reason of remediations held until the next batch boundary
+	RemediationBlockedReasonBatch = "batch"
+	// RemediationBlockedReasonControlPlaneDeletion is the reason of deletions of masters refused
+	// because the MachineHealthCheck does not allow control plane deletion
+	RemediationBlockedReasonControlPlaneDeletion = "control_plane_deletion"
 )
 
 var (
@@ -48,6 +77,212 @@ var (
 			Help: "Short circuit status for MachineHealthCheck (0=no, 1=yes)",
 		}, []string{"name", "namespace"},
 	)
+
+	// MachineHealthCheckTimeToRemediateSeconds is a Prometheus metric, which reports the delay between a target
+	// becoming unhealthy and the MachineHealthCheck remediating it
+	MachineHealthCheckTimeToRemediateSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mapi_mhc_time_to_remediate_seconds",
+			Help:    "Number of seconds between a node condition exceeding its timeout and the MachineHealthCheck remediating it",
+			Buckets: []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+		}, []string{"name", "namespace"},
+	)
+
+	// MachineHealthCheckUnhealthyDurationAtRemediationSeconds is a Prometheus metric, which reports how long the
+	// node of a target had been unhealthy when the MachineHealthCheck remediated it, timeout included
+	MachineHealthCheckUnhealthyDurationAtRemediationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mapi_mhc_unhealthy_duration_at_remediation_seconds",
+			Help:    "Number of seconds a node condition had been unhealthy when the MachineHealthCheck remediated it",
+			Buckets: []float64{30, 60, 120, 300, 600, 900, 1200, 1800, 3600, 7200},
+		}, []string{"name", "namespace"},
+	)
+
+	// MachineHealthCheckInconsistentTargetsTotal is a Prometheus metric, which reports the number of targets skipped
+	// because the node and the machine do not reference each other consistently
+	MachineHealthCheckInconsistentTargetsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mapi_machinehealthcheck_inconsistent_targets_total",
+			Help: "Number of targets skipped by MachineHealthChecks due to inconsistent node and machine references",
+		}, []string{"name", "namespace"},
+	)
+
+	// MachineHealthCheckDuplicateNodeAnnotationsTotal is a Prometheus metric, which reports the number of nodes
+	// ignored because they are annotated with a machine whose nodeRef refers to another node
+	MachineHealthCheckDuplicateNodeAnnotationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mapi_mhc_duplicate_node_annotations_total",
+			Help: "Number of nodes ignored by MachineHealthChecks because another node is referenced by their annotated machine",
+		}, []string{"name", "namespace"},
+	)
+
+	// MachineHealthCheckShortConditionTimeouts is a Prometheus metric, which reports the number of unhealthy
+	// conditions of a MachineHealthCheck whose timeout is below the configured minimum
+	MachineHealthCheckShortConditionTimeouts = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mapi_mhc_short_condition_timeouts",
+			Help: "Number of unhealthy conditions of MachineHealthChecks whose timeout is below the configured minimum",
+		}, []string{"name", "namespace"},
+	)
+
+	// MachineHealthCheckAuditWebhookFailuresTotal is a Prometheus metric, which reports the number of remediation
+	// records which could not be delivered to the audit webhook
+	MachineHealthCheckAuditWebhookFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mapi_machinehealthcheck_audit_webhook_failures_total",
+			Help: "Number of remediation audit records MachineHealthChecks failed to deliver to the audit webhook",
+		}, []string{"name", "namespace"},
+	)
+
+	// MachineUnhealthy is a Prometheus metric, which reports the machines a MachineHealthCheck considers unhealthy
+	// along with the condition which made them unhealthy
+	MachineUnhealthy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mapi_machine_unhealthy",
+			Help: "Machines considered unhealthy by MachineHealthChecks and the condition which made them unhealthy",
+		}, []string{"name", "namespace", "machinehealthcheck", "condition"},
+	)
+
+	// MachineHealthCheckNextCheckSeconds is a Prometheus metric, which reports the delay after which the
+	// MachineHealthCheck was last requeued to check its targets again (0=not requeued)
+	MachineHealthCheckNextCheckSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mapi_mhc_next_check_seconds",
+			Help: "Number of seconds after which the MachineHealthCheck will check its targets again (0=no check scheduled)",
+		}, []string{"name", "namespace"},
+	)
+
+	// MachineHealthCheckFlappingDetectedTotal is a Prometheus metric, which reports the number of remediations
+	// suppressed because the node was remediated too often recently
+	MachineHealthCheckFlappingDetectedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mapi_machinehealthcheck_flapping_detected_total",
+			Help: "Number of remediations suppressed by MachineHealthChecks due to flapping nodes",
+		}, []string{"name", "namespace"},
+	)
+
+	// MachineHealthCheckBadMachineAnnotationTotal is a Prometheus metric, which reports the number of times
+	// the machine annotation of a node could not be parsed
+	MachineHealthCheckBadMachineAnnotationTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mapi_mhc_bad_machine_annotation_total",
+			Help: "Number of times the machine annotation of a node could not be parsed",
+		}, []string{"node"},
+	)
+
+	// MachineHealthCheckTargetsEvaluated is a Prometheus metric, which reports the number of targets
+	// evaluated by the last reconcile of the MachineHealthCheck
+	MachineHealthCheckTargetsEvaluated = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mapi_mhc_targets_evaluated",
+			Help: "Number of targets evaluated by the last reconcile of the MachineHealthCheck",
+		}, []string{"name", "namespace"},
+	)
+
+	// MachineHealthCheckEvaluationDurationSeconds is a Prometheus metric, which reports the time
+	// taken to evaluate the health of all targets of the MachineHealthCheck
+	MachineHealthCheckEvaluationDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mapi_mhc_evaluation_duration_seconds",
+			Help:    "Number of seconds taken to evaluate the health of the targets of the MachineHealthCheck",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10},
+		}, []string{"name", "namespace"},
+	)
+
+	// MachinesUnremediatableCount is a Prometheus metric, which reports the number of machines matched
+	// by a MachineHealthCheck whose remediation is skipped or restricted, by reason
+	MachinesUnremediatableCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mapi_machines_unremediatable_count",
+			Help: "Number of machines matched by the MachineHealthCheck whose remediation is skipped or restricted",
+		}, []string{"name", "namespace", "reason"},
+	)
+
+	// ClusterMachinesHealthyRatio is a Prometheus metric, which reports the ratio of healthy machines over all
+	// machines covered by MachineHealthChecks. Machines covered by several MachineHealthChecks are counted once.
+	ClusterMachinesHealthyRatio = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mapi_cluster_machines_healthy_ratio",
+			Help: "Ratio of healthy machines over all machines covered by MachineHealthChecks",
+		},
+	)
+
+	// MachineHealthCheckConfigInfo is a Prometheus metric, which describes the effective configuration of
+	// the MachineHealthCheck in its labels, for detecting configuration drift. Its value is always 1.
+	MachineHealthCheckConfigInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mapi_mhc_config_info",
+			Help: "Effective configuration of the MachineHealthCheck, the value is always 1",
+		}, []string{"name", "namespace", "max_unhealthy", "unhealthy_conditions", "node_startup_timeout"},
+	)
+
+	// ClusterRemediationSuspended is a Prometheus metric, which reports whether remediation is suspended
+	// cluster wide because too many nodes of the cluster are not ready
+	ClusterRemediationSuspended = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mapi_cluster_remediation_suspended",
+			Help: "Whether remediation is suspended because too many nodes of the cluster are not ready",
+		},
+	)
+
+	// MachineHealthCheckRemediationEscalatedTotal is a Prometheus metric, which reports the number of
+	// remediations of nodes which were already remediated repeatedly without recovering
+	MachineHealthCheckRemediationEscalatedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mapi_mhc_remediation_escalated_total",
+			Help: "Number of remediations escalated because the node was remediated repeatedly without recovering",
+		}, []string{"name", "namespace"},
+	)
+
+	// MachineHealthCheckRemediationBlockedTotal is a Prometheus metric, which reports the number of
+	// remediations of unhealthy targets withheld by a budget of the MachineHealthCheck, by reason
+	MachineHealthCheckRemediationBlockedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mapi_mhc_remediation_blocked_total",
+			Help: "Number of remediations of unhealthy machines withheld by a budget of the MachineHealthCheck",
+		}, []string{"name", "namespace", "reason"},
+	)
+
+	// MachineHealthCheckMatchedMachines is a Prometheus metric, which reports the number of
+	// machines matched by the selector of each MachineHealthCheck
+	MachineHealthCheckMatchedMachines = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mapi_mhc_matched_machines",
+			Help: "Number of machines matched by the selector of the MachineHealthCheck",
+		}, []string{"name", "namespace"},
+	)
+
+	// MachineHealthCheckOverlappingTotal is a Prometheus metric, which reports the number of other
+	// MachineHealthChecks whose selectors match a machine also matched by the MachineHealthCheck
+	MachineHealthCheckOverlappingTotal = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mapi_mhc_overlapping_total",
+			Help: "Number of other MachineHealthChecks whose selectors match a machine also matched by the MachineHealthCheck",
+		}, []string{"name", "namespace"},
+	)
+
+	// NodeConditionTransitionsTotal is a Prometheus metric, which reports the number of transitions
+	// of each condition of each node observed by MachineHealthChecks
+	NodeConditionTransitionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mapi_node_condition_transitions_total",
+			Help: "Number of transitions of the node condition observed by MachineHealthChecks",
+		}, []string{"node", "condition"},
+	)
+
+	// machineUnhealthyLabels contains the labels of the MachineUnhealthy series
+	// currently reported for each MachineHealthCheck
+	machineUnhealthyLabels     = map[string][]prometheus.Labels{}
+	machineUnhealthyLabelsLock sync.Mutex
+
+	// machinesHealth contains the health of the machines covered by each MachineHealthCheck
+	machinesHealth     = map[string]map[string]bool{}
+	machinesHealthLock sync.Mutex
+
+	// machineHealthCheckConfigLabels contains the labels of the MachineHealthCheckConfigInfo
+	// series currently reported for each MachineHealthCheck
+	machineHealthCheckConfigLabels     = map[string]prometheus.Labels{}
+	machineHealthCheckConfigLabelsLock sync.Mutex
 )
 
 func InitializeMachineHealthCheckMetrics() {
@@ -55,6 +290,27 @@ func InitializeMachineHealthCheckMetrics() {
 		MachineHealthCheckNodesCovered,
 		MachineHealthCheckRemediationSuccessTotal,
 		MachineHealthCheckShortCircuit,
+		MachineHealthCheckTimeToRemediateSeconds,
+		MachineHealthCheckUnhealthyDurationAtRemediationSeconds,
+		MachineHealthCheckInconsistentTargetsTotal,
+		MachineHealthCheckDuplicateNodeAnnotationsTotal,
+		MachineHealthCheckShortConditionTimeouts,
+		MachineHealthCheckAuditWebhookFailuresTotal,
+		MachineUnhealthy,
+		MachineHealthCheckNextCheckSeconds,
+		MachineHealthCheckFlappingDetectedTotal,
+		MachineHealthCheckBadMachineAnnotationTotal,
+		MachineHealthCheckTargetsEvaluated,
+		MachineHealthCheckEvaluationDurationSeconds,
+		ClusterMachinesHealthyRatio,
+		MachinesUnremediatableCount,
+		MachineHealthCheckConfigInfo,
+		ClusterRemediationSuspended,
+		MachineHealthCheckRemediationEscalatedTotal,
+		MachineHealthCheckRemediationBlockedTotal,
+		MachineHealthCheckMatchedMachines,
+		MachineHealthCheckOverlappingTotal,
+		NodeConditionTransitionsTotal,
 	)
 }
 
@@ -92,3 +348,292 @@ func ObserveMachineHealthCheckShortCircuitEnabled(name string, namespace string)
 		"namespace": namespace,
 	}).Set(1)
 }
+
+func ObserveMachineHealthCheckTimeToRemediate(name string, namespace string, seconds float64) {
+	MachineHealthCheckTimeToRemediateSeconds.With(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	}).Observe(seconds)
+}
+
+func ObserveMachineHealthCheckUnhealthyDurationAtRemediation(name string, namespace string, seconds float64) {
+	MachineHealthCheckUnhealthyDurationAtRemediationSeconds.With(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	}).Observe(seconds)
+}
+
+func ObserveMachineHealthCheckInconsistentTarget(name string, namespace string) {
+	MachineHealthCheckInconsistentTargetsTotal.With(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	}).Inc()
+}
+
+// ObserveMachineHealthCheckDuplicateNodeAnnotations records nodes ignored because another node is referenced by their annotated machine
+func ObserveMachineHealthCheckDuplicateNodeAnnotations(name string, namespace string, count int) {
+	MachineHealthCheckDuplicateNodeAnnotationsTotal.With(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	}).Add(float64(count))
+}
+
+// DeleteMachineHealthCheckShortConditionTimeouts removes the short condition timeouts reported for the named MachineHealthCheck
+func DeleteMachineHealthCheckShortConditionTimeouts(name string, namespace string) {
+	MachineHealthCheckShortConditionTimeouts.Delete(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	})
+}
+
+// ObserveMachineHealthCheckShortConditionTimeouts records the number of unhealthy conditions whose timeout is below the configured minimum
+func ObserveMachineHealthCheckShortConditionTimeouts(name string, namespace string, count int) {
+	MachineHealthCheckShortConditionTimeouts.With(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	}).Set(float64(count))
+}
+
+func ObserveMachineHealthCheckAuditWebhookFailure(name string, namespace string) {
+	MachineHealthCheckAuditWebhookFailuresTotal.With(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	}).Inc()
+}
+
+func ObserveMachineHealthCheckFlappingDetected(name string, namespace string) {
+	MachineHealthCheckFlappingDetectedTotal.With(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	}).Inc()
+}
+
+func ObserveMachineHealthCheckRemediationEscalated(name string, namespace string) {
+	MachineHealthCheckRemediationEscalatedTotal.With(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	}).Inc()
+}
+
+// ObserveMachineHealthCheckRemediationBlocked counts the remediations of the given number
+// of targets blocked for the given reason
+func ObserveMachineHealthCheckRemediationBlocked(name string, namespace string, reason string, count int) {
+	if count <= 0 {
+		return
+	}
+	MachineHealthCheckRemediationBlockedTotal.With(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+		"reason":    reason,
+	}).Add(float64(count))
+}
+
+func ObserveMachineHealthCheckBadMachineAnnotation(node string) {
+	MachineHealthCheckBadMachineAnnotationTotal.With(prometheus.Labels{
+		"node": node,
+	}).Inc()
+}
+
+func DeleteMachineHealthCheckTargetsEvaluated(name string, namespace string) {
+	MachineHealthCheckTargetsEvaluated.Delete(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	})
+}
+
+func ObserveMachineHealthCheckTargetsEvaluated(name string, namespace string, count int, seconds float64) {
+	labels := prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	}
+	MachineHealthCheckTargetsEvaluated.With(labels).Set(float64(count))
+	MachineHealthCheckEvaluationDurationSeconds.With(labels).Observe(seconds)
+}
+
+func DeleteMachineHealthCheckUnremediatableMachines(name string, namespace string) {
+	for _, reason := range []string{UnremediatableReasonNoOwner, UnremediatableReasonMaster, UnremediatableReasonProtected, UnremediatableReasonInconsistent} {
+		MachinesUnremediatableCount.Delete(prometheus.Labels{
+			"name":      name,
+			"namespace": namespace,
+			"reason":    reason,
+		})
+	}
+}
+
+// ObserveMachineHealthCheckUnremediatableMachines reports the number of machines of the named
+// MachineHealthCheck whose remediation is skipped or restricted, counts maps reasons to numbers of machines.
+// Reasons missing from counts are reported as zero.
+func ObserveMachineHealthCheckUnremediatableMachines(name string, namespace string, counts map[string]int) {
+	for _, reason := range []string{UnremediatableReasonNoOwner, UnremediatableReasonMaster, UnremediatableReasonProtected, UnremediatableReasonInconsistent} {
+		MachinesUnremediatableCount.With(prometheus.Labels{
+			"name":      name,
+			"namespace": namespace,
+			"reason":    reason,
+		}).Set(float64(counts[reason]))
+	}
+}
+
+func DeleteMachineHealthCheckNextCheck(name string, namespace string) {
+	MachineHealthCheckNextCheckSeconds.Delete(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	})
+}
+
+func ObserveMachineHealthCheckNextCheck(name string, namespace string, seconds float64) {
+	MachineHealthCheckNextCheckSeconds.With(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	}).Set(seconds)
+}
+
+// ObserveMachineHealthCheckUnhealthyMachines replaces the unhealthy machines reported for
+// the named MachineHealthCheck. unhealthyMachines maps machine names to the condition which
+// made them unhealthy.
+func ObserveMachineHealthCheckUnhealthyMachines(name string, namespace string, unhealthyMachines map[string]string) {
+	machineUnhealthyLabelsLock.Lock()
+	defer machineUnhealthyLabelsLock.Unlock()
+
+	key := namespace + "/" + name
+	for _, labels := range machineUnhealthyLabels[key] {
+		MachineUnhealthy.Delete(labels)
+	}
+	delete(machineUnhealthyLabels, key)
+
+	for machine, condition := range unhealthyMachines {
+		labels := prometheus.Labels{
+			"name":               machine,
+			"namespace":          namespace,
+			"machinehealthcheck": name,
+			"condition":          condition,
+		}
+		MachineUnhealthy.With(labels).Set(1)
+		machineUnhealthyLabels[key] = append(machineUnhealthyLabels[key], labels)
+	}
+}
+
+// ObserveMachineHealthCheckMachinesHealth replaces the health of the machines covered by the named
+// MachineHealthCheck and updates the cluster wide healthy ratio. machines maps namespaced machine names
+// to whether the machine is healthy, nil removes the MachineHealthCheck. A machine covered by several
+// MachineHealthChecks is counted once, and only as healthy if all of them consider it healthy.
+func ObserveMachineHealthCheckMachinesHealth(name string, namespace string, machines map[string]bool) {
+	machinesHealthLock.Lock()
+	defer machinesHealthLock.Unlock()
+
+	key := namespace + "/" + name
+	if machines == nil {
+		delete(machinesHealth, key)
+	} else {
+		machinesHealth[key] = machines
+	}
+
+	healthy := map[string]bool{}
+	for _, mhcMachines := range machinesHealth {
+		for machine, machineHealthy := range mhcMachines {
+			if previous, ok := healthy[machine]; ok {
+				machineHealthy = previous && machineHealthy
+			}
+			healthy[machine] = machineHealthy
+		}
+	}
+
+	// report fully healthy when no machine is covered
+	ratio := float64(1)
+	if len(healthy) > 0 {
+		var healthyCount int
+		for _, machineHealthy := range healthy {
+			if machineHealthy {
+				healthyCount++
+			}
+		}
+		ratio = float64(healthyCount) / float64(len(healthy))
+	}
+	ClusterMachinesHealthyRatio.Set(ratio)
+}
+
+// DeleteMachineHealthCheckConfig removes the configuration reported for the named MachineHealthCheck
+func DeleteMachineHealthCheckConfig(name string, namespace string) {
+	machineHealthCheckConfigLabelsLock.Lock()
+	defer machineHealthCheckConfigLabelsLock.Unlock()
+
+	key := namespace + "/" + name
+	if labels, ok := machineHealthCheckConfigLabels[key]; ok {
+		MachineHealthCheckConfigInfo.Delete(labels)
+		delete(machineHealthCheckConfigLabels, key)
+	}
+}
+
+// ObserveMachineHealthCheckConfig replaces the configuration reported for the named MachineHealthCheck.
+// maxUnhealthy and nodeStartupTimeout are reported as they are formatted in the spec.
+func ObserveMachineHealthCheckConfig(name string, namespace string, maxUnhealthy string, unhealthyConditions int, nodeStartupTimeout time.Duration) {
+	machineHealthCheckConfigLabelsLock.Lock()
+	defer machineHealthCheckConfigLabelsLock.Unlock()
+
+	labels := prometheus.Labels{
+		"name":                 name,
+		"namespace":            namespace,
+		"max_unhealthy":        maxUnhealthy,
+		"unhealthy_conditions": strconv.Itoa(unhealthyConditions),
+		"node_startup_timeout": nodeStartupTimeout.String(),
+	}
+	key := namespace + "/" + name
+	if previous, ok := machineHealthCheckConfigLabels[key]; ok {
+		MachineHealthCheckConfigInfo.Delete(previous)
+	}
+	MachineHealthCheckConfigInfo.With(labels).Set(1)
+	machineHealthCheckConfigLabels[key] = labels
+}
+
+// ObserveClusterRemediationSuspended reports whether remediation is suspended cluster wide
+func ObserveClusterRemediationSuspended(suspended bool) {
+	if suspended {
+		ClusterRemediationSuspended.Set(1)
+		return
+	}
+	ClusterRemediationSuspended.Set(0)
+}
+
+func DeleteMachineHealthCheckMatchedMachines(name string, namespace string) {
+	MachineHealthCheckMatchedMachines.Delete(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	})
+}
+
+// ObserveMachineHealthCheckMatchedMachines records the number of machines matched by the selector of the MachineHealthCheck
+func ObserveMachineHealthCheckMatchedMachines(name string, namespace string, count int) {
+	MachineHealthCheckMatchedMachines.With(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	}).Set(float64(count))
+}
+
+func DeleteMachineHealthCheckOverlapping(name string, namespace string) {
+	MachineHealthCheckOverlappingTotal.Delete(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	})
+}
+
+// ObserveMachineHealthCheckOverlapping records the number of other MachineHealthChecks overlapping with the MachineHealthCheck
+func ObserveMachineHealthCheckOverlapping(name string, namespace string, count int) {
+	MachineHealthCheckOverlappingTotal.With(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	}).Set(float64(count))
+}
+
+func DeleteNodeConditionTransitions(node string, condition string) {
+	NodeConditionTransitionsTotal.Delete(prometheus.Labels{
+		"node":      node,
+		"condition": condition,
+	})
+}
+
+// ObserveNodeConditionTransition records a transition of the condition of the node
+func ObserveNodeConditionTransition(node string, condition string) {
+	NodeConditionTransitionsTotal.With(prometheus.Labels{
+		"node":      node,
+		"condition": condition,
+	}).Inc()
+}
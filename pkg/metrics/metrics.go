@@ -1,11 +1,22 @@
 package metrics
 
 import (
+	"fmt"
+	"time"
+
 	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 	machineinformers "github.com/openshift/machine-api-operator/pkg/generated/informers/externalversions/machine/v1beta1"
 	machinelisters "github.com/openshift/machine-api-operator/pkg/generated/listers/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
 	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
@@ -14,6 +25,10 @@ const (
 	DefaultMachineSetMetricsAddress = ":8082"
 	DefaultMachineMetricsAddress    = ":8081"
 	DefaultMetal3MetricsAddress     = ":60000"
+
+	// MissingProviderIDGracePeriod is the age after which a machine without a providerID is reported,
+	// machines are expected to be provisioned and have their providerID set within it
+	MissingProviderIDGracePeriod = 10 * time.Minute
 )
 
 var (
@@ -21,6 +36,14 @@ var (
 	MachineCountDesc = prometheus.NewDesc("mapi_machine_items", "Count of machine objects currently at the apiserver", nil, nil)
 	// MachineSetCountDesc Count of machineset object count at the apiserver
 	MachineSetCountDesc = prometheus.NewDesc("mapi_machineset_items", "Count of machinesets at the apiserver", nil, nil)
+	// MachineNodeNotReadyCountDesc is a metric about the count of machines whose node is not ready
+	MachineNodeNotReadyCountDesc = prometheus.NewDesc("mapi_machine_node_not_ready_count", "Count of machine objects whose node is not ready", nil, nil)
+	// MachineMissingProviderIDCountDesc is a metric about the count of machines without a providerID
+	MachineMissingProviderIDCountDesc = prometheus.NewDesc("mapi_machine_missing_provider_id_count", "Count of machine objects older than the grace period without a providerID", nil, nil)
+	// MachineExcludedCountDesc is a metric about the count of machines excluded from the per machine metrics by their phase
+	MachineExcludedCountDesc = prometheus.NewDesc("mapi_machine_excluded_items", "Count of machine objects whose phase excludes them from the per machine metrics", nil, nil)
+	// MachineAgeDesc is a metric about the age of machine objects in the cluster
+	MachineAgeDesc = prometheus.NewDesc("mapi_machine_age_seconds", "Number of seconds since the mapi managed Machine was created", []string{"name", "namespace"}, nil)
 	// MachineInfoDesc is a metric about machine object info in the cluster
 	MachineInfoDesc = prometheus.NewDesc("mapi_machine_created_timestamp_seconds", "Timestamp of the mapi managed Machine creation time", []string{"name", "namespace", "spec_provider_id", "node", "api_version", "phase"}, nil)
 	// MachineSetInfoDesc is a metric about machine object info in the cluster
@@ -35,12 +58,25 @@ var (
 	// MachineSetStatusReplicasDesc is the information of the Machineset's status for replicas.
 	MachineSetStatusReplicasDesc = prometheus.NewDesc("mapi_machine_set_status_replicas", "Information of the mapi managed Machineset's status for replicas", []string{"name", "namespace"}, nil)
 
+	// MachineSetStatusUnavailableReplicasDesc is the information of the Machineset's status for unavailable replicas.
+	MachineSetStatusUnavailableReplicasDesc = prometheus.NewDesc("mapi_machineset_status_unavailable_replicas", "Information of the mapi managed Machineset's status for unavailable replicas", []string{"name", "namespace"}, nil)
+
+	// MachineSetStatusUnavailableReasonDesc is the reason reported by a Machineset with unavailable replicas.
+	MachineSetStatusUnavailableReasonDesc = prometheus.NewDesc("mapi_machineset_status_unavailable_replicas_reason", "Reason reported by the mapi managed Machineset for its unavailable replicas", []string{"name", "namespace", "reason"}, nil)
+
 	// MachineCollectorUp is a Prometheus metric, which reports reflects successful collection and reporting of all the metrics
 	MachineCollectorUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "mapi_mao_collector_up",
 		Help: "Machine API Operator metrics are being collected and reported successfully",
 	}, []string{"kind"})
 
+	// OperatorDeploymentGenerationLag is a Prometheus metric, which reports how many generations the observed
+	// generation of a deployment managed by the operator lags behind its desired generation
+	OperatorDeploymentGenerationLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mapi_mao_deployment_generation_lag",
+		Help: "Number of generations the observed generation of a deployment managed by the Machine API Operator lags behind its desired generation",
+	}, []string{"name", "namespace"})
+
 	failedInstanceCreateCount = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "mapi_instance_create_failed",
@@ -73,11 +109,42 @@ var (
 			Buckets: []float64{5, 10, 20, 30, 60, 90, 120, 180, 240, 300, 360, 480, 600},
 		}, []string{"phase"},
 	)
+
+	// DrainDurationSeconds is a metric to capture the time taken to drain the node of a deleted Machine
+	DrainDurationSeconds = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "mapi_mhc_drain_duration_seconds",
+			Help:    "Number of seconds taken to drain the node of a deleted Machine.",
+			Buckets: []float64{1, 5, 10, 20, 30, 60, 120, 300, 600},
+		},
+	)
+
+	// DrainPodsEvictedTotal is a metric to count the pods evicted or deleted while draining nodes
+	DrainPodsEvictedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "mapi_mhc_pods_evicted_total",
+			Help: "Number of pods evicted or deleted while draining the nodes of deleted Machines.",
+		},
+	)
+
+	// DrainFailuresTotal is a metric to count the failed attempts to drain nodes
+	DrainFailuresTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "mapi_mhc_drain_failures_total",
+			Help: "Number of times draining the node of a deleted Machine has failed.",
+		},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(MachineCollectorUp)
+	prometheus.MustRegister(OperatorDeploymentGenerationLag)
 	metrics.Registry.MustRegister(MachinePhaseTransitionSeconds)
+	metrics.Registry.MustRegister(
+		DrainDurationSeconds,
+		DrainPodsEvictedTotal,
+		DrainFailuresTotal,
+	)
 	metrics.Registry.MustRegister(
 		failedInstanceCreateCount,
 		failedInstanceUpdateCount,
@@ -89,7 +156,16 @@ func init() {
 type MachineCollector struct {
 	machineLister    machinelisters.MachineLister
 	machineSetLister machinelisters.MachineSetLister
-	namespace        string
+	nodeLister       corelisters.NodeLister
+	// namespaces are the namespaces metrics are aggregated across,
+	// all namespaces are listed at once if it contains metav1.NamespaceAll
+	namespaces []string
+	// machineSelector limits the machines metrics are collected for
+	machineSelector labels.Selector
+	// excludedPhases contains the machine phases whose machines are left out of the
+	// per machine metrics, they are still counted
+	excludedPhases sets.String
+	clock          clock.PassiveClock
 }
 
 // MachineLabels is the group of labels that are applied to the machine metrics
@@ -99,11 +175,22 @@ type MachineLabels struct {
 	Reason    string
 }
 
-func NewMachineCollector(machineInformer machineinformers.MachineInformer, machinesetInformer machineinformers.MachineSetInformer, namespace string) *MachineCollector {
+// NewMachineCollector returns a MachineCollector reporting metrics for the machines matching machineSelector
+// and all machinesets in the given namespaces, or in all namespaces if they contain metav1.NamespaceAll.
+// Metrics are collected for all machines if machineSelector is nil. Machines in one of excludedPhases,
+// e.g. "Deleting", are only counted rather than reported individually.
+func NewMachineCollector(machineInformer machineinformers.MachineInformer, machinesetInformer machineinformers.MachineSetInformer, nodeInformer coreinformers.NodeInformer, namespaces []string, machineSelector labels.Selector, excludedPhases []string) *MachineCollector {
+	if machineSelector == nil {
+		machineSelector = labels.Everything()
+	}
 	return &MachineCollector{
 		machineLister:    machineInformer.Lister(),
 		machineSetLister: machinesetInformer.Lister(),
-		namespace:        namespace,
+		nodeLister:       nodeInformer.Lister(),
+		namespaces:       collectedNamespaces(namespaces),
+		machineSelector:  machineSelector,
+		excludedPhases:   sets.NewString(excludedPhases...),
+		clock:            clock.RealClock{},
 	}
 }
 
@@ -117,6 +204,9 @@ func (mc *MachineCollector) Collect(ch chan<- prometheus.Metric) {
 func (mc MachineCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- MachineCountDesc
 	ch <- MachineSetCountDesc
+	ch <- MachineNodeNotReadyCountDesc
+	ch <- MachineMissingProviderIDCountDesc
+	ch <- MachineExcludedCountDesc
 }
 
 // Collect implements the prometheus.Collector interface.
@@ -128,14 +218,29 @@ func (mc MachineCollector) collectMachineMetrics(ch chan<- prometheus.Metric) {
 	}
 	MachineCollectorUp.With(prometheus.Labels{"kind": "mapi_machine_items"}).Set(float64(1))
 
+	nodeNotReadyCount := 0
+	missingProviderIDCount := 0
+	excludedCount := 0
 	for _, machine := range machineList {
+		if mc.hasNodeNotReady(machine) {
+			nodeNotReadyCount++
+		}
+		if mc.hasMissingProviderID(machine) {
+			missingProviderIDCount++
+		}
+
+		phase := stringPointerDeref(machine.Status.Phase)
+		if mc.excludedPhases.Has(phase) {
+			excludedCount++
+			continue
+		}
+
 		nodeName := ""
 		if machine.Status.NodeRef != nil {
 			nodeName = machine.Status.NodeRef.Name
 		}
 		// Only gather metrics for machines with a phase.  This indicates
 		// That the machine-controller is running on this cluster.
-		phase := stringPointerDeref(machine.Status.Phase)
 		if phase != "" {
 			ch <- prometheus.MustNewConstMetric(
 				MachineInfoDesc,
@@ -149,12 +254,49 @@ func (mc MachineCollector) collectMachineMetrics(ch chan<- prometheus.Metric) {
 				phase,
 			)
 		}
+
+		ch <- prometheus.MustNewConstMetric(
+			MachineAgeDesc,
+			prometheus.GaugeValue,
+			mc.clock.Since(machine.ObjectMeta.GetCreationTimestamp().Time).Seconds(),
+			machine.ObjectMeta.Name,
+			machine.ObjectMeta.Namespace,
+		)
 	}
 
 	ch <- prometheus.MustNewConstMetric(MachineCountDesc, prometheus.GaugeValue, float64(len(machineList)))
+	ch <- prometheus.MustNewConstMetric(MachineNodeNotReadyCountDesc, prometheus.GaugeValue, float64(nodeNotReadyCount))
+	ch <- prometheus.MustNewConstMetric(MachineMissingProviderIDCountDesc, prometheus.GaugeValue, float64(missingProviderIDCount))
+	ch <- prometheus.MustNewConstMetric(MachineExcludedCountDesc, prometheus.GaugeValue, float64(excludedCount))
 	klog.V(4).Infof("collectmachineMetrics exit")
 }
 
+// hasNodeNotReady returns true if the machine references a node which is not ready.
+// Machines without a node are not considered.
+func (mc MachineCollector) hasNodeNotReady(machine *mapiv1beta1.Machine) bool {
+	if machine.Status.NodeRef == nil {
+		return false
+	}
+	node, err := mc.nodeLister.Get(machine.Status.NodeRef.Name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.Errorf("Failed to get node %q of machine %q: %v", machine.Status.NodeRef.Name, machine.Name, err)
+		}
+		return false
+	}
+	readyCondition := conditions.GetNodeCondition(node, corev1.NodeReady)
+	return readyCondition == nil || readyCondition.Status != corev1.ConditionTrue
+}
+
+// hasMissingProviderID returns true if the machine has no providerID although it is older
+// than the grace period, which usually indicates a failure to provision its instance
+func (mc MachineCollector) hasMissingProviderID(machine *mapiv1beta1.Machine) bool {
+	if stringPointerDeref(machine.Spec.ProviderID) != "" {
+		return false
+	}
+	return mc.clock.Since(machine.ObjectMeta.GetCreationTimestamp().Time) > MissingProviderIDGracePeriod
+}
+
 func stringPointerDeref(stringPointer *string) string {
 	if stringPointer != nil {
 		return *stringPointer
@@ -198,15 +340,80 @@ func (mc MachineCollector) collectMachineSetMetrics(ch chan<- prometheus.Metric)
 			float64(machineSet.Status.Replicas),
 			machineSet.Name, machineSet.Namespace,
 		)
+
+		unavailableReplicas := machineSet.Status.Replicas - machineSet.Status.AvailableReplicas
+		if unavailableReplicas < 0 {
+			unavailableReplicas = 0
+		}
+		ch <- prometheus.MustNewConstMetric(
+			MachineSetStatusUnavailableReplicasDesc,
+			prometheus.GaugeValue,
+			float64(unavailableReplicas),
+			machineSet.Name, machineSet.Namespace,
+		)
+		if unavailableReplicas > 0 && machineSet.Status.ErrorReason != nil {
+			ch <- prometheus.MustNewConstMetric(
+				MachineSetStatusUnavailableReasonDesc,
+				prometheus.GaugeValue,
+				1,
+				machineSet.Name, machineSet.Namespace, string(*machineSet.Status.ErrorReason),
+			)
+		}
+	}
+}
+
+// collectedNamespaces deduplicates the given namespaces, collapsing them
+// to metav1.NamespaceAll if any of them selects all namespaces
+func collectedNamespaces(namespaces []string) []string {
+	seen := sets.NewString()
+	var collected []string
+	for _, namespace := range namespaces {
+		if namespace == metav1.NamespaceAll {
+			return []string{metav1.NamespaceAll}
+		}
+		if !seen.Has(namespace) {
+			seen.Insert(namespace)
+			collected = append(collected, namespace)
+		}
 	}
+	return collected
 }
 
 func (mc MachineCollector) listMachines() ([]*mapiv1beta1.Machine, error) {
-	return mc.machineLister.Machines(mc.namespace).List(labels.Everything())
+	var machines []*mapiv1beta1.Machine
+	for _, namespace := range mc.namespaces {
+		namespaceMachines, err := mc.machineLister.Machines(namespace).List(mc.machineSelector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list machines in namespace %q: %v", namespace, err)
+		}
+		machines = append(machines, namespaceMachines...)
+	}
+	return machines, nil
 }
 
 func (mc MachineCollector) listMachineSets() ([]*mapiv1beta1.MachineSet, error) {
-	return mc.machineSetLister.MachineSets(mc.namespace).List(labels.Everything())
+	var machineSets []*mapiv1beta1.MachineSet
+	for _, namespace := range mc.namespaces {
+		namespaceMachineSets, err := mc.machineSetLister.MachineSets(namespace).List(labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list machinesets in namespace %q: %v", namespace, err)
+		}
+		machineSets = append(machineSets, namespaceMachineSets...)
+	}
+	return machineSets, nil
+}
+
+// ObserveOperatorDeploymentGeneration records how many generations the observed generation of
+// a deployment managed by the operator lags behind its desired generation
+func ObserveOperatorDeploymentGeneration(name string, namespace string, generation int64, observedGeneration int64) {
+	lag := generation - observedGeneration
+	if lag < 0 {
+		lag = 0
+	}
+	OperatorDeploymentGenerationLag.With(prometheus.Labels{
+		"name":      name,
+		"namespace": namespace,
+	}).Set(float64(lag))
 }
 
 func RegisterFailedInstanceCreate(labels *MachineLabels) {
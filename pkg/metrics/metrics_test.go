@@ -1,6 +1,24 @@
 package metrics
 
-import "testing"
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	fakemachine "github.com/openshift/machine-api-operator/pkg/generated/clientset/versioned/fake"
+	machineinformers "github.com/openshift/machine-api-operator/pkg/generated/informers/externalversions"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	kubeinformers "k8s.io/client-go/informers"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
 
 func TestStringPointerDeref(t *testing.T) {
 	value := "test"
@@ -23,3 +41,530 @@ func TestStringPointerDeref(t *testing.T) {
 		}
 	}
 }
+
+func TestMachineCollectorSelector(t *testing.T) {
+	namespace := "test"
+	newMachine := func(name, role string) *mapiv1beta1.Machine {
+		return &mapiv1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{"role": role},
+			},
+			Status: mapiv1beta1.MachineStatus{
+				Phase: pointer.StringPtr("Running"),
+			},
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		selector         labels.Selector
+		expectedMachines []string
+	}{
+		{
+			name:             "no selector",
+			selector:         nil,
+			expectedMachines: []string{"infra", "worker"},
+		},
+		{
+			name:             "scoped selector",
+			selector:         labels.SelectorFromSet(labels.Set{"role": "infra"}),
+			expectedMachines: []string{"infra"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			informerFactory := machineinformers.NewSharedInformerFactory(fakemachine.NewSimpleClientset(), 0)
+			machineInformer := informerFactory.Machine().V1beta1().Machines()
+			for _, machine := range []*mapiv1beta1.Machine{newMachine("infra", "infra"), newMachine("worker", "worker")} {
+				if err := machineInformer.Informer().GetIndexer().Add(machine); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			nodeInformer := kubeinformers.NewSharedInformerFactory(fakekube.NewSimpleClientset(), 0).Core().V1().Nodes()
+			collector := NewMachineCollector(machineInformer, informerFactory.Machine().V1beta1().MachineSets(), nodeInformer, []string{namespace}, tc.selector, nil)
+
+			ch := make(chan prometheus.Metric, 10)
+			collector.collectMachineMetrics(ch)
+			close(ch)
+
+			var machines []string
+			for metric := range ch {
+				if metric.Desc() != MachineInfoDesc {
+					continue
+				}
+				m := &dto.Metric{}
+				if err := metric.Write(m); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				for _, label := range m.GetLabel() {
+					if label.GetName() == "name" {
+						machines = append(machines, label.GetValue())
+					}
+				}
+			}
+			sort.Strings(machines)
+			if !reflect.DeepEqual(machines, tc.expectedMachines) {
+				t.Errorf("Expected metrics for machines %v, got %v", tc.expectedMachines, machines)
+			}
+		})
+	}
+}
+
+func TestMachineCollectorNamespaces(t *testing.T) {
+	newMachine := func(namespace, name string) *mapiv1beta1.Machine {
+		return &mapiv1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		namespaces       []string
+		expectedMachines []string
+	}{
+		{
+			name:             "single namespace",
+			namespaces:       []string{"a"},
+			expectedMachines: []string{"a/machine-0", "a/machine-1"},
+		},
+		{
+			name:             "two namespaces",
+			namespaces:       []string{"a", "b", "a"},
+			expectedMachines: []string{"a/machine-0", "a/machine-1", "b/machine-0"},
+		},
+		{
+			name:             "all namespaces",
+			namespaces:       []string{"a", metav1.NamespaceAll},
+			expectedMachines: []string{"a/machine-0", "a/machine-1", "b/machine-0", "c/machine-0"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			informerFactory := machineinformers.NewSharedInformerFactory(fakemachine.NewSimpleClientset(), 0)
+			machineInformer := informerFactory.Machine().V1beta1().Machines()
+			machineSetInformer := informerFactory.Machine().V1beta1().MachineSets()
+			machines := []*mapiv1beta1.Machine{
+				newMachine("a", "machine-0"),
+				newMachine("a", "machine-1"),
+				newMachine("b", "machine-0"),
+				newMachine("c", "machine-0"),
+			}
+			for _, machine := range machines {
+				if err := machineInformer.Informer().GetIndexer().Add(machine); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				machineSet := &mapiv1beta1.MachineSet{ObjectMeta: machine.ObjectMeta}
+				if err := machineSetInformer.Informer().GetIndexer().Add(machineSet); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			nodeInformer := kubeinformers.NewSharedInformerFactory(fakekube.NewSimpleClientset(), 0).Core().V1().Nodes()
+			collector := NewMachineCollector(machineInformer, machineSetInformer, nodeInformer, tc.namespaces, nil, nil)
+
+			machineList, err := collector.listMachines()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var got []string
+			for _, machine := range machineList {
+				got = append(got, machine.Namespace+"/"+machine.Name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tc.expectedMachines) {
+				t.Errorf("Expected machines %v, got %v", tc.expectedMachines, got)
+			}
+
+			machineSetList, err := collector.listMachineSets()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(machineSetList) != len(tc.expectedMachines) {
+				t.Errorf("Expected %d machinesets, got %d", len(tc.expectedMachines), len(machineSetList))
+			}
+
+			ch := make(chan prometheus.Metric, 20)
+			collector.collectMachineMetrics(ch)
+			close(ch)
+			for metric := range ch {
+				if metric.Desc() != MachineCountDesc {
+					continue
+				}
+				m := &dto.Metric{}
+				if err := metric.Write(m); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if count := int(m.GetGauge().GetValue()); count != len(tc.expectedMachines) {
+					t.Errorf("Expected machine count %d, got %d", len(tc.expectedMachines), count)
+				}
+			}
+		})
+	}
+}
+
+func TestMachineSetUnavailableReplicasMetrics(t *testing.T) {
+	namespace := "test"
+	errorReason := mapiv1beta1.InvalidConfigurationMachineSetError
+
+	testCases := []struct {
+		name                string
+		status              mapiv1beta1.MachineSetStatus
+		expectedUnavailable float64
+		expectedReason      string
+	}{
+		{
+			name: "available",
+			status: mapiv1beta1.MachineSetStatus{
+				Replicas:          3,
+				AvailableReplicas: 3,
+			},
+			expectedUnavailable: 0,
+		},
+		{
+			name: "degraded without reason",
+			status: mapiv1beta1.MachineSetStatus{
+				Replicas:          3,
+				AvailableReplicas: 1,
+			},
+			expectedUnavailable: 2,
+		},
+		{
+			name: "degraded with reason",
+			status: mapiv1beta1.MachineSetStatus{
+				Replicas:          3,
+				AvailableReplicas: 1,
+				ErrorReason:       &errorReason,
+			},
+			expectedUnavailable: 2,
+			expectedReason:      string(errorReason),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			informerFactory := machineinformers.NewSharedInformerFactory(fakemachine.NewSimpleClientset(), 0)
+			machineSetInformer := informerFactory.Machine().V1beta1().MachineSets()
+			machineSet := &mapiv1beta1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "machineset",
+					Namespace: namespace,
+				},
+				Status: tc.status,
+			}
+			if err := machineSetInformer.Informer().GetIndexer().Add(machineSet); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			nodeInformer := kubeinformers.NewSharedInformerFactory(fakekube.NewSimpleClientset(), 0).Core().V1().Nodes()
+			collector := NewMachineCollector(informerFactory.Machine().V1beta1().Machines(), machineSetInformer, nodeInformer, []string{namespace}, nil, nil)
+
+			ch := make(chan prometheus.Metric, 10)
+			collector.collectMachineSetMetrics(ch)
+			close(ch)
+
+			var unavailable *float64
+			var reason string
+			for metric := range ch {
+				m := &dto.Metric{}
+				if err := metric.Write(m); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				switch metric.Desc() {
+				case MachineSetStatusUnavailableReplicasDesc:
+					unavailable = pointer.Float64Ptr(m.GetGauge().GetValue())
+				case MachineSetStatusUnavailableReasonDesc:
+					for _, label := range m.GetLabel() {
+						if label.GetName() == "reason" {
+							reason = label.GetValue()
+						}
+					}
+				}
+			}
+			if unavailable == nil || *unavailable != tc.expectedUnavailable {
+				t.Errorf("Expected %v unavailable replicas, got %v", tc.expectedUnavailable, unavailable)
+			}
+			if reason != tc.expectedReason {
+				t.Errorf("Expected reason %q, got %q", tc.expectedReason, reason)
+			}
+		})
+	}
+}
+
+func TestMachineNodeNotReadyCount(t *testing.T) {
+	namespace := "test"
+	newNode := func(name string, ready corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: ready},
+				},
+			},
+		}
+	}
+	newMachine := func(name, nodeName string) *mapiv1beta1.Machine {
+		machine := &mapiv1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+		if nodeName != "" {
+			machine.Status.NodeRef = &corev1.ObjectReference{Name: nodeName}
+		}
+		return machine
+	}
+
+	nodes := []*corev1.Node{
+		newNode("ready", corev1.ConditionTrue),
+		newNode("notReady", corev1.ConditionFalse),
+		newNode("unknown", corev1.ConditionUnknown),
+		{ObjectMeta: metav1.ObjectMeta{Name: "noConditions"}},
+	}
+	machines := []*mapiv1beta1.Machine{
+		newMachine("machineReady", "ready"),
+		newMachine("machineNotReady", "notReady"),
+		newMachine("machineUnknown", "unknown"),
+		newMachine("machineNoConditions", "noConditions"),
+		newMachine("machineNoNode", ""),
+		newMachine("machineNodeNotFound", "notFound"),
+	}
+
+	machineInformerFactory := machineinformers.NewSharedInformerFactory(fakemachine.NewSimpleClientset(), 0)
+	machineInformer := machineInformerFactory.Machine().V1beta1().Machines()
+	for _, machine := range machines {
+		if err := machineInformer.Informer().GetIndexer().Add(machine); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	nodeInformer := kubeinformers.NewSharedInformerFactory(fakekube.NewSimpleClientset(), 0).Core().V1().Nodes()
+	for _, node := range nodes {
+		if err := nodeInformer.Informer().GetIndexer().Add(node); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	collector := NewMachineCollector(machineInformer, machineInformerFactory.Machine().V1beta1().MachineSets(), nodeInformer, []string{namespace}, nil, nil)
+
+	ch := make(chan prometheus.Metric, 10)
+	collector.collectMachineMetrics(ch)
+	close(ch)
+
+	var notReady *float64
+	for metric := range ch {
+		if metric.Desc() != MachineNodeNotReadyCountDesc {
+			continue
+		}
+		m := &dto.Metric{}
+		if err := metric.Write(m); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		notReady = pointer.Float64Ptr(m.GetGauge().GetValue())
+	}
+	if notReady == nil || *notReady != 3 {
+		t.Errorf("Expected 3 machines with a node not ready, got %v", notReady)
+	}
+}
+
+func TestMachineAgeMetric(t *testing.T) {
+	namespace := "test"
+	now := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	machine := &mapiv1beta1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "machine",
+			Namespace:         namespace,
+			CreationTimestamp: metav1.NewTime(now.Add(-36 * time.Hour)),
+		},
+	}
+
+	machineInformerFactory := machineinformers.NewSharedInformerFactory(fakemachine.NewSimpleClientset(), 0)
+	machineInformer := machineInformerFactory.Machine().V1beta1().Machines()
+	if err := machineInformer.Informer().GetIndexer().Add(machine); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	nodeInformer := kubeinformers.NewSharedInformerFactory(fakekube.NewSimpleClientset(), 0).Core().V1().Nodes()
+	collector := NewMachineCollector(machineInformer, machineInformerFactory.Machine().V1beta1().MachineSets(), nodeInformer, []string{namespace}, nil, nil)
+	collector.clock = clock.NewFakePassiveClock(now)
+
+	ch := make(chan prometheus.Metric, 10)
+	collector.collectMachineMetrics(ch)
+	close(ch)
+
+	var age *float64
+	for metric := range ch {
+		if metric.Desc() != MachineAgeDesc {
+			continue
+		}
+		m := &dto.Metric{}
+		if err := metric.Write(m); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		age = pointer.Float64Ptr(m.GetGauge().GetValue())
+	}
+	if expected := (36 * time.Hour).Seconds(); age == nil || *age != expected {
+		t.Errorf("Expected machine age of %vs, got %v", expected, age)
+	}
+}
+
+func TestMachineMetricsExcludedPhases(t *testing.T) {
+	namespace := "test"
+	newMachine := func(name string, phase string) *mapiv1beta1.Machine {
+		return &mapiv1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Status: mapiv1beta1.MachineStatus{
+				Phase: pointer.StringPtr(phase),
+			},
+		}
+	}
+
+	machineInformerFactory := machineinformers.NewSharedInformerFactory(fakemachine.NewSimpleClientset(), 0)
+	machineInformer := machineInformerFactory.Machine().V1beta1().Machines()
+	for _, machine := range []*mapiv1beta1.Machine{newMachine("running", "Running"), newMachine("deleting", "Deleting")} {
+		if err := machineInformer.Informer().GetIndexer().Add(machine); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	nodeInformer := kubeinformers.NewSharedInformerFactory(fakekube.NewSimpleClientset(), 0).Core().V1().Nodes()
+	collector := NewMachineCollector(machineInformer, machineInformerFactory.Machine().V1beta1().MachineSets(), nodeInformer, []string{namespace}, nil, []string{"Deleting"})
+
+	ch := make(chan prometheus.Metric, 10)
+	collector.collectMachineMetrics(ch)
+	close(ch)
+
+	var infoPhases []string
+	counts := map[*prometheus.Desc]float64{}
+	for metric := range ch {
+		m := &dto.Metric{}
+		if err := metric.Write(m); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		switch metric.Desc() {
+		case MachineInfoDesc:
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "phase" {
+					infoPhases = append(infoPhases, label.GetValue())
+				}
+			}
+		case MachineCountDesc, MachineExcludedCountDesc:
+			counts[metric.Desc()] = m.GetGauge().GetValue()
+		}
+	}
+
+	if expected := []string{"Running"}; !reflect.DeepEqual(infoPhases, expected) {
+		t.Errorf("Expected info metrics for phases %v, got %v", expected, infoPhases)
+	}
+	if got := counts[MachineExcludedCountDesc]; got != 1 {
+		t.Errorf("Expected 1 excluded machine, got %v", got)
+	}
+	if got := counts[MachineCountDesc]; got != 2 {
+		t.Errorf("Expected 2 machines, got %v", got)
+	}
+}
+
+func TestClusterMachinesHealthyRatio(t *testing.T) {
+	machinesHealth = map[string]map[string]bool{}
+	defer func() {
+		machinesHealth = map[string]map[string]bool{}
+	}()
+
+	ratio := func() float64 {
+		m := &dto.Metric{}
+		if err := ClusterMachinesHealthyRatio.Write(m); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return m.GetGauge().GetValue()
+	}
+
+	// the selectors of the two MachineHealthChecks overlap on machine-1 and machine-2
+	ObserveMachineHealthCheckMachinesHealth("all", "test", map[string]bool{
+		"test/machine-1": true,
+		"test/machine-2": true,
+		"test/machine-3": false,
+	})
+	ObserveMachineHealthCheckMachinesHealth("subset", "test", map[string]bool{
+		"test/machine-1": true,
+		"test/machine-2": true,
+	})
+	if got, expected := ratio(), 2.0/3.0; got != expected {
+		t.Errorf("Expected each machine to be counted once with a ratio of %v, got %v", expected, got)
+	}
+
+	// a machine is only healthy if all of its MachineHealthChecks consider it healthy
+	ObserveMachineHealthCheckMachinesHealth("subset", "test", map[string]bool{
+		"test/machine-1": true,
+		"test/machine-2": false,
+	})
+	if got, expected := ratio(), 1.0/3.0; got != expected {
+		t.Errorf("Expected a ratio of %v, got %v", expected, got)
+	}
+
+	ObserveMachineHealthCheckMachinesHealth("all", "test", nil)
+	if got, expected := ratio(), 1.0/2.0; got != expected {
+		t.Errorf("Expected a ratio of %v after removing a MachineHealthCheck, got %v", expected, got)
+	}
+
+	ObserveMachineHealthCheckMachinesHealth("subset", "test", nil)
+	if got, expected := ratio(), 1.0; got != expected {
+		t.Errorf("Expected a ratio of %v without any machine covered, got %v", expected, got)
+	}
+}
+
+func TestMachineMissingProviderIDCount(t *testing.T) {
+	namespace := "test"
+	now := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	newMachine := func(name string, providerID *string, age time.Duration) *mapiv1beta1.Machine {
+		return &mapiv1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+			Spec: mapiv1beta1.MachineSpec{
+				ProviderID: providerID,
+			},
+		}
+	}
+	machines := []*mapiv1beta1.Machine{
+		newMachine("populated", pointer.StringPtr("aws:///us-east-1a/i-0"), time.Hour),
+		newMachine("empty", pointer.StringPtr(""), time.Hour),
+		newMachine("nil", nil, time.Hour),
+		newMachine("provisioning", nil, time.Minute),
+	}
+
+	machineInformerFactory := machineinformers.NewSharedInformerFactory(fakemachine.NewSimpleClientset(), 0)
+	machineInformer := machineInformerFactory.Machine().V1beta1().Machines()
+	for _, machine := range machines {
+		if err := machineInformer.Informer().GetIndexer().Add(machine); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	nodeInformer := kubeinformers.NewSharedInformerFactory(fakekube.NewSimpleClientset(), 0).Core().V1().Nodes()
+	collector := NewMachineCollector(machineInformer, machineInformerFactory.Machine().V1beta1().MachineSets(), nodeInformer, []string{namespace}, nil, nil)
+	collector.clock = clock.NewFakeClock(now)
+
+	ch := make(chan prometheus.Metric, 20)
+	collector.collectMachineMetrics(ch)
+	close(ch)
+
+	var missing *float64
+	for metric := range ch {
+		if metric.Desc() != MachineMissingProviderIDCountDesc {
+			continue
+		}
+		m := &dto.Metric{}
+		if err := metric.Write(m); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		missing = pointer.Float64Ptr(m.GetGauge().GetValue())
+	}
+	if missing == nil || *missing != 2 {
+		t.Errorf("Expected 2 machines missing a providerID, got %v", missing)
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"regexp"
 
 	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 )
 
 const (
@@ -14,8 +17,26 @@ const (
 	clusterAPIControllerKubemark = "docker.io/gofed/kubemark-machine-controllers:v1.0"
 	clusterAPIControllerNoOp     = "no-op"
 	kubemarkPlatform             = configv1.PlatformType("kubemark")
+
+	// operatorConfigConfigMapName is the name of the ConfigMap overriding the
+	// target namespace and controller images of MAO
+	operatorConfigConfigMapName = "machine-api-operator-config"
+)
+
+// Keys of the operator config ConfigMap
+const (
+	operatorConfigTargetNamespaceKey    = "targetNamespace"
+	operatorConfigProviderKey           = "provider"
+	operatorConfigMachineSetKey         = "machineSet"
+	operatorConfigNodeLinkKey           = "nodeLink"
+	operatorConfigMachineHealthCheckKey = "machineHealthCheck"
+	operatorConfigKubeRBACProxyKey      = "kubeRBACProxy"
+	operatorConfigTerminationHandlerKey = "terminationHandler"
 )
 
+// imageReferenceRegexp matches image references of the form [registry[:port]/]repository[:tag][@digest]
+var imageReferenceRegexp = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*(:[0-9]+)?(/[a-z0-9]+([._-][a-z0-9]+)*)*(:[A-Za-z0-9_][A-Za-z0-9_.-]{0,127})?(@sha256:[a-f0-9]{64})?$`)
+
 type Provider string
 
 // OperatorConfig contains configuration for MAO
@@ -69,6 +90,37 @@ func getImagesFromJSONFile(filePath string) (*Images, error) {
 	return &i, nil
 }
 
+// applyOperatorConfigOverrides overrides the fields of the operator config with the keys set in
+// the ConfigMap. Keys which are missing or empty keep the value selected for the platform, images
+// set must be valid image references.
+func applyOperatorConfigOverrides(config *OperatorConfig, cm *corev1.ConfigMap) error {
+	var errs []error
+	for key, field := range map[string]*string{
+		operatorConfigProviderKey:           &config.Controllers.Provider,
+		operatorConfigMachineSetKey:         &config.Controllers.MachineSet,
+		operatorConfigNodeLinkKey:           &config.Controllers.NodeLink,
+		operatorConfigMachineHealthCheckKey: &config.Controllers.MachineHealthCheck,
+		operatorConfigKubeRBACProxyKey:      &config.Controllers.KubeRBACProxy,
+		operatorConfigTerminationHandlerKey: &config.Controllers.TerminationHandler,
+	} {
+		image := cm.Data[key]
+		if image == "" {
+			continue
+		}
+		if image != clusterAPIControllerNoOp && !imageReferenceRegexp.MatchString(image) {
+			errs = append(errs, fmt.Errorf("configmap %s/%s key %q is not a valid image reference: %q", cm.Namespace, cm.Name, key, image))
+			continue
+		}
+		*field = image
+	}
+
+	if targetNamespace := cm.Data[operatorConfigTargetNamespaceKey]; targetNamespace != "" {
+		config.TargetNamespace = targetNamespace
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
 func getProviderControllerFromImages(platform configv1.PlatformType, images Images) (string, error) {
 	switch platform {
 	case configv1.AWSPlatformType:
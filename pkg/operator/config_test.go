@@ -1,9 +1,12 @@
 package operator
 
 import (
+	"reflect"
 	"testing"
 
 	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var (
@@ -299,3 +302,81 @@ func TestGetKubeRBACProxyFromImages(t *testing.T) {
 		t.Errorf("failed getKubeRBACProxyFromImages. Expected: %s, got: %s", expectedKubeRBACProxyImage, res)
 	}
 }
+
+func TestApplyOperatorConfigOverrides(t *testing.T) {
+	newConfig := func() *OperatorConfig {
+		return &OperatorConfig{
+			TargetNamespace: "openshift-machine-api",
+			Controllers: Controllers{
+				Provider:           expectedAWSImage,
+				MachineSet:         expectedMachineAPIOperatorImage,
+				NodeLink:           expectedMachineAPIOperatorImage,
+				MachineHealthCheck: expectedMachineAPIOperatorImage,
+				KubeRBACProxy:      expectedKubeRBACProxyImage,
+				TerminationHandler: expectedAWSImage,
+			},
+		}
+	}
+	newConfigMap := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      operatorConfigConfigMapName,
+				Namespace: "openshift-machine-api",
+			},
+			Data: data,
+		}
+	}
+	digest := "quay.io/openshift/origin-aws-machine-controllers@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name          string
+		configMap     *corev1.ConfigMap
+		expected      func(*OperatorConfig)
+		expectedError bool
+	}{{
+		name:      "no keys set",
+		configMap: newConfigMap(nil),
+		expected:  func(*OperatorConfig) {},
+	}, {
+		name: "empty keys keep the platform images",
+		configMap: newConfigMap(map[string]string{
+			operatorConfigTargetNamespaceKey: "",
+			operatorConfigProviderKey:        "",
+		}),
+		expected: func(*OperatorConfig) {},
+	}, {
+		name: "set keys override the platform images",
+		configMap: newConfigMap(map[string]string{
+			operatorConfigTargetNamespaceKey: "test-namespace",
+			operatorConfigProviderKey:        digest,
+			operatorConfigNodeLinkKey:        clusterAPIControllerNoOp,
+		}),
+		expected: func(config *OperatorConfig) {
+			config.TargetNamespace = "test-namespace"
+			config.Controllers.Provider = digest
+			config.Controllers.NodeLink = clusterAPIControllerNoOp
+		},
+	}, {
+		name:          "invalid image",
+		configMap:     newConfigMap(map[string]string{operatorConfigNodeLinkKey: "Not An Image"}),
+		expectedError: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := newConfig()
+			err := applyOperatorConfigOverrides(config, test.configMap)
+			if (err != nil) != test.expectedError {
+				t.Fatalf("failed applyOperatorConfigOverrides. Expected error: %t, got: %v", test.expectedError, err)
+			}
+			if test.expectedError {
+				return
+			}
+			expected := newConfig()
+			test.expected(expected)
+			if !reflect.DeepEqual(config, expected) {
+				t.Errorf("failed applyOperatorConfigOverrides. Expected: %+v, got: %+v", expected, config)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	osconfigv1 "github.com/openshift/api/config/v1"
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// on machine health checks we only reconcile when their remediation starts or stops failing
+func (optr *Operator) eventHandlerMachineHealthChecks() cache.ResourceEventHandler {
+	workQueueKey := fmt.Sprintf("%s/%s", optr.namespace, optr.name)
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if isRemediationFailing(obj) {
+				logResource(obj)
+				optr.queue.Add(workQueueKey)
+			}
+		},
+		UpdateFunc: func(old, new interface{}) {
+			if isRemediationFailing(old) != isRemediationFailing(new) {
+				logResource(new)
+				optr.queue.Add(workQueueKey)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if isRemediationFailing(obj) {
+				logResource(obj)
+				optr.queue.Add(workQueueKey)
+			}
+		},
+	}
+}
+
+// isRemediationFailing reports whether the given object is a MachineHealthCheck whose
+// remediation is repeatedly failing. Masters whose deletion is refused by the MachineHealthCheck
+// also set RemediationSucceeding to False, but as configured, so they do not count.
+func isRemediationFailing(obj interface{}) bool {
+	mhc, ok := obj.(*mapiv1.MachineHealthCheck)
+	if !ok {
+		return false
+	}
+	condition := conditions.Get(mhc, mapiv1.RemediationSucceedingCondition)
+	return condition != nil && condition.Status == corev1.ConditionFalse && condition.Reason == mapiv1.RepeatedRemediationFailuresReason
+}
+
+// machineHealthChecksDegradedCondition returns the Degraded condition reflecting the remediation of
+// the MachineHealthChecks. It is True while the remediation of any of them is repeatedly failing.
+func (optr *Operator) machineHealthChecksDegradedCondition() osconfigv1.ClusterOperatorStatusCondition {
+	asExpected := newClusterOperatorStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionFalse, string(ReasonAsExpected), "")
+	if optr.mhcLister == nil {
+		return asExpected
+	}
+
+	mhcs, err := optr.mhcLister.MachineHealthChecks(optr.namespace).List(labels.Everything())
+	if err != nil {
+		// remediation failures are not a reason to fail syncing the operands
+		klog.Errorf("Error listing MachineHealthChecks: %v", err)
+		return asExpected
+	}
+
+	var failing []string
+	for _, mhc := range mhcs {
+		if isRemediationFailing(mhc) {
+			failing = append(failing, mhc.Name)
+		}
+	}
+	if len(failing) == 0 {
+		return asExpected
+	}
+	sort.Strings(failing)
+	return newClusterOperatorStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionTrue, string(ReasonRemediationFailing),
+		fmt.Sprintf("Remediation of MachineHealthChecks %s is repeatedly failing", strings.Join(failing, ", ")))
+}
@@ -0,0 +1,108 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	osconfigv1 "github.com/openshift/api/config/v1"
+	fakeconfigclientset "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	mapiv1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinelistersv1beta1 "github.com/openshift/machine-api-operator/pkg/generated/listers/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestOperatorStatusMachineHealthCheckRemediationFailing(t *testing.T) {
+	newMHC := func(name string, condition *mapiv1.Condition) *mapiv1.MachineHealthCheck {
+		mhc := &mapiv1.MachineHealthCheck{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: targetNamespace},
+		}
+		if condition != nil {
+			conditions.Set(mhc, condition)
+		}
+		return mhc
+	}
+	failing := conditions.FalseCondition(mapiv1.RemediationSucceedingCondition, mapiv1.RepeatedRemediationFailuresReason, mapiv1.ConditionSeverityError, "")
+
+	testCases := []struct {
+		name             string
+		mhcs             []*mapiv1.MachineHealthCheck
+		expectedDegraded osconfigv1.ConditionStatus
+		expectedReason   StatusReason
+	}{
+		{
+			name:             "no MachineHealthChecks",
+			expectedDegraded: osconfigv1.ConditionFalse,
+			expectedReason:   ReasonAsExpected,
+		},
+		{
+			name: "remediation never failed repeatedly",
+			mhcs: []*mapiv1.MachineHealthCheck{
+				newMHC("healthy", nil),
+			},
+			expectedDegraded: osconfigv1.ConditionFalse,
+			expectedReason:   ReasonAsExpected,
+		},
+		{
+			name: "remediation recovered",
+			mhcs: []*mapiv1.MachineHealthCheck{
+				newMHC("recovered", conditions.TrueCondition(mapiv1.RemediationSucceedingCondition)),
+			},
+			expectedDegraded: osconfigv1.ConditionFalse,
+			expectedReason:   ReasonAsExpected,
+		},
+		{
+			name: "control plane deletion blocked",
+			mhcs: []*mapiv1.MachineHealthCheck{
+				newMHC("blocked", conditions.FalseCondition(mapiv1.RemediationSucceedingCondition, mapiv1.ControlPlaneDeletionBlockedReason, mapiv1.ConditionSeverityWarning, "")),
+			},
+			expectedDegraded: osconfigv1.ConditionFalse,
+			expectedReason:   ReasonAsExpected,
+		},
+		{
+			name: "remediation repeatedly failing",
+			mhcs: []*mapiv1.MachineHealthCheck{
+				newMHC("healthy", nil),
+				newMHC("failing", failing),
+			},
+			expectedDegraded: osconfigv1.ConditionTrue,
+			expectedReason:   ReasonRemediationFailing,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, mhc := range tc.mhcs {
+				if err := indexer.Add(mhc); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			optr := Operator{
+				namespace:     targetNamespace,
+				eventRecorder: record.NewFakeRecorder(5),
+				osClient:      fakeconfigclientset.NewSimpleClientset(),
+				mhcLister:     machinelistersv1beta1.NewMachineHealthCheckLister(indexer),
+			}
+
+			if err := optr.statusAvailable(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			co, err := optr.osClient.ConfigV1().ClusterOperators().Get(context.TODO(), clusterOperatorName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			degraded := v1helpers.FindStatusCondition(co.Status.Conditions, osconfigv1.OperatorDegraded)
+			if degraded == nil || degraded.Status != tc.expectedDegraded || degraded.Reason != string(tc.expectedReason) {
+				t.Errorf("Expected Degraded to be %s with reason %s, got %+v", tc.expectedDegraded, tc.expectedReason, degraded)
+			}
+			available := v1helpers.FindStatusCondition(co.Status.Conditions, osconfigv1.OperatorAvailable)
+			if available == nil || available.Status != osconfigv1.ConditionTrue {
+				t.Errorf("Expected Available to be True, got %+v", available)
+			}
+		})
+	}
+}
@@ -11,16 +11,22 @@ import (
 	osclientset "github.com/openshift/client-go/config/clientset/versioned"
 	configinformersv1 "github.com/openshift/client-go/config/informers/externalversions/config/v1"
 	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	machineinformersv1beta1 "github.com/openshift/machine-api-operator/pkg/generated/informers/externalversions/machine/v1beta1"
+	machinelistersv1beta1 "github.com/openshift/machine-api-operator/pkg/generated/listers/machine/v1beta1"
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
 	admissioninformersv1 "k8s.io/client-go/informers/admissionregistration/v1"
 	appsinformersv1 "k8s.io/client-go/informers/apps/v1"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	admissionlisterv1 "k8s.io/client-go/listers/admissionregistration/v1"
 	appslisterv1 "k8s.io/client-go/listers/apps/v1"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
@@ -60,6 +66,11 @@ type Operator struct {
 	proxyLister       configlistersv1.ProxyLister
 	proxyListerSynced cache.InformerSynced
 
+	// configMapLister lists the ConfigMaps of the operator namespace,
+	// it is used to read the operator config overrides
+	configMapLister       corelisterv1.ConfigMapLister
+	configMapListerSynced cache.InformerSynced
+
 	validatingWebhookLister       admissionlisterv1.ValidatingWebhookConfigurationLister
 	validatingWebhookListerSynced cache.InformerSynced
 
@@ -69,6 +80,11 @@ type Operator struct {
 	featureGateLister      configlistersv1.FeatureGateLister
 	featureGateCacheSynced cache.InformerSynced
 
+	// mhcLister lists the MachineHealthChecks whose remediation failures degrade the operator,
+	// they are not taken into account when nil
+	mhcLister       machinelistersv1beta1.MachineHealthCheckLister
+	mhcListerSynced cache.InformerSynced
+
 	// queue only ever has one item, but it has nice error handling backoff/retry semantics
 	queue           workqueue.RateLimitingInterface
 	operandVersions []osconfigv1.OperandVersion
@@ -89,6 +105,8 @@ func New(
 	validatingWebhookInformer admissioninformersv1.ValidatingWebhookConfigurationInformer,
 	mutatingWebhookInformer admissioninformersv1.MutatingWebhookConfigurationInformer,
 	proxyInformer configinformersv1.ProxyInformer,
+	configMapInformer coreinformersv1.ConfigMapInformer,
+	mhcInformer machineinformersv1beta1.MachineHealthCheckInformer,
 	kubeClient kubernetes.Interface,
 	osClient osclientset.Interface,
 	dynamicClient dynamic.Interface,
@@ -119,6 +137,8 @@ func New(
 	validatingWebhookInformer.Informer().AddEventHandler(optr.eventHandlerSingleton(isMachineWebhook))
 	mutatingWebhookInformer.Informer().AddEventHandler(optr.eventHandlerSingleton(isMachineWebhook))
 	featureGateInformer.Informer().AddEventHandler(optr.eventHandler())
+	configMapInformer.Informer().AddEventHandler(optr.eventHandlerSingleton(isOperatorConfigConfigMap))
+	mhcInformer.Informer().AddEventHandler(optr.eventHandlerMachineHealthChecks())
 
 	optr.config = config
 	optr.syncHandler = optr.sync
@@ -132,6 +152,9 @@ func New(
 	optr.proxyLister = proxyInformer.Lister()
 	optr.proxyListerSynced = proxyInformer.Informer().HasSynced
 
+	optr.configMapLister = configMapInformer.Lister()
+	optr.configMapListerSynced = configMapInformer.Informer().HasSynced
+
 	optr.validatingWebhookLister = validatingWebhookInformer.Lister()
 	optr.validatingWebhookListerSynced = validatingWebhookInformer.Informer().HasSynced
 
@@ -141,6 +164,9 @@ func New(
 	optr.featureGateLister = featureGateInformer.Lister()
 	optr.featureGateCacheSynced = featureGateInformer.Informer().HasSynced
 
+	optr.mhcLister = mhcInformer.Lister()
+	optr.mhcListerSynced = mhcInformer.Informer().HasSynced
+
 	return optr
 }
 
@@ -158,7 +184,9 @@ func (optr *Operator) Run(workers int, stopCh <-chan struct{}) {
 		optr.deployListerSynced,
 		optr.daemonsetListerSynced,
 		optr.proxyListerSynced,
-		optr.featureGateCacheSynced) {
+		optr.configMapListerSynced,
+		optr.featureGateCacheSynced,
+		optr.mhcListerSynced) {
 		klog.Error("Failed to sync caches")
 		return
 	}
@@ -257,6 +285,11 @@ func (optr *Operator) eventHandlerSingleton(f func(interface{}) bool) cache.Filt
 	}
 }
 
+func isOperatorConfigConfigMap(obj interface{}) bool {
+	cm, ok := obj.(*corev1.ConfigMap)
+	return ok && cm.Name == operatorConfigConfigMapName
+}
+
 func isMachineWebhook(obj interface{}) bool {
 	mutatingWebhook, ok := obj.(*admissionregistrationv1.MutatingWebhookConfiguration)
 	if ok {
@@ -328,6 +361,11 @@ func (optr *Operator) maoConfigFromInfrastructure() (*OperatorConfig, error) {
 		return nil, err
 	}
 
+	clusterWideProxy, err := optr.osClient.ConfigV1().Proxies().Get(context.Background(), "cluster", metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
 	provider, err := getProviderFromInfrastructure(infra)
 	if err != nil {
 		return nil, err
@@ -358,12 +396,7 @@ func (optr *Operator) maoConfigFromInfrastructure() (*OperatorConfig, error) {
 		return nil, err
 	}
 
-	clusterWideProxy, err := optr.osClient.ConfigV1().Proxies().Get(context.Background(), "cluster", metav1.GetOptions{})
-	if err != nil {
-		return nil, err
-	}
-
-	return &OperatorConfig{
+	config := &OperatorConfig{
 		TargetNamespace: optr.namespace,
 		Proxy:           clusterWideProxy,
 		Controllers: Controllers{
@@ -374,5 +407,18 @@ func (optr *Operator) maoConfigFromInfrastructure() (*OperatorConfig, error) {
 			KubeRBACProxy:      kubeRBACProxy,
 			TerminationHandler: terminationHandlerImage,
 		},
-	}, nil
+	}
+
+	// the operator config ConfigMap overrides the images injected at build time
+	cm, err := optr.configMapLister.ConfigMaps(optr.namespace).Get(operatorConfigConfigMapName)
+	if apierrors.IsNotFound(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := applyOperatorConfigOverrides(config, cm); err != nil {
+		return nil, err
+	}
+	return config, nil
 }
@@ -15,8 +15,11 @@ import (
 	openshiftv1 "github.com/openshift/api/config/v1"
 	fakeos "github.com/openshift/client-go/config/clientset/versioned/fake"
 	configinformersv1 "github.com/openshift/client-go/config/informers/externalversions"
+	fakemachine "github.com/openshift/machine-api-operator/pkg/generated/clientset/versioned/fake"
+	machineinformersv1beta1 "github.com/openshift/machine-api-operator/pkg/generated/informers/externalversions"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -26,6 +29,7 @@ import (
 	"k8s.io/client-go/informers"
 	fakekube "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 )
@@ -46,9 +50,13 @@ func newFakeOperator(kubeObjects []runtime.Object, osObjects []runtime.Object, s
 	featureGateInformer := configSharedInformer.Config().V1().FeatureGates()
 	deployInformer := kubeNamespacedSharedInformer.Apps().V1().Deployments()
 	proxyInformer := configSharedInformer.Config().V1().Proxies()
+	configMapInformer := kubeNamespacedSharedInformer.Core().V1().ConfigMaps()
 	daemonsetInformer := kubeNamespacedSharedInformer.Apps().V1().DaemonSets()
 	mutatingWebhookInformer := kubeNamespacedSharedInformer.Admissionregistration().V1().MutatingWebhookConfigurations()
 	validatingWebhookInformer := kubeNamespacedSharedInformer.Admissionregistration().V1().ValidatingWebhookConfigurations()
+	machineClient := fakemachine.NewSimpleClientset()
+	machineSharedInformer := machineinformersv1beta1.NewSharedInformerFactoryWithOptions(machineClient, 2*time.Minute, machineinformersv1beta1.WithNamespace(targetNamespace))
+	mhcInformer := machineSharedInformer.Machine().V1beta1().MachineHealthChecks()
 
 	optr := &Operator{
 		kubeClient:                    kubeClient,
@@ -57,27 +65,33 @@ func newFakeOperator(kubeObjects []runtime.Object, osObjects []runtime.Object, s
 		featureGateLister:             featureGateInformer.Lister(),
 		deployLister:                  deployInformer.Lister(),
 		proxyLister:                   proxyInformer.Lister(),
+		configMapLister:               configMapInformer.Lister(),
 		daemonsetLister:               daemonsetInformer.Lister(),
 		mutatingWebhookLister:         mutatingWebhookInformer.Lister(),
 		validatingWebhookLister:       validatingWebhookInformer.Lister(),
+		mhcLister:                     mhcInformer.Lister(),
 		imagesFile:                    "fixtures/images.json",
 		namespace:                     targetNamespace,
 		eventRecorder:                 record.NewFakeRecorder(50),
 		queue:                         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "machineapioperator"),
 		deployListerSynced:            deployInformer.Informer().HasSynced,
 		proxyListerSynced:             proxyInformer.Informer().HasSynced,
+		configMapListerSynced:         configMapInformer.Informer().HasSynced,
 		daemonsetListerSynced:         daemonsetInformer.Informer().HasSynced,
 		featureGateCacheSynced:        featureGateInformer.Informer().HasSynced,
 		mutatingWebhookListerSynced:   mutatingWebhookInformer.Informer().HasSynced,
 		validatingWebhookListerSynced: validatingWebhookInformer.Informer().HasSynced,
+		mhcListerSynced:               mhcInformer.Informer().HasSynced,
 	}
 
 	configSharedInformer.Start(stopCh)
 	kubeNamespacedSharedInformer.Start(stopCh)
+	machineSharedInformer.Start(stopCh)
 
 	optr.syncHandler = optr.sync
 	deployInformer.Informer().AddEventHandler(optr.eventHandlerDeployments())
 	featureGateInformer.Informer().AddEventHandler(optr.eventHandler())
+	mhcInformer.Informer().AddEventHandler(optr.eventHandlerMachineHealthChecks())
 
 	optr.operandVersions = []openshiftv1.OperandVersion{
 		{Name: "operator", Version: releaseVersion},
@@ -288,6 +302,7 @@ func TestMAOConfigFromInfrastructure(t *testing.T) {
 		platform       openshiftv1.PlatformType
 		infra          *openshiftv1.Infrastructure
 		proxy          *openshiftv1.Proxy
+		configMap      *corev1.ConfigMap
 		imagesFile     string
 		expectedConfig *OperatorConfig
 		expectedError  error
@@ -310,6 +325,33 @@ func TestMAOConfigFromInfrastructure(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "operator-config-overrides",
+			platform: openshiftv1.AWSPlatformType,
+			infra:    infra,
+			proxy:    proxy,
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      operatorConfigConfigMapName,
+					Namespace: targetNamespace,
+				},
+				Data: map[string]string{
+					operatorConfigMachineSetKey: "quay.io/openshift/origin-machine-api-operator:test",
+				},
+			},
+			expectedConfig: &OperatorConfig{
+				TargetNamespace: targetNamespace,
+				Proxy:           proxy,
+				Controllers: Controllers{
+					Provider:           images.ClusterAPIControllerAWS,
+					MachineSet:         "quay.io/openshift/origin-machine-api-operator:test",
+					NodeLink:           images.MachineAPIOperator,
+					MachineHealthCheck: images.MachineAPIOperator,
+					TerminationHandler: images.ClusterAPIControllerAWS,
+					KubeRBACProxy:      images.KubeRBACProxy,
+				},
+			},
+		},
 		{
 			name:     string(openshiftv1.LibvirtPlatformType),
 			platform: openshiftv1.LibvirtPlatformType,
@@ -540,8 +582,12 @@ func TestMAOConfigFromInfrastructure(t *testing.T) {
 				proxy := tc.proxy.DeepCopy()
 				objects = append(objects, proxy)
 			}
+			kubeObjects := []runtime.Object{}
+			if tc.configMap != nil {
+				kubeObjects = append(kubeObjects, tc.configMap)
+			}
 			stopCh := make(<-chan struct{})
-			optr := newFakeOperator(nil, objects, stopCh)
+			optr := newFakeOperator(kubeObjects, objects, stopCh)
 			optr.queue.Add("trigger")
 
 			if tc.imagesFile != "" {
@@ -549,6 +595,7 @@ func TestMAOConfigFromInfrastructure(t *testing.T) {
 			}
 
 			go optr.Run(1, stopCh)
+			g.Expect(cache.WaitForCacheSync(stopCh, optr.configMapListerSynced)).To(BeTrue())
 
 			config, err := optr.maoConfigFromInfrastructure()
 
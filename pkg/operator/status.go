@@ -25,6 +25,9 @@ const (
 	ReasonInitializing StatusReason = "Initializing"
 	ReasonSyncing      StatusReason = "SyncingResources"
 	ReasonSyncFailed   StatusReason = "SyncingFailed"
+
+	// ReasonRemediationFailing is used when the remediation of MachineHealthChecks is repeatedly failing
+	ReasonRemediationFailing StatusReason = "MachineHealthCheckRemediationFailing"
 )
 
 const (
@@ -80,13 +83,14 @@ func (optr *Operator) statusProgressing() error {
 }
 
 // statusAvailable sets the Available condition to True, with the given reason
-// and message, and sets both the Progressing and Degraded conditions to False.
+// and message, and sets the Progressing condition to False. The Degraded condition
+// is set to False unless remediation of MachineHealthChecks is repeatedly failing.
 func (optr *Operator) statusAvailable() error {
 	conds := []osconfigv1.ClusterOperatorStatusCondition{
 		newClusterOperatorStatusCondition(osconfigv1.OperatorAvailable, osconfigv1.ConditionTrue, string(ReasonAsExpected),
 			fmt.Sprintf("Cluster Machine API Operator is available at %s", optr.printOperandVersions())),
 		newClusterOperatorStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionFalse, string(ReasonAsExpected), ""),
-		newClusterOperatorStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionFalse, string(ReasonAsExpected), ""),
+		optr.machineHealthChecksDegradedCondition(),
 		operatorUpgradeable,
 	}
 
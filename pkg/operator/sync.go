@@ -199,6 +199,9 @@ func (optr *Operator) waitForDeploymentRollout(resource *appsv1.Deployment, poll
 			return false, fmt.Errorf("deployment %s is being deleted", resource.Name)
 		}
 
+		// a lagging observed generation shows a rollout stuck before the deployment controller acted on it
+		metrics.ObserveOperatorDeploymentGeneration(d.Name, d.Namespace, d.Generation, d.Status.ObservedGeneration)
+
 		if d.Generation <= d.Status.ObservedGeneration && d.Status.UpdatedReplicas == d.Status.Replicas && d.Status.UnavailableReplicas == 0 {
 			c := conditions.GetDeploymentCondition(d, appsv1.DeploymentAvailable)
 			if c == nil {
@@ -5,6 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/openshift/machine-api-operator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -241,3 +244,38 @@ func Test_ensureDependecyAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestWaitForDeploymentRolloutGenerationLag(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "lagging",
+			Namespace:  targetNamespace,
+			Generation: 3,
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1,
+			Replicas:           1,
+			UpdatedReplicas:    1,
+			ReadyReplicas:      1,
+			AvailableReplicas:  1,
+		},
+	}
+	optr := newFakeOperator([]runtime.Object{deployment}, nil, make(<-chan struct{}))
+
+	expected := fmt.Errorf("deployment lagging is not ready. status: (replicas: 1, updated: 1, ready: 1, unavailable: 0)")
+	if got := optr.waitForDeploymentRollout(deployment, 100*time.Millisecond, time.Second); got == nil || got.Error() != expected.Error() {
+		t.Errorf("Got: %v, expected: %v", got, expected)
+	}
+
+	gauge, err := metrics.OperatorDeploymentGenerationLag.GetMetricWithLabelValues(deployment.Name, deployment.Namespace)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	metric := &dto.Metric{}
+	if err := gauge.(prometheus.Metric).Write(metric); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lag := metric.GetGauge().GetValue(); lag != 2 {
+		t.Errorf("Expected a generation lag of 2, got %v", lag)
+	}
+}
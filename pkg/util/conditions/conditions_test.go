@@ -0,0 +1,26 @@
+package conditions
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetNodeCondition(t *testing.T) {
+	g := NewWithT(t)
+
+	kernelDeadlock := corev1.NodeConditionType("KernelDeadlock")
+	node := &corev1.Node{
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				{Type: kernelDeadlock, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+
+	g.Expect(GetNodeCondition(node, corev1.NodeReady)).To(Equal(&node.Status.Conditions[0]))
+	g.Expect(GetNodeCondition(node, kernelDeadlock)).To(Equal(&node.Status.Conditions[1]))
+	g.Expect(GetNodeCondition(node, corev1.NodeConditionType("FrequentKubeletRestart"))).To(BeNil())
+}